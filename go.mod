@@ -6,9 +6,14 @@ require (
 	github.com/blevesearch/bleve v1.0.14
 	github.com/elastic/go-elasticsearch v0.0.0
 	github.com/golang-migrate/migrate/v4 v4.15.2
+	github.com/google/btree v1.1.2
 	github.com/google/uuid v1.3.0
 	github.com/lib/pq v1.10.7
+	go.etcd.io/bbolt v1.3.6
+	golang.org/x/net v0.0.0-20220722155237-a158d28d115b
 	golang.org/x/xerrors v0.0.0-20220609144429-65e65417b02f
+	google.golang.org/grpc v1.51.0
+	google.golang.org/protobuf v1.28.1
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c
 )
 
@@ -36,8 +41,8 @@ require (
 	github.com/steveyen/gtreap v0.1.0 // indirect
 	github.com/tinylib/msgp v1.1.0 // indirect
 	github.com/willf/bitset v1.1.11 // indirect
-	go.etcd.io/bbolt v1.3.6 // indirect
 	go.uber.org/atomic v1.7.0 // indirect
-	golang.org/x/sys v0.0.0-20220317061510-51cd9980dadf // indirect
-	google.golang.org/protobuf v1.27.1 // indirect
+	golang.org/x/sys v0.0.0-20220722155257-8c9f86f7a55f // indirect
+	golang.org/x/text v0.4.0 // indirect
+	google.golang.org/genproto v0.0.0-20220314164441-57ef72a4c106 // indirect
 )
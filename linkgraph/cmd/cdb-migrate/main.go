@@ -0,0 +1,106 @@
+// Command cdb-migrate manages the schema of a CockroachDB link graph using
+// the migrations that are embedded into the cdb package.
+//
+// Usage:
+//
+//	cdb-migrate [-cdb-dsn DSN] [-version N] [-batch-size N] up|down|status|backfill-hosts
+//
+// The up command migrates the schema to the requested version, defaulting to
+// the latest one. The down command rolls the schema back to the requested
+// version; as this may discard data, the version must always be specified.
+// The status command reports the current schema version.
+//
+// The backfill-hosts command populates the host of links that were stored
+// before the links table had a host column, updating -batch-size links at a
+// time. It requires the schema to be at the latest version and should be run
+// once after migrating a graph created by an older version.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/cdb"
+	"golang.org/x/xerrors"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "cdb-migrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("cdb-migrate", flag.ContinueOnError)
+	dsn := fs.String("cdb-dsn", os.Getenv("CDB_DSN"), "the DSN of the CockroachDB link graph; defaults to the CDB_DSN envvar")
+	version := fs.Int("version", -1, "the schema version to migrate to")
+	batchSize := fs.Int("batch-size", 1000, "the number of links updated per statement by the backfill-hosts command")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *dsn == "" {
+		return xerrors.New("the -cdb-dsn flag or the CDB_DSN envvar must be specified")
+	} else if fs.NArg() != 1 {
+		return xerrors.New("expected exactly one of the up, down, status or backfill-hosts commands")
+	}
+
+	current, dirty, err := cdb.SchemaVersion(*dsn)
+	if err != nil {
+		return err
+	}
+
+	switch cmd := fs.Arg(0); cmd {
+	case "status":
+		fmt.Printf("version: %d, latest: %d, dirty: %t\n", current, cdb.LatestSchemaVersion, dirty)
+		return nil
+	case "up":
+		target := cdb.LatestSchemaVersion
+		if *version >= 0 {
+			target = uint(*version)
+		}
+		if target < current {
+			return xerrors.Errorf("cannot migrate up from version %d to %d", current, target)
+		}
+		return migrate(*dsn, current, target)
+	case "down":
+		if *version < 0 {
+			return xerrors.New("the -version flag is required for the down command")
+		}
+		target := uint(*version)
+		if target > current {
+			return xerrors.Errorf("cannot migrate down from version %d to %d", current, target)
+		}
+		return migrate(*dsn, current, target)
+	case "backfill-hosts":
+		return backfillHosts(*dsn, *batchSize)
+	default:
+		return xerrors.Errorf("unknown command %q", cmd)
+	}
+}
+
+func migrate(dsn string, from, to uint) error {
+	if err := cdb.Migrate(dsn, to); err != nil {
+		return err
+	}
+	fmt.Printf("migrated schema from version %d to %d\n", from, to)
+	return nil
+}
+
+func backfillHosts(dsn string, batchSize int) error {
+	g, err := cdb.NewCockroachDbGraphWithOptions(dsn, cdb.Options{VerifySchemaVersion: true})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = g.Close() }()
+
+	updated, err := g.BackfillHosts(context.Background(), batchSize)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("backfilled the host of %d links\n", updated)
+	return nil
+}
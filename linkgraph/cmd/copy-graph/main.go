@@ -0,0 +1,81 @@
+// Command copy-graph copies all links and edges of a link graph into another
+// link graph, e.g. to move a crawl from a durable in-memory graph into
+// CockroachDB.
+//
+// Exactly one of the -src-cdb-dsn, -src-bolt-path or -src-memory-dir flags
+// must be specified to select the source graph and exactly one of the
+// -dst-cdb-dsn, -dst-bolt-path or -dst-memory-dir flags to select the
+// destination graph. The destination graph should be empty. An interrupted
+// copy can be resumed by running the command again with the same -checkpoint
+// and -partitions flags.
+//
+// Unless -deterministic-ids is specified, the IDs of all copied links are
+// kept in memory for the duration of the run. With -deterministic-ids,
+// copied links keep their source ID if it was derived from their URL (see
+// the rekey-links command) and only the remaining links are tracked.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/cmd/internal/graphflags"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graphcopy"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "copy-graph: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("copy-graph", flag.ContinueOnError)
+	srcFlags := graphflags.RegisterWithPrefix(fs, "src-", "the source")
+	dstFlags := graphflags.RegisterWithPrefix(fs, "dst-", "the destination")
+	numPartitions := fs.Int("partitions", 1, "the number of partitions to split the link ID space into")
+	workers := fs.Int("workers", 1, "the number of partitions to copy in parallel")
+	batchSize := fs.Int("batch-size", 500, "the maximum number of links or edges to upsert with a single call")
+	checkpointPath := fs.String("checkpoint", "", "the path to a file for recording the progress of the copy")
+	deterministicIDs := fs.Bool("deterministic-ids", false, "assign IDs derived from their URL to the copied links")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// The source graph is not written to; the option only reports that its
+	// link IDs are derived from their URL.
+	src, srcCloser, err := srcFlags.Open(graphflags.Options{DeterministicIDs: *deterministicIDs})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = srcCloser.Close() }()
+
+	dst, dstCloser, err := dstFlags.Open(graphflags.Options{DeterministicIDs: *deterministicIDs})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = dstCloser.Close() }()
+
+	ctx, cancelFn := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancelFn()
+
+	stats, err := graphcopy.Run(ctx, src, dst, graphcopy.Config{
+		NumPartitions:  *numPartitions,
+		Workers:        *workers,
+		BatchSize:      *batchSize,
+		CheckpointPath: *checkpointPath,
+	})
+	fmt.Printf("links: %d, edges: %d, skipped partitions: %d\n", stats.Links, stats.Edges, stats.SkippedPartitions)
+	if err != nil {
+		return err
+	}
+
+	if err = dstCloser.Close(); err != nil {
+		return err
+	}
+	return srcCloser.Close()
+}
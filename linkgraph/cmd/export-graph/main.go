@@ -0,0 +1,74 @@
+// Command export-graph writes the links and edges of a link graph to a file
+// in GraphML, DOT, JSON Lines or edge-list CSV format.
+//
+// Exactly one of the -cdb-dsn, -bolt-path or -memory-dir flags must be
+// specified to select the graph to export.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/cmd/internal/graphflags"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graphio"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "export-graph: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("export-graph", flag.ContinueOnError)
+	graphFlags := graphflags.Register(fs)
+	formatName := fs.String("format", string(graphio.FormatJSONL), "the export format: graphml, dot, jsonl or csv")
+	outPath := fs.String("out", "", "the file to write the export to; defaults to STDOUT")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := graphio.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	g, closer, err := graphFlags.Open(graphflags.Options{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closer.Close() }()
+
+	var (
+		out     io.Writer = os.Stdout
+		outFile *os.File
+	)
+	if *outPath != "" {
+		if outFile, err = os.Create(*outPath); err != nil {
+			return err
+		}
+		defer func() { _ = outFile.Close() }()
+		out = outFile
+	}
+
+	ctx, cancelFn := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancelFn()
+
+	stats, err := graphio.Export(ctx, g, out, format)
+	fmt.Fprintf(os.Stderr, "exported links: %d, edges: %d\n", stats.Links, stats.Edges)
+	if err != nil {
+		return err
+	}
+
+	if outFile != nil {
+		if err = outFile.Close(); err != nil {
+			return err
+		}
+	}
+	return closer.Close()
+}
@@ -0,0 +1,62 @@
+// Command gc-links removes links that have not been retrieved within a
+// retention window and that are not referenced by any other link, together
+// with their outgoing edges.
+//
+// Exactly one of the -cdb-dsn, -bolt-path or -memory-dir flags must be
+// specified to select the graph to collect. When running against a live
+// graph, the -max-links-per-second flag should be used to bound the load
+// imposed on the graph store.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/cmd/internal/graphflags"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/retention"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "gc-links: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("gc-links", flag.ContinueOnError)
+	graphFlags := graphflags.Register(fs)
+	retentionWindow := fs.Duration("retention", 30*24*time.Hour, "remove links that have not been retrieved within this window")
+	numPartitions := fs.Int("partitions", 1, "the number of partitions to split the link ID space into")
+	maxLinksPerSecond := fs.Int("max-links-per-second", 0, "the maximum number of links to check and remove per second (0 for no limit)")
+	dryRun := fs.Bool("dry-run", false, "report the number of links that would be removed without modifying the graph")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	g, closer, err := graphFlags.Open(graphflags.Options{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closer.Close() }()
+
+	ctx, cancelFn := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancelFn()
+
+	stats, err := retention.Run(ctx, g, retention.Config{
+		Retention:         *retentionWindow,
+		NumPartitions:     *numPartitions,
+		MaxLinksPerSecond: *maxLinksPerSecond,
+		DryRun:            *dryRun,
+	})
+	fmt.Printf("expired: %d, removed: %d, removed edges: %d\n", stats.Expired, stats.Removed, stats.RemovedEdges)
+	if err != nil {
+		return err
+	}
+
+	return closer.Close()
+}
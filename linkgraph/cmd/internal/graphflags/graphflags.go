@@ -0,0 +1,99 @@
+// Package graphflags provides the command-line flags that the link graph
+// maintenance commands use for selecting the graph to operate on.
+package graphflags
+
+import (
+	"flag"
+	"io"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/bolt"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/cdb"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory/wal"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
+	"golang.org/x/xerrors"
+)
+
+// Flags holds the values of the graph selection flags.
+type Flags struct {
+	CDBDSN    string
+	BoltPath  string
+	MemoryDir string
+
+	// prefix is prepended to the names of the registered flags.
+	prefix string
+}
+
+// Options configures the graph returned by Flags.Open.
+type Options struct {
+	// The canonicalizer to apply to upserted links. If not specified, the
+	// default canonicalizer of the selected store is used.
+	Canonicalizer *urlcanon.Canonicalizer
+
+	// When set, new links are assigned deterministic IDs derived from
+	// their URL.
+	DeterministicIDs bool
+}
+
+// store is implemented by all graph implementations that can be selected
+// via the command-line flags.
+type store interface {
+	graph.Graph
+	io.Closer
+	SetCanonicalizer(*urlcanon.Canonicalizer)
+	SetDeterministicIDs(bool)
+}
+
+// Register adds the -cdb-dsn, -bolt-path and -memory-dir flags to fs.
+func Register(fs *flag.FlagSet) *Flags {
+	return RegisterWithPrefix(fs, "", "a")
+}
+
+// RegisterWithPrefix adds the graph selection flags to fs, prepending
+// prefix to each flag name. It allows commands that operate on more than
+// one graph to register a set of flags per graph. The role describes the
+// graph in the flag usage strings (e.g. "the source").
+func RegisterWithPrefix(fs *flag.FlagSet, prefix, role string) *Flags {
+	f := &Flags{prefix: prefix}
+	fs.StringVar(&f.CDBDSN, prefix+"cdb-dsn", "", "the DSN of "+role+" CockroachDB link graph")
+	fs.StringVar(&f.BoltPath, prefix+"bolt-path", "", "the path to "+role+" bbolt link graph")
+	fs.StringVar(&f.MemoryDir, prefix+"memory-dir", "", "the data directory of "+role+" durable in-memory link graph")
+	return f
+}
+
+// Open opens the graph selected by the command-line flags. Exactly one of
+// the flags must be specified.
+func (f *Flags) Open(opts Options) (graph.Graph, io.Closer, error) {
+	var selected int
+	for _, v := range []string{f.CDBDSN, f.BoltPath, f.MemoryDir} {
+		if v != "" {
+			selected++
+		}
+	}
+	if selected != 1 {
+		return nil, nil, xerrors.Errorf("exactly one of -%[1]scdb-dsn, -%[1]sbolt-path or -%[1]smemory-dir must be specified", f.prefix)
+	}
+
+	var (
+		g   store
+		err error
+	)
+	switch {
+	case f.CDBDSN != "":
+		g, err = cdb.NewCockroachDbGraphWithOptions(f.CDBDSN, cdb.Options{VerifySchemaVersion: true})
+	case f.BoltPath != "":
+		g, err = bolt.NewBoltGraph(f.BoltPath)
+	default:
+		g, err = memory.NewDurableInMemoryGraph(f.MemoryDir, wal.Options{})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.Canonicalizer != nil {
+		g.SetCanonicalizer(opts.Canonicalizer)
+	}
+	g.SetDeterministicIDs(opts.DeterministicIDs)
+	return g, g, nil
+}
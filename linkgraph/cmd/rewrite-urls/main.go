@@ -0,0 +1,62 @@
+// Command rewrite-urls converts the URLs of already stored links into their
+// canonical form, merging links whose URLs turn out to be duplicates.
+//
+// Exactly one of the -cdb-dsn, -bolt-path or -memory-dir flags must be
+// specified to select the graph to rewrite.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/cmd/internal/graphflags"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon/rewrite"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "rewrite-urls: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("rewrite-urls", flag.ContinueOnError)
+	graphFlags := graphflags.Register(fs)
+	stripTracking := fs.Bool("strip-tracking-params", false, "remove tracking parameters (utm_*, gclid, ...) from URLs")
+	numPartitions := fs.Int("partitions", 1, "the number of partitions to split the link ID space into")
+	dryRun := fs.Bool("dry-run", false, "report the number of links that would be rewritten without modifying the graph")
+	deterministicIDs := fs.Bool("deterministic-ids", false, "assign IDs derived from their URL to newly created canonical links")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	canon := urlcanon.New(urlcanon.Options{StripTrackingParams: *stripTracking})
+	g, closer, err := graphFlags.Open(graphflags.Options{
+		Canonicalizer:    canon,
+		DeterministicIDs: *deterministicIDs,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closer.Close() }()
+
+	ctx, cancelFn := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancelFn()
+
+	stats, err := rewrite.Run(ctx, g, rewrite.Config{
+		Canonicalizer: canon,
+		NumPartitions: *numPartitions,
+		DryRun:        *dryRun,
+	})
+	fmt.Printf("scanned: %d, rewritten: %d, invalid: %d\n", stats.Scanned, stats.Rewritten, stats.Invalid)
+	if err != nil {
+		return err
+	}
+
+	return closer.Close()
+}
@@ -1,6 +1,10 @@
 package graph
 
-import "golang.org/x/xerrors"
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
 
 var (
 	// ErrNotFound is returned when a link or edge lookup fails.
@@ -9,4 +13,48 @@ var (
 	// ErrUnknownEdgeLinks is returned when attempting to create an edge
 	// with an invalid source and/or destination ID
 	ErrUnknownEdgeLinks = xerrors.New("unknown source and/or destination for edge")
+
+	// ErrEventsUnavailable is returned by Watch when the events following
+	// the requested cursor are no longer (or not yet) available.
+	ErrEventsUnavailable = xerrors.New("events following the requested cursor are unavailable")
+
+	// ErrInvalidEventCursor is returned by Watch when the requested cursor
+	// was not obtained from the watched graph.
+	ErrInvalidEventCursor = xerrors.New("invalid event cursor")
 )
+
+// BatchError is returned by the batch upsert methods when one or more items
+// in the batch could not be upserted.
+type BatchError struct {
+	// Errors contains one entry for each item in the batch. Entries for
+	// items that were upserted successfully are nil.
+	Errors []error
+}
+
+// NewBatchError returns a *BatchError if any of the entries in errs is
+// non-nil or nil otherwise.
+func NewBatchError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return &BatchError{Errors: errs}
+		}
+	}
+	return nil
+}
+
+// Error implements the error interface.
+func (e *BatchError) Error() string {
+	var (
+		failed   int
+		firstErr error
+	)
+	for _, err := range e.Errors {
+		if err == nil {
+			continue
+		}
+		if failed++; firstErr == nil {
+			firstErr = err
+		}
+	}
+	return fmt.Sprintf("%d of %d batch items failed; first error: %v", failed, len(e.Errors), firstErr)
+}
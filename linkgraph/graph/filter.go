@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"strings"
+	"time"
+)
+
+// LinkFilter selects links based on their crawl metadata. A link matches the
+// filter if it satisfies all of the filter's non-zero fields; the zero value
+// matches every link.
+type LinkFilter struct {
+	// Match links whose status code is at least MinStatusCode.
+	MinStatusCode int
+	// Match links whose status code is at most MaxStatusCode.
+	MaxStatusCode int
+	// Match links whose content type begins with ContentTypePrefix.
+	ContentTypePrefix string
+	// Match links with at least MinFetchErrors failed fetch attempts.
+	MinFetchErrors int
+	// Match links that were first seen at or after FirstSeenAfter.
+	FirstSeenAfter time.Time
+}
+
+// Matches returns true if link satisfies all non-zero fields of the filter.
+func (f LinkFilter) Matches(link *Link) bool {
+	switch {
+	case f.MinStatusCode != 0 && link.StatusCode < f.MinStatusCode:
+		return false
+	case f.MaxStatusCode != 0 && link.StatusCode > f.MaxStatusCode:
+		return false
+	case f.ContentTypePrefix != "" && !strings.HasPrefix(link.ContentType, f.ContentTypePrefix):
+		return false
+	case f.MinFetchErrors != 0 && link.FetchErrors < f.MinFetchErrors:
+		return false
+	case !f.FirstSeenAfter.IsZero() && link.FirstSeenAt.Before(f.FirstSeenAfter):
+		return false
+	}
+	return true
+}
+
+// MatchesAny returns true if link matches at least one of the provided
+// filters or if no filters are specified.
+func MatchesAny(link *Link, filters []LinkFilter) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if f.Matches(link) {
+			return true
+		}
+	}
+	return false
+}
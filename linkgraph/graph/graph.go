@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,6 +32,20 @@ type Link struct {
 	URL string
 	// The timestamp when the link was last retrieved.
 	RetrievedAt time.Time
+	// The timestamp when the link was first added to the graph. It is
+	// populated by the graph when the link is created.
+	FirstSeenAt time.Time
+
+	// The HTTP status code returned by the last fetch attempt.
+	StatusCode int
+	// The content type reported by the last fetch attempt.
+	ContentType string
+	// A hash of the content returned by the last fetch attempt.
+	ContentHash string
+	// The number of failed fetch attempts.
+	FetchErrors int
+	// The error message reported by the last failed fetch attempt.
+	LastError string
 }
 
 // Edge describes a graph edge that originates from Src and terminates
@@ -44,24 +59,102 @@ type Edge struct {
 	Dst uuid.UUID
 	// The timestamp when the link was last updated.
 	UpdatedAt time.Time
+
+	// The anchor text of the link(s) from Src to Dst.
+	AnchorText string
+	// Set if the link is marked with rel="nofollow".
+	NoFollow bool
+	// Set if the link is marked with rel="sponsored".
+	Sponsored bool
+	// Set if the link is marked with rel="ugc" (user-generated content).
+	UGC bool
+	// The number of times Src links to Dst. Edges that are upserted with
+	// a zero LinkCount are stored with a count of 1.
+	LinkCount int
 }
 
 // Graph is implemented by objects that can mutate or query a link graph.
+//
+// Each method has a Context-suffixed variant that accepts a context.Context
+// which can be used to cancel the operation or bound it with a deadline.
+// Iterators returned by the Context variants stop and report ctx.Err() if
+// the context is cancelled while they are being consumed.
 type Graph interface {
-	// UpsertLink creates a new link or updates an existing link.
+	// UpsertLink creates a new link or updates an existing link. Link URLs
+	// are converted into their canonical form (see package urlcanon) before
+	// they are stored so that different spellings of the same URL resolve
+	// to the same link. The canonical URL is written back to link.URL.
+	//
+	// The crawl metadata of an existing link is only replaced if link has
+	// a more recent RetrievedAt timestamp than the stored link. FirstSeenAt
+	// is set to the current time when the link is created (unless already
+	// set) and is never moved forward by subsequent upserts; its value is
+	// written back to link.FirstSeenAt.
 	UpsertLink(link *Link) error
+	UpsertLinkContext(ctx context.Context, link *Link) error
+
+	// UpsertLinks creates or updates a batch of links. The IDs assigned to
+	// each link are written back to the batch entries. Failures that only
+	// affect individual links are reported via a *BatchError; any other
+	// error indicates that the batch as a whole could not be upserted.
+	UpsertLinks(links []*Link) error
+	UpsertLinksContext(ctx context.Context, links []*Link) error
+
 	// FindLink looks up a link by its ID.
 	FindLink(id uuid.UUID) (*Link, error)
+	FindLinkContext(ctx context.Context, id uuid.UUID) (*Link, error)
+
+	// FindLinkByURL looks up a link by its URL. The URL is converted into
+	// its canonical form before the lookup.
+	FindLinkByURL(url string) (*Link, error)
+	FindLinkByURLContext(ctx context.Context, url string) (*Link, error)
+
+	// LinksByHost returns an iterator for the set of links whose URL
+	// refers to the specified host. Hosts are compared using their
+	// canonical form (see urlcanon.CanonicalHostname); in particular, port
+	// numbers are ignored.
+	LinksByHost(host string) (LinkIterator, error)
+	LinksByHostContext(ctx context.Context, host string) (LinkIterator, error)
+
+	// RemoveLink deletes the link with the specified ID together with all
+	// edges that originate from or point to it. ErrNotFound is returned if
+	// no such link exists.
+	RemoveLink(id uuid.UUID) error
+	RemoveLinkContext(ctx context.Context, id uuid.UUID) error
+
 	// Links returns an iterator for the set of links whose IDs belong to the
 	// [fromID, toID) range and were retrieved before the provided timestamp.
-	Links(fromID, toID uuid.UUID, retrievedBefore time.Time) (LinkIterator, error)
-	// UpsertEdge creates a new edge or updates an existing edge.
+	// If any filters are specified, only links that match at least one of
+	// them are returned.
+	Links(fromID, toID uuid.UUID, retrievedBefore time.Time, filters ...LinkFilter) (LinkIterator, error)
+	LinksContext(ctx context.Context, fromID, toID uuid.UUID, retrievedBefore time.Time, filters ...LinkFilter) (LinkIterator, error)
+
+	// UpsertEdge creates a new edge or updates an existing edge. The
+	// attributes of an existing edge are replaced by the ones of the
+	// upserted edge.
 	UpsertEdge(edge *Edge) error
+	UpsertEdgeContext(ctx context.Context, edge *Edge) error
+
+	// UpsertEdges creates or updates a batch of edges. The IDs and update
+	// timestamps assigned to each edge are written back to the batch
+	// entries. Failures that only affect individual edges (e.g. edges that
+	// refer to unknown links) are reported via a *BatchError.
+	UpsertEdges(edges []*Edge) error
+	UpsertEdgesContext(ctx context.Context, edges []*Edge) error
+
 	// Edges returns an iterator for the set of edges whose source vertex IDs
 	// belong to the [fromID, toID) range and were updated before the provided
 	// timestamp.
 	Edges(fromID, toID uuid.UUID, updatedBefore time.Time) (EdgeIterator, error)
+	EdgesContext(ctx context.Context, fromID, toID uuid.UUID, updatedBefore time.Time) (EdgeIterator, error)
+
+	// InboundEdges returns an iterator for the set of edges that point to
+	// the specified link ID and were updated before the provided timestamp.
+	InboundEdges(dstID uuid.UUID, updatedBefore time.Time) (EdgeIterator, error)
+	InboundEdgesContext(ctx context.Context, dstID uuid.UUID, updatedBefore time.Time) (EdgeIterator, error)
+
 	// RemoveStaleEdges removes any edge that originates from the specified
 	// link ID and was updated before the specified timestamp.
 	RemoveStaleEdges(fromID uuid.UUID, updatedBefore time.Time) error
+	RemoveStaleEdgesContext(ctx context.Context, fromID uuid.UUID, updatedBefore time.Time) error
 }
@@ -0,0 +1,77 @@
+package graphtest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
+	"github.com/google/uuid"
+	gc "gopkg.in/check.v1"
+)
+
+// The helpers below are shared by the tests of the packages that operate on
+// entire graphs. Links and edges are scanned up to an hour into the future
+// so that entries written right before a call are included.
+
+// AllLinks returns all links in g keyed by their URL.
+func AllLinks(c *gc.C, g graph.Graph) map[string]*graph.Link {
+	it, err := g.Links(partition.MinUUID, partition.MaxUUID, time.Now().Add(time.Hour))
+	c.Assert(err, gc.IsNil)
+
+	links := make(map[string]*graph.Link)
+	for it.Next() {
+		link := it.Link()
+		links[link.URL] = link
+	}
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+	return links
+}
+
+// LinkURLs returns the sorted URLs of all links in g.
+func LinkURLs(c *gc.C, g graph.Graph) []string {
+	var urls []string
+	for u := range AllLinks(c, g) {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// AllEdges returns a sorted description of each edge in g that refers to
+// links by URL so that edges can be compared across graphs and link IDs.
+func AllEdges(c *gc.C, g graph.Graph) []string {
+	return describeEdges(c, g, func(*graph.Edge) string { return "" })
+}
+
+// AllEdgesWithAttributes behaves like AllEdges but also includes the
+// attributes of each edge in its description.
+func AllEdgesWithAttributes(c *gc.C, g graph.Graph) []string {
+	return describeEdges(c, g, func(e *graph.Edge) string {
+		return " (" + strings.Join([]string{e.AnchorText, strconv.FormatBool(e.NoFollow), strconv.FormatBool(e.Sponsored), strconv.FormatBool(e.UGC), strconv.Itoa(e.LinkCount)}, ", ") + ")"
+	})
+}
+
+func describeEdges(c *gc.C, g graph.Graph, attrFn func(*graph.Edge) string) []string {
+	urls := make(map[uuid.UUID]string)
+	for u, link := range AllLinks(c, g) {
+		urls[link.ID] = u
+	}
+
+	it, err := g.Edges(partition.MinUUID, partition.MaxUUID, time.Now().Add(time.Hour))
+	c.Assert(err, gc.IsNil)
+
+	var edges []string
+	for it.Next() {
+		e := it.Edge()
+		edges = append(edges, urls[e.Src]+" -> "+urls[e.Dst]+attrFn(e))
+	}
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+
+	sort.Strings(edges)
+	return edges
+}
@@ -1,14 +1,16 @@
 package graphtest
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"math/big"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
 	"github.com/google/uuid"
 	gc "gopkg.in/check.v1"
 )
@@ -75,6 +77,70 @@ func (s *SuiteBase) TestUpsertLink(c *gc.C) {
 	c.Assert(dup.ID, gc.Not(gc.Equals), uuid.Nil, gc.Commentf("expected a linkID to be assigned to the new link"))
 }
 
+// TestUpsertLinkCanonicalURL verifies that links whose URLs only differ in
+// their spelling are mapped to the same canonical link.
+func (s *SuiteBase) TestUpsertLinkCanonicalURL(c *gc.C) {
+	link := &graph.Link{URL: "HTTP://Example.com:80/a#frag"}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+	c.Assert(link.URL, gc.Equals, "http://example.com/a", gc.Commentf("expected the canonical URL to be written back to the link"))
+
+	stored, err := s.g.FindLink(link.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stored.URL, gc.Equals, "http://example.com/a")
+
+	same := &graph.Link{URL: "http://example.com/a"}
+	c.Assert(s.g.UpsertLink(same), gc.IsNil)
+	c.Assert(same.ID, gc.Equals, link.ID, gc.Commentf("expected URLs with the same canonical form to share an ID"))
+
+	batch := []*graph.Link{
+		{URL: "http://EXAMPLE.com/%61"},
+		{URL: "http://bücher.example/"},
+		{URL: "http://xn--bcher-kva.example"},
+	}
+	c.Assert(s.g.UpsertLinks(batch), gc.IsNil)
+	c.Assert(batch[0].ID, gc.Equals, link.ID)
+	c.Assert(batch[1].URL, gc.Equals, "http://xn--bcher-kva.example/")
+	c.Assert(batch[2].ID, gc.Equals, batch[1].ID)
+}
+
+// TestUpsertLinks verifies the batch link upsert logic.
+func (s *SuiteBase) TestUpsertLinks(c *gc.C) {
+	existing := &graph.Link{
+		URL:         "https://example.com/existing",
+		RetrievedAt: time.Now().Add(-10 * time.Hour).Truncate(time.Second).UTC(),
+	}
+	c.Assert(s.g.UpsertLink(existing), gc.IsNil)
+
+	newerTs := time.Now().Truncate(time.Second).UTC()
+	batch := []*graph.Link{
+		{URL: "https://example.com/1"},
+		{URL: "https://example.com/2"},
+		{URL: existing.URL, RetrievedAt: newerTs},
+		{URL: "https://example.com/1"},
+	}
+	c.Assert(s.g.UpsertLinks(batch), gc.IsNil)
+
+	for i, link := range batch {
+		c.Assert(link.ID, gc.Not(gc.Equals), uuid.Nil, gc.Commentf("expected an ID to be assigned to batch entry %d", i))
+	}
+	c.Assert(batch[0].ID, gc.Not(gc.Equals), batch[1].ID)
+	c.Assert(batch[2].ID, gc.Equals, existing.ID, gc.Commentf("expected the ID of the existing link to be reused"))
+	c.Assert(batch[3].ID, gc.Equals, batch[0].ID, gc.Commentf("expected entries with the same URL to share an ID"))
+
+	stored, err := s.g.FindLink(existing.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stored.RetrievedAt, gc.Equals, newerTs, gc.Commentf("last accessed timestamp was not updated"))
+
+	for _, link := range batch[:2] {
+		stored, err := s.g.FindLink(link.ID)
+		c.Assert(err, gc.IsNil)
+		c.Assert(stored.URL, gc.Equals, link.URL)
+	}
+
+	// Upserting an empty batch is a no-op.
+	c.Assert(s.g.UpsertLinks(nil), gc.IsNil)
+}
+
 // TestFindLink verifies the link lookup logic.
 func (s *SuiteBase) TestFindLink(c *gc.C) {
 	// Create a new link
@@ -99,6 +165,142 @@ func (s *SuiteBase) TestFindLink(c *gc.C) {
 	c.Assert(errors.Is(err, graph.ErrNotFound), gc.Equals, true)
 }
 
+// TestFindLinkByURL verifies that links can be looked up by any spelling of
+// their URL.
+func (s *SuiteBase) TestFindLinkByURL(c *gc.C) {
+	link := &graph.Link{
+		URL:         "https://example.com/about",
+		RetrievedAt: time.Now().Truncate(time.Second).UTC(),
+	}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+
+	for _, u := range []string{link.URL, "HTTPS://Example.com:443/about#team"} {
+		got, err := s.g.FindLinkByURL(u)
+		c.Assert(err, gc.IsNil, gc.Commentf("URL %q", u))
+		c.Assert(got, gc.DeepEquals, link, gc.Commentf("URL %q", u))
+	}
+
+	_, err := s.g.FindLinkByURL("https://example.com/missing")
+	c.Assert(errors.Is(err, graph.ErrNotFound), gc.Equals, true)
+}
+
+// TestLinksByHost verifies that the links of a host can be listed.
+func (s *SuiteBase) TestLinksByHost(c *gc.C) {
+	links := make(map[string]*graph.Link)
+	for _, u := range []string{
+		"https://example.com/",
+		"https://example.com/about",
+		"http://example.com:8080/admin",
+		"https://www.example.com/",
+		"https://other.example/",
+		"http://bücher.example/",
+	} {
+		link := &graph.Link{URL: u}
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+		links[link.URL] = link
+	}
+
+	specs := []struct {
+		host string
+		exp  []string
+	}{
+		{
+			host: "example.com",
+			exp:  []string{"http://example.com:8080/admin", "https://example.com/", "https://example.com/about"},
+		},
+		{host: "Example.COM:443", exp: []string{"http://example.com:8080/admin", "https://example.com/", "https://example.com/about"}},
+		{host: "www.example.com", exp: []string{"https://www.example.com/"}},
+		{host: "bücher.example", exp: []string{"http://xn--bcher-kva.example/"}},
+		{host: "unknown.example"},
+	}
+
+	for specIndex, spec := range specs {
+		it, err := s.g.LinksByHost(spec.host)
+		c.Assert(err, gc.IsNil)
+
+		var got []string
+		for it.Next() {
+			link := it.Link()
+			c.Assert(link.ID, gc.Equals, links[link.URL].ID)
+			got = append(got, link.URL)
+		}
+		c.Assert(it.Error(), gc.IsNil)
+		c.Assert(it.Close(), gc.IsNil)
+
+		sort.Strings(got)
+		c.Assert(got, gc.DeepEquals, spec.exp, gc.Commentf("spec %d: host %q", specIndex, spec.host))
+	}
+
+	// Removed links must no longer be listed.
+	c.Assert(s.g.RemoveLink(links["https://www.example.com/"].ID), gc.IsNil)
+	it, err := s.g.LinksByHost("www.example.com")
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+}
+
+// TestRemoveLink verifies that removing a link also removes its URL index
+// entry as well as any edges that originate from or point to it.
+func (s *SuiteBase) TestRemoveLink(c *gc.C) {
+	links := make([]*graph.Link, 3)
+	for i := range links {
+		links[i] = &graph.Link{URL: fmt.Sprintf("https://example.com/%d", i)}
+		c.Assert(s.g.UpsertLink(links[i]), gc.IsNil)
+	}
+
+	// Create edges in both directions between all links.
+	edgeIDs := make(map[[2]uuid.UUID]uuid.UUID)
+	for _, src := range links {
+		for _, dst := range links {
+			if src == dst {
+				continue
+			}
+			edge := &graph.Edge{Src: src.ID, Dst: dst.ID}
+			c.Assert(s.g.UpsertEdge(edge), gc.IsNil)
+			edgeIDs[[2]uuid.UUID{src.ID, dst.ID}] = edge.ID
+		}
+	}
+
+	removed := links[1]
+	c.Assert(s.g.RemoveLink(removed.ID), gc.IsNil)
+
+	_, err := s.g.FindLink(removed.ID)
+	c.Assert(errors.Is(err, graph.ErrNotFound), gc.Equals, true, gc.Commentf("expected the removed link to be gone; got %v", err))
+
+	// Only edges between the remaining links should be left.
+	it, err := s.partitionedEdgeIterator(c, 0, 1, time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	seen := make(map[uuid.UUID]bool)
+	for it.Next() {
+		edge := it.Edge()
+		c.Assert(edge.Src, gc.Not(gc.Equals), removed.ID, gc.Commentf("outgoing edge %s was not removed", edge.ID))
+		c.Assert(edge.Dst, gc.Not(gc.Equals), removed.ID, gc.Commentf("incoming edge %s was not removed", edge.ID))
+		seen[edge.ID] = true
+	}
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+	c.Assert(seen, gc.DeepEquals, map[uuid.UUID]bool{
+		edgeIDs[[2]uuid.UUID{links[0].ID, links[2].ID}]: true,
+		edgeIDs[[2]uuid.UUID{links[2].ID, links[0].ID}]: true,
+	})
+
+	// Edges pointing to the removed link can no longer be created.
+	err = s.g.UpsertEdge(&graph.Edge{Src: links[0].ID, Dst: removed.ID})
+	c.Assert(errors.Is(err, graph.ErrUnknownEdgeLinks), gc.Equals, true, gc.Commentf("got %v", err))
+
+	// Upserting a link with the same URL must create a brand new link.
+	readded := &graph.Link{URL: removed.URL}
+	c.Assert(s.g.UpsertLink(readded), gc.IsNil)
+	c.Assert(readded.ID, gc.Not(gc.Equals), removed.ID)
+
+	// Removing an unknown or already removed link fails.
+	err = s.g.RemoveLink(removed.ID)
+	c.Assert(errors.Is(err, graph.ErrNotFound), gc.Equals, true, gc.Commentf("got %v", err))
+	err = s.g.RemoveLink(uuid.New())
+	c.Assert(errors.Is(err, graph.ErrNotFound), gc.Equals, true, gc.Commentf("got %v", err))
+}
+
 // TestConcurrentLinkIterators verifies that multiple clients can concurrently
 // access the store.
 func (s *SuiteBase) TestConcurrentLinkIterators(c *gc.C) {
@@ -187,6 +389,159 @@ func (s *SuiteBase) assertIteratedLinkIDsMatch(c *gc.C, updatedBefore time.Time,
 	c.Assert(got, gc.DeepEquals, exp)
 }
 
+// TestLinkCrawlMetadata verifies that the crawl metadata of links is
+// persisted and only replaced by more recent fetches.
+func (s *SuiteBase) TestLinkCrawlMetadata(c *gc.C) {
+	firstFetch := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+	link := &graph.Link{
+		URL:         "https://example.com/broken",
+		RetrievedAt: firstFetch,
+		StatusCode:  404,
+		ContentType: "text/html",
+		ContentHash: "d41d8cd98f00b204e9800998ecf8427e",
+		FetchErrors: 1,
+		LastError:   "not found",
+	}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+	c.Assert(link.FirstSeenAt.IsZero(), gc.Equals, false, gc.Commentf("expected FirstSeenAt to be populated"))
+
+	stored, err := s.g.FindLink(link.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stored, gc.DeepEquals, link)
+
+	// Re-discovering the link must neither clobber the metadata of the
+	// last fetch nor move FirstSeenAt forward.
+	rediscovered := &graph.Link{URL: link.URL}
+	c.Assert(s.g.UpsertLink(rediscovered), gc.IsNil)
+	c.Assert(rediscovered.FirstSeenAt.Equal(link.FirstSeenAt), gc.Equals, true)
+
+	stored, err = s.g.FindLink(link.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stored, gc.DeepEquals, link)
+
+	// A more recent fetch replaces the metadata.
+	refetched := &graph.Link{
+		URL:         link.URL,
+		RetrievedAt: firstFetch.Add(30 * time.Minute),
+		StatusCode:  200,
+		ContentType: "text/plain",
+		ContentHash: "9e107d9d372bb6826bd81d3542a419d6",
+	}
+	c.Assert(s.g.UpsertLink(refetched), gc.IsNil)
+
+	stored, err = s.g.FindLink(link.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stored.RetrievedAt, gc.Equals, refetched.RetrievedAt)
+	c.Assert(stored.StatusCode, gc.Equals, 200)
+	c.Assert(stored.ContentType, gc.Equals, "text/plain")
+	c.Assert(stored.ContentHash, gc.Equals, refetched.ContentHash)
+	c.Assert(stored.FetchErrors, gc.Equals, 0)
+	c.Assert(stored.LastError, gc.Equals, "")
+	c.Assert(stored.FirstSeenAt.Equal(link.FirstSeenAt), gc.Equals, true)
+
+	// An earlier first-seen timestamp (e.g. when importing links from
+	// another graph) replaces the stored one.
+	earlier := link.FirstSeenAt.Add(-24 * time.Hour).Truncate(time.Second)
+	c.Assert(s.g.UpsertLink(&graph.Link{URL: link.URL, FirstSeenAt: earlier}), gc.IsNil)
+
+	stored, err = s.g.FindLink(link.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stored.FirstSeenAt.Equal(earlier), gc.Equals, true)
+	c.Assert(stored.StatusCode, gc.Equals, 200)
+}
+
+// TestLinkIteratorMetadataFilter verifies that links can be filtered by
+// their crawl metadata.
+func (s *SuiteBase) TestLinkIteratorMetadataFilter(c *gc.C) {
+	retrievedAt := time.Now().Add(-time.Hour).Truncate(time.Second).UTC()
+	for _, link := range []*graph.Link{
+		{URL: "https://example.com/html", RetrievedAt: retrievedAt, StatusCode: 200, ContentType: "text/html; charset=utf-8"},
+		{URL: "https://example.com/pdf", RetrievedAt: retrievedAt, StatusCode: 200, ContentType: "application/pdf"},
+		{URL: "https://example.com/missing", RetrievedAt: retrievedAt, StatusCode: 404, ContentType: "text/html", FetchErrors: 1},
+		{URL: "https://example.com/unavailable", RetrievedAt: retrievedAt, StatusCode: 503, FetchErrors: 3, LastError: "service unavailable"},
+		{URL: "https://example.com/unfetched"},
+	} {
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+	}
+
+	specs := []struct {
+		descr   string
+		filters []graph.LinkFilter
+		exp     []string
+	}{
+		{
+			descr: "no filters",
+			exp:   []string{"html", "missing", "pdf", "unavailable", "unfetched"},
+		},
+		{
+			descr:   "empty filter",
+			filters: []graph.LinkFilter{{}},
+			exp:     []string{"html", "missing", "pdf", "unavailable", "unfetched"},
+		},
+		{
+			descr:   "status code lower bound",
+			filters: []graph.LinkFilter{{MinStatusCode: 400}},
+			exp:     []string{"missing", "unavailable"},
+		},
+		{
+			descr:   "status code range",
+			filters: []graph.LinkFilter{{MinStatusCode: 400, MaxStatusCode: 499}},
+			exp:     []string{"missing"},
+		},
+		{
+			descr:   "content type prefix",
+			filters: []graph.LinkFilter{{ContentTypePrefix: "text/html"}},
+			exp:     []string{"html", "missing"},
+		},
+		{
+			descr:   "content type prefix with wildcard characters",
+			filters: []graph.LinkFilter{{ContentTypePrefix: "text_"}, {ContentTypePrefix: "%"}},
+		},
+		{
+			descr:   "fetch errors",
+			filters: []graph.LinkFilter{{MinFetchErrors: 2}},
+			exp:     []string{"unavailable"},
+		},
+		{
+			descr:   "combined fields",
+			filters: []graph.LinkFilter{{MaxStatusCode: 499, ContentTypePrefix: "text/"}},
+			exp:     []string{"html", "missing"},
+		},
+		{
+			descr:   "any of multiple filters",
+			filters: []graph.LinkFilter{{MinStatusCode: 500}, {MinFetchErrors: 1}},
+			exp:     []string{"missing", "unavailable"},
+		},
+		{
+			descr:   "first seen in the future",
+			filters: []graph.LinkFilter{{FirstSeenAfter: time.Now().Add(time.Hour)}},
+		},
+		{
+			descr:   "first seen in the past",
+			filters: []graph.LinkFilter{{MinStatusCode: 500, FirstSeenAfter: time.Now().Add(-time.Hour)}},
+			exp:     []string{"unavailable"},
+		},
+	}
+
+	from, to := s.partitionRange(c, 0, 1)
+	for specIndex, spec := range specs {
+		c.Logf("[spec %d] %s", specIndex, spec.descr)
+
+		it, err := s.g.Links(from, to, time.Now(), spec.filters...)
+		c.Assert(err, gc.IsNil)
+
+		var got []string
+		for it.Next() {
+			got = append(got, strings.TrimPrefix(it.Link().URL, "https://example.com/"))
+		}
+		c.Assert(it.Error(), gc.IsNil)
+		c.Assert(it.Close(), gc.IsNil)
+
+		sort.Strings(got)
+		c.Assert(got, gc.DeepEquals, spec.exp)
+	}
+}
+
 // TestPartitionedLinkIterators verifies that the graph partitioning logic
 // works as expected even when partitions contain an uneven number of items.
 func (s *SuiteBase) TestPartitionedLinkIterators(c *gc.C) {
@@ -267,6 +622,120 @@ func (s *SuiteBase) TestUpsertEdge(c *gc.C) {
 	c.Assert(errors.Is(err, graph.ErrUnknownEdgeLinks), gc.Equals, true)
 }
 
+// TestUpsertEdges verifies the batch edge upsert logic.
+func (s *SuiteBase) TestUpsertEdges(c *gc.C) {
+	linkUUIDs := make([]uuid.UUID, 3)
+	for i := 0; i < len(linkUUIDs); i++ {
+		link := &graph.Link{URL: fmt.Sprint(i)}
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+		linkUUIDs[i] = link.ID
+	}
+
+	existing := &graph.Edge{Src: linkUUIDs[0], Dst: linkUUIDs[1]}
+	c.Assert(s.g.UpsertEdge(existing), gc.IsNil)
+
+	batch := []*graph.Edge{
+		{Src: linkUUIDs[0], Dst: linkUUIDs[1]},
+		{Src: linkUUIDs[0], Dst: uuid.New()},
+		{Src: linkUUIDs[1], Dst: linkUUIDs[2]},
+		{Src: linkUUIDs[2], Dst: linkUUIDs[0]},
+	}
+	err := s.g.UpsertEdges(batch)
+
+	var batchErr *graph.BatchError
+	c.Assert(errors.As(err, &batchErr), gc.Equals, true, gc.Commentf("expected a *graph.BatchError; got %v", err))
+	c.Assert(batchErr.Errors, gc.HasLen, len(batch))
+	for i, itemErr := range batchErr.Errors {
+		if i == 1 {
+			c.Assert(errors.Is(itemErr, graph.ErrUnknownEdgeLinks), gc.Equals, true, gc.Commentf("batch entry %d: %v", i, itemErr))
+			continue
+		}
+		c.Assert(itemErr, gc.IsNil, gc.Commentf("batch entry %d", i))
+		c.Assert(batch[i].ID, gc.Not(gc.Equals), uuid.Nil, gc.Commentf("expected an ID to be assigned to batch entry %d", i))
+		c.Assert(batch[i].UpdatedAt.IsZero(), gc.Equals, false, gc.Commentf("expected an update timestamp to be assigned to batch entry %d", i))
+	}
+	c.Assert(batch[0].ID, gc.Equals, existing.ID, gc.Commentf("expected the ID of the existing edge to be reused"))
+
+	// Verify that only the valid edges were persisted
+	it, err := s.partitionedEdgeIterator(c, 0, 1, time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	seen := make(map[uuid.UUID]bool)
+	for it.Next() {
+		seen[it.Edge().ID] = true
+	}
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+	c.Assert(seen, gc.DeepEquals, map[uuid.UUID]bool{batch[0].ID: true, batch[2].ID: true, batch[3].ID: true})
+
+	// A batch without invalid edges yields no error.
+	c.Assert(s.g.UpsertEdges(batch[2:]), gc.IsNil)
+}
+
+// TestEdgeAttributes verifies that edge attributes are persisted and
+// replaced when an edge is upserted again.
+func (s *SuiteBase) TestEdgeAttributes(c *gc.C) {
+	src := &graph.Link{URL: "https://example.com/src"}
+	dst := &graph.Link{URL: "https://example.com/dst"}
+	c.Assert(s.g.UpsertLinks([]*graph.Link{src, dst}), gc.IsNil)
+
+	edge := &graph.Edge{
+		Src:        src.ID,
+		Dst:        dst.ID,
+		AnchorText: "an example",
+		NoFollow:   true,
+		UGC:        true,
+		LinkCount:  3,
+	}
+	c.Assert(s.g.UpsertEdge(edge), gc.IsNil)
+	s.assertStoredEdge(c, edge)
+
+	// Upserting the edge again replaces its attributes. Edges without a
+	// link count are stored with a count of 1.
+	updated := &graph.Edge{
+		Src:        src.ID,
+		Dst:        dst.ID,
+		AnchorText: "another example",
+		Sponsored:  true,
+	}
+	c.Assert(s.g.UpsertEdge(updated), gc.IsNil)
+	c.Assert(updated.ID, gc.Equals, edge.ID)
+	c.Assert(updated.LinkCount, gc.Equals, 1)
+	s.assertStoredEdge(c, updated)
+
+	// Batch upserts must also persist the attributes.
+	batched := &graph.Edge{
+		Src:        src.ID,
+		Dst:        dst.ID,
+		AnchorText: "batched",
+		NoFollow:   true,
+		LinkCount:  2,
+	}
+	c.Assert(s.g.UpsertEdges([]*graph.Edge{batched}), gc.IsNil)
+	c.Assert(batched.ID, gc.Equals, edge.ID)
+	s.assertStoredEdge(c, batched)
+}
+
+// assertStoredEdge checks that the edge returned by both the Edges and the
+// InboundEdges iterators matches exp.
+func (s *SuiteBase) assertStoredEdge(c *gc.C, exp *graph.Edge) {
+	from, to := s.partitionRange(c, 0, 1)
+	it, err := s.g.Edges(from, to, time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Edge(), gc.DeepEquals, exp)
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+
+	it, err = s.g.InboundEdges(exp.Dst, time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Edge(), gc.DeepEquals, exp)
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+}
+
 // TestConcurrentEdgeIterators verifies that multiple clients can concurrently
 // access the store.
 func (s *SuiteBase) TestConcurrentEdgeIterators(c *gc.C) {
@@ -431,6 +900,65 @@ func (s *SuiteBase) iteratePartitionedEdges(c *gc.C, numPartitions int) int {
 	return len(seen)
 }
 
+// TestInboundEdges verifies that the edges pointing to a link can be
+// retrieved and filtered by their update timestamp.
+func (s *SuiteBase) TestInboundEdges(c *gc.C) {
+	numLinks := 5
+	linkUUIDs := make([]uuid.UUID, numLinks)
+	for i := 0; i < numLinks; i++ {
+		link := &graph.Link{URL: fmt.Sprint(i)}
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+		linkUUIDs[i] = link.ID
+	}
+
+	// Point all links to linkUUIDs[0]. The first half of the edges will
+	// have an updated at value < splitTs.
+	var (
+		splitTs time.Time
+		oldIDs  = make(map[uuid.UUID]bool)
+		allIDs  = make(map[uuid.UUID]bool)
+	)
+	for i := 1; i < numLinks; i++ {
+		if i == numLinks/2+1 {
+			time.Sleep(250 * time.Millisecond)
+			splitTs = time.Now()
+			time.Sleep(250 * time.Millisecond)
+		}
+		edge := &graph.Edge{Src: linkUUIDs[i], Dst: linkUUIDs[0]}
+		c.Assert(s.g.UpsertEdge(edge), gc.IsNil)
+		if splitTs.IsZero() {
+			oldIDs[edge.ID] = true
+		}
+		allIDs[edge.ID] = true
+	}
+
+	// An outgoing edge of the target link must not be reported.
+	c.Assert(s.g.UpsertEdge(&graph.Edge{Src: linkUUIDs[0], Dst: linkUUIDs[1]}), gc.IsNil)
+
+	c.Assert(s.inboundEdgeIDs(c, linkUUIDs[0], time.Now().Add(time.Minute)), gc.DeepEquals, allIDs)
+	c.Assert(s.inboundEdgeIDs(c, linkUUIDs[0], splitTs), gc.DeepEquals, oldIDs)
+	c.Assert(s.inboundEdgeIDs(c, linkUUIDs[numLinks-1], time.Now().Add(time.Minute)), gc.HasLen, 0)
+
+	// Removing a source link also removes its edge from the index.
+	c.Assert(s.g.RemoveLink(linkUUIDs[numLinks-1]), gc.IsNil)
+	c.Assert(s.inboundEdgeIDs(c, linkUUIDs[0], time.Now().Add(time.Minute)), gc.HasLen, numLinks-2)
+}
+
+func (s *SuiteBase) inboundEdgeIDs(c *gc.C, dstID uuid.UUID, updatedBefore time.Time) map[uuid.UUID]bool {
+	it, err := s.g.InboundEdges(dstID, updatedBefore)
+	c.Assert(err, gc.IsNil)
+
+	seen := make(map[uuid.UUID]bool)
+	for it.Next() {
+		edge := it.Edge()
+		c.Assert(edge.Dst, gc.Equals, dstID)
+		seen[edge.ID] = true
+	}
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+	return seen
+}
+
 // TestRemoveStaleEdges verifies that the edge deletion logic works as expected.
 func (s *SuiteBase) TestRemoveStaleEdges(c *gc.C) {
 	numEdges := 100
@@ -481,51 +1009,97 @@ func (s *SuiteBase) TestRemoveStaleEdges(c *gc.C) {
 	c.Assert(seen, gc.Equals, numEdges)
 }
 
-func (s *SuiteBase) partitionedLinkIterator(c *gc.C, partition, numPartitions int, accessedBefore time.Time) (graph.LinkIterator, error) {
-	from, to := s.partitionRange(c, partition, numPartitions)
-	return s.g.Links(from, to, accessedBefore)
-}
+// TestCancelledContext verifies that the Context variants of the graph
+// methods refuse to operate on a context that has already been cancelled.
+func (s *SuiteBase) TestCancelledContext(c *gc.C) {
+	link := &graph.Link{URL: "https://example.com"}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
 
-func (s *SuiteBase) partitionedEdgeIterator(c *gc.C, partition, numPartitions int, updatedBefore time.Time) (graph.EdgeIterator, error) {
-	from, to := s.partitionRange(c, partition, numPartitions)
-	return s.g.Edges(from, to, updatedBefore)
-}
+	ctx, cancelFn := context.WithCancel(context.Background())
+	cancelFn()
 
-func (s *SuiteBase) partitionRange(c *gc.C, partition, numPartitions int) (from, to uuid.UUID) {
-	if partition < 0 || partition >= numPartitions {
-		c.Fatal("invalid partition")
-	}
+	err := s.g.UpsertLinkContext(ctx, &graph.Link{URL: "https://example.com/cancelled"})
+	c.Assert(errors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("upsert link: %v", err))
+
+	_, err = s.g.FindLinkContext(ctx, link.ID)
+	c.Assert(errors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("find link: %v", err))
 
-	var minUUID = uuid.Nil
-	var maxUUID = uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff")
-	var err error
+	err = s.g.UpsertEdgeContext(ctx, &graph.Edge{Src: link.ID, Dst: link.ID})
+	c.Assert(errors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("upsert edge: %v", err))
 
-	// Calculate the size of each partition as: (2^128 / numPartitions)
-	tokenRange := big.NewInt(0)
-	partSize := big.NewInt(0)
-	partSize.SetBytes(maxUUID[:])
-	partSize = partSize.Div(partSize, big.NewInt(int64(numPartitions)))
+	err = s.g.RemoveStaleEdgesContext(ctx, link.ID, time.Now())
+	c.Assert(errors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("remove stale edges: %v", err))
 
-	// We model the partitions as a segment that begins at minUUID (all
-	// bits set to zero) and ends at maxUUID (all bits set to 1). By
-	// setting the end range for the *last* partition to maxUUID we ensure
-	// that we always cover the full range of UUIDs even if the range
-	// itself is not evenly divisible by numPartitions.
-	if partition == 0 {
-		from = minUUID
+	// Iterator constructors may either fail straight away or return an
+	// iterator that reports the cancellation on the first call to Next.
+	from, to := s.partitionRange(c, 0, 1)
+	if linkIt, err := s.g.LinksContext(ctx, from, to, time.Now()); err != nil {
+		c.Assert(errors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("links: %v", err))
 	} else {
-		tokenRange.Mul(partSize, big.NewInt(int64(partition)))
-		from, err = uuid.FromBytes(tokenRange.Bytes())
-		c.Assert(err, gc.IsNil)
+		c.Assert(linkIt.Next(), gc.Equals, false)
+		c.Assert(errors.Is(linkIt.Error(), context.Canceled), gc.Equals, true, gc.Commentf("link iterator: %v", linkIt.Error()))
+		c.Assert(linkIt.Close(), gc.IsNil)
 	}
-
-	if partition == numPartitions-1 {
-		to = maxUUID
+	if edgeIt, err := s.g.EdgesContext(ctx, from, to, time.Now()); err != nil {
+		c.Assert(errors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("edges: %v", err))
 	} else {
-		tokenRange.Mul(partSize, big.NewInt(int64(partition+1)))
-		to, err = uuid.FromBytes(tokenRange.Bytes())
-		c.Assert(err, gc.IsNil)
+		c.Assert(edgeIt.Next(), gc.Equals, false)
+		c.Assert(errors.Is(edgeIt.Error(), context.Canceled), gc.Equals, true, gc.Commentf("edge iterator: %v", edgeIt.Error()))
+		c.Assert(edgeIt.Close(), gc.IsNil)
+	}
+}
+
+// TestIteratorContextCancellation verifies that link and edge iterators stop
+// and report the context error when their context is cancelled while they
+// are being consumed.
+func (s *SuiteBase) TestIteratorContextCancellation(c *gc.C) {
+	numLinks := 10
+	linkIDs := make([]uuid.UUID, numLinks)
+	for i := 0; i < numLinks; i++ {
+		link := &graph.Link{URL: fmt.Sprint(i)}
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+		linkIDs[i] = link.ID
+	}
+	for i := 0; i < numLinks; i++ {
+		c.Assert(s.g.UpsertEdge(&graph.Edge{Src: linkIDs[0], Dst: linkIDs[i]}), gc.IsNil)
 	}
 
+	from, to := s.partitionRange(c, 0, 1)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	linkIt, err := s.g.LinksContext(ctx, from, to, time.Now())
+	c.Assert(err, gc.IsNil)
+	c.Assert(linkIt.Next(), gc.Equals, true)
+	cancelFn()
+	c.Assert(linkIt.Next(), gc.Equals, false)
+	c.Assert(errors.Is(linkIt.Error(), context.Canceled), gc.Equals, true, gc.Commentf("link iterator: %v", linkIt.Error()))
+	_ = linkIt.Close()
+
+	ctx, cancelFn = context.WithCancel(context.Background())
+	edgeIt, err := s.g.EdgesContext(ctx, from, to, time.Now())
+	c.Assert(err, gc.IsNil)
+	c.Assert(edgeIt.Next(), gc.Equals, true)
+	cancelFn()
+	c.Assert(edgeIt.Next(), gc.Equals, false)
+	c.Assert(errors.Is(edgeIt.Error(), context.Canceled), gc.Equals, true, gc.Commentf("edge iterator: %v", edgeIt.Error()))
+	_ = edgeIt.Close()
+}
+
+func (s *SuiteBase) partitionedLinkIterator(c *gc.C, partitionNum, numPartitions int, accessedBefore time.Time) (graph.LinkIterator, error) {
+	from, to := s.partitionRange(c, partitionNum, numPartitions)
+	return s.g.Links(from, to, accessedBefore)
+}
+
+func (s *SuiteBase) partitionedEdgeIterator(c *gc.C, partitionNum, numPartitions int, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	from, to := s.partitionRange(c, partitionNum, numPartitions)
+	return s.g.Edges(from, to, updatedBefore)
+}
+
+func (s *SuiteBase) partitionRange(c *gc.C, partitionNum, numPartitions int) (from, to uuid.UUID) {
+	r, err := partition.NewFullRange(numPartitions)
+	c.Assert(err, gc.IsNil)
+
+	from, to, err = r.PartitionExtents(partitionNum)
+	c.Assert(err, gc.IsNil)
 	return from, to
 }
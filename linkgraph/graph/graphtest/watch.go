@@ -0,0 +1,137 @@
+package graphtest
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	gc "gopkg.in/check.v1"
+)
+
+// watchTimeout bounds the time that the change feed tests wait for events.
+const watchTimeout = 30 * time.Second
+
+// watcher returns the graph under test as a graph.Watcher or skips the
+// test if the graph does not provide a change feed.
+func (s *SuiteBase) watcher(c *gc.C) graph.Watcher {
+	w, ok := s.g.(graph.Watcher)
+	if !ok {
+		c.Skip("graph does not implement graph.Watcher")
+	}
+	return w
+}
+
+// TestWatch verifies that mutations are reported in order by the change
+// feed and that the feed can be resumed from any event cursor.
+func (s *SuiteBase) TestWatch(c *gc.C) {
+	w := s.watcher(c)
+	ctx, cancelFn := context.WithTimeout(context.Background(), watchTimeout)
+	defer cancelFn()
+
+	start, err := w.LastEventCursor(ctx)
+	c.Assert(err, gc.IsNil)
+
+	src := &graph.Link{URL: "https://example.com", RetrievedAt: time.Now().Truncate(time.Second).UTC(), StatusCode: 200}
+	dst := &graph.Link{URL: "https://example.com/about"}
+	c.Assert(s.g.UpsertLinks([]*graph.Link{src, dst}), gc.IsNil)
+	edge := &graph.Edge{Src: src.ID, Dst: dst.ID, AnchorText: "about", NoFollow: true}
+	c.Assert(s.g.UpsertEdge(edge), gc.IsNil)
+	staleBefore := edge.UpdatedAt.Add(-time.Minute)
+	c.Assert(s.g.RemoveStaleEdges(src.ID, staleBefore), gc.IsNil)
+	c.Assert(s.g.RemoveLink(dst.ID), gc.IsNil)
+
+	events := s.nextEvents(c, ctx, w, start, 5)
+	for i := 1; i < len(events); i++ {
+		c.Assert(events[i].Cursor > events[i-1].Cursor, gc.Equals, true, gc.Commentf("events %d and %d are out of order", i-1, i))
+	}
+
+	// The order of the events for links that are upserted in the same
+	// batch is unspecified.
+	upsertedLinks := make(map[string]*graph.Link)
+	for _, ev := range events[:2] {
+		c.Assert(ev.Type, gc.Equals, graph.EventLinkUpserted)
+		upsertedLinks[ev.Link.URL] = ev.Link
+	}
+	c.Assert(upsertedLinks[src.URL], gc.NotNil)
+	c.Assert(upsertedLinks[src.URL].ID, gc.Equals, src.ID)
+	c.Assert(upsertedLinks[src.URL].RetrievedAt, gc.Equals, src.RetrievedAt)
+	c.Assert(upsertedLinks[src.URL].StatusCode, gc.Equals, 200)
+	c.Assert(upsertedLinks[dst.URL], gc.NotNil)
+	c.Assert(upsertedLinks[dst.URL].ID, gc.Equals, dst.ID)
+
+	c.Assert(events[2].Type, gc.Equals, graph.EventEdgeUpserted)
+	c.Assert(events[2].Edge.ID, gc.Equals, edge.ID)
+	c.Assert(events[2].Edge.Src, gc.Equals, src.ID)
+	c.Assert(events[2].Edge.Dst, gc.Equals, dst.ID)
+	c.Assert(events[2].Edge.AnchorText, gc.Equals, "about")
+	c.Assert(events[2].Edge.NoFollow, gc.Equals, true)
+	c.Assert(events[2].Edge.LinkCount, gc.Equals, 1)
+
+	c.Assert(events[3].Type, gc.Equals, graph.EventStaleEdgesRemoved)
+	c.Assert(events[3].LinkID, gc.Equals, src.ID)
+	c.Assert(events[3].UpdatedBefore.Equal(staleBefore), gc.Equals, true)
+
+	c.Assert(events[4].Type, gc.Equals, graph.EventLinkRemoved)
+	c.Assert(events[4].LinkID, gc.Equals, dst.ID)
+
+	// Resuming the feed must skip the events that were already consumed.
+	resumed := s.nextEvents(c, ctx, w, events[2].Cursor, 2)
+	c.Assert(resumed[0].Cursor, gc.Equals, events[3].Cursor)
+	c.Assert(resumed[1].Cursor, gc.Equals, events[4].Cursor)
+
+	last, err := w.LastEventCursor(ctx)
+	c.Assert(err, gc.IsNil)
+	c.Assert(last, gc.Equals, events[4].Cursor)
+}
+
+// TestWatchBlocksUntilNextEvent verifies that a caught-up change feed waits
+// for new events and stops when its context is cancelled.
+func (s *SuiteBase) TestWatchBlocksUntilNextEvent(c *gc.C) {
+	w := s.watcher(c)
+	ctx, cancelFn := context.WithTimeout(context.Background(), watchTimeout)
+	defer cancelFn()
+
+	start, err := w.LastEventCursor(ctx)
+	c.Assert(err, gc.IsNil)
+	it, err := w.Watch(ctx, start)
+	c.Assert(err, gc.IsNil)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = s.g.UpsertLink(&graph.Link{URL: "https://example.com/"})
+	}()
+
+	c.Assert(it.Next(), gc.Equals, true, gc.Commentf("%v", it.Error()))
+	c.Assert(it.Event().Type, gc.Equals, graph.EventLinkUpserted)
+	c.Assert(it.Event().Link.URL, gc.Equals, "https://example.com/")
+
+	time.AfterFunc(100*time.Millisecond, cancelFn)
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(errors.Is(it.Error(), context.Canceled), gc.Equals, true, gc.Commentf("%v", it.Error()))
+	c.Assert(it.Close(), gc.IsNil)
+}
+
+// TestWatchInvalidCursor verifies that watching from a cursor that was not
+// obtained from the graph fails.
+func (s *SuiteBase) TestWatchInvalidCursor(c *gc.C) {
+	w := s.watcher(c)
+
+	_, err := w.Watch(context.TODO(), "bogus")
+	c.Assert(errors.Is(err, graph.ErrInvalidEventCursor), gc.Equals, true, gc.Commentf("%v", err))
+}
+
+// nextEvents consumes the next n events following after.
+func (s *SuiteBase) nextEvents(c *gc.C, ctx context.Context, w graph.Watcher, after graph.EventCursor, n int) []*graph.Event {
+	it, err := w.Watch(ctx, after)
+	c.Assert(err, gc.IsNil)
+
+	var events []*graph.Event
+	for len(events) < n && it.Next() {
+		events = append(events, it.Event())
+	}
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+	c.Assert(events, gc.HasLen, n)
+	return events
+}
@@ -0,0 +1,11 @@
+package graph
+
+import "github.com/google/uuid"
+
+// LinkIDFromURL returns the deterministic link ID for the specified
+// canonical URL. IDs are version 5 UUIDs derived from the URL within the
+// RFC 4122 URL namespace so that the same URL maps to the same ID in every
+// graph that is configured to use deterministic IDs.
+func LinkIDFromURL(url string) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(url))
+}
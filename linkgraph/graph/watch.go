@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType describes the kind of mutation reported by an Event.
+type EventType uint8
+
+// The supported event types.
+const (
+	// A link was created or updated. Event.Link holds the link as stored.
+	EventLinkUpserted EventType = iota + 1
+
+	// An edge was created or updated. Event.Edge holds the edge as stored.
+	EventEdgeUpserted
+
+	// The edges originating from Event.LinkID that were last updated
+	// before Event.UpdatedBefore were removed.
+	EventStaleEdgesRemoved
+
+	// The link with ID Event.LinkID was removed together with all edges
+	// that originate from or point to it.
+	EventLinkRemoved
+)
+
+// String implements fmt.Stringer.
+func (t EventType) String() string {
+	switch t {
+	case EventLinkUpserted:
+		return "link upserted"
+	case EventEdgeUpserted:
+		return "edge upserted"
+	case EventStaleEdgesRemoved:
+		return "stale edges removed"
+	case EventLinkRemoved:
+		return "link removed"
+	default:
+		return "unknown"
+	}
+}
+
+// EventCursor identifies the position of an event in the change feed of a
+// graph. Cursors are opaque, but the cursors of the events of a graph compare
+// (as strings) in the order in which the events are delivered. The empty
+// cursor precedes all events.
+type EventCursor string
+
+// Event describes a mutation that was applied to the graph.
+type Event struct {
+	// The position of the event in the change feed. It can be passed to
+	// Watch to resume the feed after the event.
+	Cursor EventCursor
+	// The kind of mutation.
+	Type EventType
+
+	// The upserted link; only set for EventLinkUpserted.
+	Link *Link
+	// The upserted edge; only set for EventEdgeUpserted.
+	Edge *Edge
+	// The ID of the removed link or the source link of the removed stale
+	// edges.
+	LinkID uuid.UUID
+	// The timestamp passed to RemoveStaleEdges; only set for
+	// EventStaleEdgesRemoved.
+	UpdatedBefore time.Time
+}
+
+// EventIterator is implemented by objects that can stream graph events.
+type EventIterator interface {
+	Iterator
+
+	// Event returns the currently fetched event.
+	Event() *Event
+}
+
+// Watcher is implemented by graphs that provide a change feed for the
+// mutations applied to them.
+type Watcher interface {
+	// Watch returns an iterator for the events following the event
+	// identified by after. Events are returned in cursor order. Once all
+	// past events have been consumed, the iterator's Next method blocks
+	// until a new event arrives or ctx is cancelled, in which case Next
+	// returns false and the iterator reports ctx.Err().
+	//
+	// ErrEventsUnavailable is returned if the graph can no longer (or not
+	// yet) provide all events following after. ErrInvalidEventCursor is
+	// returned if after was not obtained from the graph.
+	Watch(ctx context.Context, after EventCursor) (EventIterator, error)
+
+	// LastEventCursor returns the cursor of the most recent event.
+	// Clients that need to process the whole graph can obtain the current
+	// cursor, scan the graph and then watch for events following it;
+	// events for mutations that raced with the scan are delivered again.
+	LastEventCursor(ctx context.Context) (EventCursor, error)
+}
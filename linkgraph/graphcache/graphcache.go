@@ -0,0 +1,285 @@
+// Package graphcache provides a graph.Graph decorator that caches link
+// lookups in a bounded LRU cache.
+package graphcache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/google/uuid"
+)
+
+// Config encapsulates the settings for a CachingGraph.
+type Config struct {
+	// The maximum number of cached lookups. Lookups by ID and by URL are
+	// cached separately and both count towards the limit. Defaults to
+	// 10000.
+	Size int
+
+	// The time after which cached links expire. If zero, cached links only
+	// leave the cache when they are evicted or invalidated.
+	TTL time.Duration
+}
+
+// Stats summarizes the cache activity of a CachingGraph.
+type Stats struct {
+	// The number of lookups that were served from the cache.
+	Hits uint64
+
+	// The number of lookups that were forwarded to the wrapped graph.
+	Misses uint64
+
+	// The number of entries that were evicted to make room for new ones.
+	Evictions uint64
+
+	// The number of entries that were removed because the cached link was
+	// modified through the decorator.
+	Invalidations uint64
+}
+
+// cacheKey identifies a cached lookup. Exactly one of its fields is set.
+type cacheKey struct {
+	id  uuid.UUID
+	url string
+}
+
+// cacheEntry is the value stored in the LRU list.
+type cacheEntry struct {
+	key       cacheKey
+	link      *graph.Link
+	expiresAt time.Time
+}
+
+// CachingGraph is a graph.Graph decorator that serves FindLink and
+// FindLinkByURL from a read-through LRU cache. All other graph.Graph methods
+// are forwarded to the wrapped graph; optional interfaces such as
+// graph.Watcher are not.
+//
+// Cached links are invalidated when they are upserted or removed through
+// the decorator. Modifications made by other clients of the underlying
+// store only become visible once the cached entries expire, so a TTL should
+// be configured if the store is shared. Failed lookups are not cached.
+type CachingGraph struct {
+	graph.Graph
+	cfg Config
+
+	// mu guards the fields below.
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[cacheKey]*list.Element
+	// urlKeys tracks the URL lookups that resolved to each cached link ID
+	// so that they can be invalidated together with the link.
+	urlKeys map[uuid.UUID]map[string]struct{}
+	// epoch is incremented on each invalidation. Lookups that raced with
+	// an invalidation do not populate the cache.
+	epoch uint64
+	stats Stats
+}
+
+// New returns a CachingGraph that wraps g.
+func New(g graph.Graph, cfg Config) *CachingGraph {
+	if cfg.Size <= 0 {
+		cfg.Size = 10000
+	}
+
+	return &CachingGraph{
+		Graph:   g,
+		cfg:     cfg,
+		lru:     list.New(),
+		entries: make(map[cacheKey]*list.Element),
+		urlKeys: make(map[uuid.UUID]map[string]struct{}),
+	}
+}
+
+// Stats returns the cache activity so far.
+func (c *CachingGraph) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// FindLink looks up a link by its ID, consulting the cache first.
+func (c *CachingGraph) FindLink(id uuid.UUID) (*graph.Link, error) {
+	return c.FindLinkContext(context.Background(), id)
+}
+
+// FindLinkContext implements graph.Graph.
+func (c *CachingGraph) FindLinkContext(ctx context.Context, id uuid.UUID) (*graph.Link, error) {
+	key := cacheKey{id: id}
+	cached, epoch, found := c.get(key)
+	if found {
+		return cached, nil
+	}
+
+	link, err := c.Graph.FindLinkContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, link, epoch)
+	return link, nil
+}
+
+// FindLinkByURL looks up a link by its URL, consulting the cache first.
+func (c *CachingGraph) FindLinkByURL(url string) (*graph.Link, error) {
+	return c.FindLinkByURLContext(context.Background(), url)
+}
+
+// FindLinkByURLContext implements graph.Graph.
+func (c *CachingGraph) FindLinkByURLContext(ctx context.Context, url string) (*graph.Link, error) {
+	key := cacheKey{url: url}
+	cached, epoch, found := c.get(key)
+	if found {
+		return cached, nil
+	}
+
+	link, err := c.Graph.FindLinkByURLContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, link, epoch)
+	return link, nil
+}
+
+// UpsertLink creates or updates a link and invalidates its cached copies.
+func (c *CachingGraph) UpsertLink(link *graph.Link) error {
+	return c.UpsertLinkContext(context.Background(), link)
+}
+
+// UpsertLinkContext implements graph.Graph.
+func (c *CachingGraph) UpsertLinkContext(ctx context.Context, link *graph.Link) error {
+	err := c.Graph.UpsertLinkContext(ctx, link)
+	c.invalidate(link.ID)
+	return err
+}
+
+// UpsertLinks creates or updates a batch of links and invalidates their
+// cached copies.
+func (c *CachingGraph) UpsertLinks(links []*graph.Link) error {
+	return c.UpsertLinksContext(context.Background(), links)
+}
+
+// UpsertLinksContext implements graph.Graph.
+func (c *CachingGraph) UpsertLinksContext(ctx context.Context, links []*graph.Link) error {
+	err := c.Graph.UpsertLinksContext(ctx, links)
+	ids := make([]uuid.UUID, len(links))
+	for i, link := range links {
+		ids[i] = link.ID
+	}
+	c.invalidate(ids...)
+	return err
+}
+
+// RemoveLink deletes a link and invalidates its cached copies.
+func (c *CachingGraph) RemoveLink(id uuid.UUID) error {
+	return c.RemoveLinkContext(context.Background(), id)
+}
+
+// RemoveLinkContext implements graph.Graph.
+func (c *CachingGraph) RemoveLinkContext(ctx context.Context, id uuid.UUID) error {
+	err := c.Graph.RemoveLinkContext(ctx, id)
+	c.invalidate(id)
+	return err
+}
+
+// get returns a copy of the cached link for key together with the current
+// epoch. Expired entries are removed and reported as misses.
+func (c *CachingGraph) get(key cacheKey) (*graph.Link, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem := c.entries[key]
+	if elem == nil {
+		c.stats.Misses++
+		return nil, c.epoch, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.removeElement(elem)
+		c.stats.Misses++
+		return nil, c.epoch, false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.stats.Hits++
+	lCopy := new(graph.Link)
+	*lCopy = *entry.link
+	return lCopy, c.epoch, true
+}
+
+// put caches a copy of link under key unless an invalidation took place
+// since the lookup that returned epoch.
+func (c *CachingGraph) put(key cacheKey, link *graph.Link, epoch uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if epoch != c.epoch {
+		return
+	}
+
+	if elem := c.entries[key]; elem != nil {
+		c.removeElement(elem)
+	}
+
+	entry := &cacheEntry{key: key, link: new(graph.Link)}
+	*entry.link = *link
+	if c.cfg.TTL > 0 {
+		entry.expiresAt = time.Now().Add(c.cfg.TTL)
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	if key.url != "" {
+		if c.urlKeys[link.ID] == nil {
+			c.urlKeys[link.ID] = make(map[string]struct{})
+		}
+		c.urlKeys[link.ID][key.url] = struct{}{}
+	}
+
+	for c.lru.Len() > c.cfg.Size {
+		c.removeElement(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// invalidate removes all cached lookups that resolved to any of the
+// specified link IDs.
+func (c *CachingGraph) invalidate(ids ...uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.epoch++
+	for _, id := range ids {
+		if id == uuid.Nil {
+			continue
+		}
+
+		if elem := c.entries[cacheKey{id: id}]; elem != nil {
+			c.removeElement(elem)
+			c.stats.Invalidations++
+		}
+		for url := range c.urlKeys[id] {
+			if elem := c.entries[cacheKey{url: url}]; elem != nil {
+				c.removeElement(elem)
+				c.stats.Invalidations++
+			}
+		}
+	}
+}
+
+// removeElement removes elem from the cache. The caller must hold the lock.
+func (c *CachingGraph) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+
+	if entry.key.url == "" {
+		return
+	}
+	if urls := c.urlKeys[entry.link.ID]; urls != nil {
+		delete(urls, entry.key.url)
+		if len(urls) == 0 {
+			delete(c.urlKeys, entry.link.ID)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package graphcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory"
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var (
+	_ = gc.Suite(new(CachingGraphTestSuite))
+	_ = gc.Suite(new(CacheTestSuite))
+)
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+// CachingGraphTestSuite runs the graph conformance tests against a caching
+// decorator that wraps an in-memory graph.
+type CachingGraphTestSuite struct {
+	graphtest.SuiteBase
+}
+
+func (s *CachingGraphTestSuite) SetUpTest(c *gc.C) {
+	s.SetGraph(New(memory.NewInMemoryGraph(), Config{Size: 16}))
+}
+
+type CacheTestSuite struct {
+	g     *memory.InMemoryGraph
+	cache *CachingGraph
+}
+
+func (s *CacheTestSuite) SetUpTest(c *gc.C) {
+	s.g = memory.NewInMemoryGraph()
+	s.cache = New(s.g, Config{Size: 3})
+}
+
+// TestReadThrough verifies that repeated lookups are served from the cache
+// and that callers receive copies of the cached links.
+func (s *CacheTestSuite) TestReadThrough(c *gc.C) {
+	link := &graph.Link{URL: "http://example.com/", StatusCode: 200}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+
+	for i := 0; i < 3; i++ {
+		got, err := s.cache.FindLink(link.ID)
+		c.Assert(err, gc.IsNil)
+		c.Assert(got.URL, gc.Equals, link.URL)
+		got.StatusCode = 500
+	}
+	got, err := s.cache.FindLinkByURL("HTTP://Example.com")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.ID, gc.Equals, link.ID)
+	c.Assert(got.StatusCode, gc.Equals, 200)
+	c.Assert(s.cache.Stats(), gc.Equals, Stats{Hits: 2, Misses: 2})
+
+	// Failed lookups are not cached.
+	for i := 0; i < 2; i++ {
+		_, err = s.cache.FindLinkByURL("http://missing.example/")
+		c.Assert(xerrors.Is(err, graph.ErrNotFound), gc.Equals, true)
+	}
+	c.Assert(s.cache.Stats().Misses, gc.Equals, uint64(4))
+}
+
+// TestInvalidation verifies that writes through the decorator invalidate
+// both the ID and URL lookups for the affected link.
+func (s *CacheTestSuite) TestInvalidation(c *gc.C) {
+	link := &graph.Link{URL: "http://example.com/"}
+	c.Assert(s.cache.UpsertLink(link), gc.IsNil)
+	_, err := s.cache.FindLink(link.ID)
+	c.Assert(err, gc.IsNil)
+	_, err = s.cache.FindLinkByURL("http://EXAMPLE.com/#top")
+	c.Assert(err, gc.IsNil)
+
+	retrievedAt := time.Now().Truncate(time.Second).UTC()
+	c.Assert(s.cache.UpsertLinks([]*graph.Link{{URL: "http://example.com", RetrievedAt: retrievedAt, StatusCode: 404}}), gc.IsNil)
+	c.Assert(s.cache.Stats().Invalidations, gc.Equals, uint64(2))
+
+	got, err := s.cache.FindLink(link.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.StatusCode, gc.Equals, 404)
+	got, err = s.cache.FindLinkByURL("http://EXAMPLE.com/#top")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.RetrievedAt, gc.Equals, retrievedAt)
+
+	c.Assert(s.cache.RemoveLink(link.ID), gc.IsNil)
+	_, err = s.cache.FindLink(link.ID)
+	c.Assert(xerrors.Is(err, graph.ErrNotFound), gc.Equals, true)
+	_, err = s.cache.FindLinkByURL("http://EXAMPLE.com/#top")
+	c.Assert(xerrors.Is(err, graph.ErrNotFound), gc.Equals, true)
+}
+
+// TestEviction verifies that the least recently used entries are evicted
+// once the cache is full.
+func (s *CacheTestSuite) TestEviction(c *gc.C) {
+	var links []*graph.Link
+	for _, u := range []string{"http://a.example/", "http://b.example/", "http://c.example/", "http://d.example/"} {
+		link := &graph.Link{URL: u}
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+		links = append(links, link)
+	}
+
+	for _, link := range links[:3] {
+		_, err := s.cache.FindLink(link.ID)
+		c.Assert(err, gc.IsNil)
+	}
+	// Touch the first link so that the second one becomes the least
+	// recently used entry.
+	_, err := s.cache.FindLink(links[0].ID)
+	c.Assert(err, gc.IsNil)
+	_, err = s.cache.FindLink(links[3].ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.cache.Stats(), gc.Equals, Stats{Hits: 1, Misses: 4, Evictions: 1})
+
+	_, err = s.cache.FindLink(links[0].ID)
+	c.Assert(err, gc.IsNil)
+	_, err = s.cache.FindLink(links[1].ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.cache.Stats(), gc.Equals, Stats{Hits: 2, Misses: 5, Evictions: 2})
+}
+
+// TestTTL verifies that cached links expire after the configured TTL.
+func (s *CacheTestSuite) TestTTL(c *gc.C) {
+	s.cache = New(s.g, Config{TTL: 50 * time.Millisecond})
+	link := &graph.Link{URL: "http://example.com/"}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+
+	for i := 0; i < 2; i++ {
+		_, err := s.cache.FindLink(link.ID)
+		c.Assert(err, gc.IsNil)
+	}
+	c.Assert(s.cache.Stats(), gc.Equals, Stats{Hits: 1, Misses: 1})
+
+	// Modifications that bypass the decorator become visible once the
+	// cached entry expires.
+	link.StatusCode, link.RetrievedAt = 301, time.Now()
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+	time.Sleep(60 * time.Millisecond)
+
+	got, err := s.cache.FindLink(link.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.StatusCode, gc.Equals, 301)
+	c.Assert(s.cache.Stats(), gc.Equals, Stats{Hits: 1, Misses: 2})
+}
@@ -0,0 +1,105 @@
+package graphcopy
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/internal/atomicfile"
+	"golang.org/x/xerrors"
+)
+
+// checkpoint tracks the partitions whose links and edges have been copied.
+// All methods are safe to call on a nil checkpoint which does not track any
+// progress.
+type checkpoint struct {
+	path  string
+	state checkpointState
+}
+
+// checkpointState is the on-disk representation of a checkpoint.
+type checkpointState struct {
+	NumPartitions int    `json:"num_partitions"`
+	LinksDone     []bool `json:"links_done"`
+	EdgesDone     []bool `json:"edges_done"`
+}
+
+// loadCheckpoint reads the checkpoint at path or returns an empty checkpoint
+// if the file does not exist. The checkpoint must have been created for the
+// same number of partitions. A nil checkpoint is returned if path is empty.
+func loadCheckpoint(path string, numPartitions int) (*checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	cp := &checkpoint{
+		path: path,
+		state: checkpointState{
+			NumPartitions: numPartitions,
+			LinksDone:     make([]bool, numPartitions),
+			EdgesDone:     make([]bool, numPartitions),
+		},
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cp, nil
+	} else if err != nil {
+		return nil, xerrors.Errorf("load checkpoint: %w", err)
+	}
+
+	var state checkpointState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, xerrors.Errorf("load checkpoint: %w", err)
+	}
+	if state.NumPartitions != numPartitions || len(state.LinksDone) != numPartitions || len(state.EdgesDone) != numPartitions {
+		return nil, xerrors.Errorf("load checkpoint: checkpoint was created for %d partitions, not %d", state.NumPartitions, numPartitions)
+	}
+	cp.state = state
+	return cp, nil
+}
+
+func (cp *checkpoint) linksDone(p int) bool { return cp != nil && cp.state.LinksDone[p] }
+
+func (cp *checkpoint) edgesDone(p int) bool { return cp != nil && cp.state.EdgesDone[p] }
+
+// markLinksDone records that the links of partition p have been copied.
+func (cp *checkpoint) markLinksDone(p int) error {
+	if cp == nil {
+		return nil
+	}
+	cp.state.LinksDone[p] = true
+	return cp.save()
+}
+
+// markEdgesDone records that the edges of partition p have been copied.
+func (cp *checkpoint) markEdgesDone(p int) error {
+	if cp == nil {
+		return nil
+	}
+	cp.state.EdgesDone[p] = true
+	return cp.save()
+}
+
+// save atomically replaces the checkpoint file with the current state.
+func (cp *checkpoint) save() error {
+	data, err := json.Marshal(cp.state)
+	if err != nil {
+		return xerrors.Errorf("save checkpoint: %w", err)
+	}
+
+	if err = atomicfile.WriteFile(cp.path, data); err != nil {
+		return xerrors.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the checkpoint file.
+func (cp *checkpoint) remove() error {
+	if cp == nil {
+		return nil
+	}
+	if err := os.Remove(cp.path); err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("remove checkpoint: %w", err)
+	}
+	return nil
+}
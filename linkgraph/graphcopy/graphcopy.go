@@ -0,0 +1,424 @@
+// Package graphcopy copies the links and edges of a link graph into another
+// link graph which may be backed by a different store.
+package graphcopy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// ErrCountMismatch is returned by Run if the number of links or edges in the
+// destination graph does not match the source graph once the copy completes.
+var ErrCountMismatch = xerrors.New("link or edge count mismatch between source and destination graph")
+
+// deterministicIDGraph is implemented by graphs that can report whether they
+// assign deterministic IDs to new links.
+type deterministicIDGraph interface {
+	DeterministicIDs() bool
+}
+
+// Config encapsulates the settings for a copy run.
+//
+// Unless both graphs assign deterministic link IDs, a run keeps the source
+// and destination ID of every copied link in memory so that edges can be
+// remapped (see Run). The memory required for a run therefore grows with
+// the number of links in the source graph, regardless of NumPartitions.
+type Config struct {
+	// The number of partitions to split the link ID space of the source
+	// graph into. Defaults to 1.
+	NumPartitions int
+
+	// The number of partitions that are copied in parallel. Defaults to 1.
+	Workers int
+
+	// The maximum number of links or edges that are upserted into the
+	// destination graph with a single call. Defaults to 500.
+	BatchSize int
+
+	// The path to a file for tracking the partitions that have already
+	// been copied. If the file exists, the run resumes from the recorded
+	// progress. The file is removed once the copy completes successfully.
+	// If not specified, no progress is recorded.
+	CheckpointPath string
+}
+
+// Stats summarizes the outcome of a copy run.
+type Stats struct {
+	// The number of links that were copied.
+	Links int
+
+	// The number of edges that were copied.
+	Edges int
+
+	// The number of link and edge partitions that were skipped because
+	// the checkpoint file reported them as already copied.
+	SkippedPartitions int
+}
+
+// Run copies all links and then all edges from src to dst. Each partition of
+// the source link ID space is copied independently and up to cfg.Workers
+// partitions are copied in parallel.
+//
+// As the destination graph assigns its own IDs to copied links, edges are
+// remapped to the destination link IDs before they are upserted. The ID of
+// each copied link is kept in memory for the duration of the run; when
+// resuming a run, the IDs of links in partitions that were already copied
+// are looked up by URL. If both graphs report via a DeterministicIDs method
+// that they assign the IDs returned by graph.LinkIDFromURL, copied links
+// keep their source ID and only links whose source ID was not derived from
+// their URL need to be tracked or looked up. Link crawl metadata and edge
+// attributes are retained; the update timestamp of copied edges is set by
+// the destination graph, though.
+//
+// Once all partitions have been copied, Run counts the links and edges in
+// both graphs and fails with ErrCountMismatch if they differ. For the counts
+// to match, dst should be empty when the copy starts, the URLs of the links
+// in src should already be in canonical form and src should not be written
+// to while the run is in progress.
+func Run(ctx context.Context, src, dst graph.Graph, cfg Config) (Stats, error) {
+	var stats Stats
+	if cfg.NumPartitions <= 0 {
+		cfg.NumPartitions = 1
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+
+	r, err := partition.NewFullRange(cfg.NumPartitions)
+	if err != nil {
+		return stats, xerrors.Errorf("copy: %w", err)
+	}
+
+	cp, err := loadCheckpoint(cfg.CheckpointPath, cfg.NumPartitions)
+	if err != nil {
+		return stats, xerrors.Errorf("copy: %w", err)
+	}
+
+	c := &copier{
+		src:   src,
+		dst:   dst,
+		cfg:   cfg,
+		r:     r,
+		cp:    cp,
+		idMap: make(map[uuid.UUID]uuid.UUID),
+		stats: &stats,
+
+		sameIDs: deterministicIDs(src) && deterministicIDs(dst),
+	}
+
+	if err = c.forEachPartition(ctx, c.copyLinks); err != nil {
+		return stats, xerrors.Errorf("copy: %w", err)
+	}
+	if err = c.forEachPartition(ctx, c.copyEdges); err != nil {
+		return stats, xerrors.Errorf("copy: %w", err)
+	}
+
+	if err = verifyCounts(ctx, src, dst); err != nil {
+		return stats, xerrors.Errorf("copy: %w", err)
+	}
+
+	if err = cp.remove(); err != nil {
+		return stats, xerrors.Errorf("copy: %w", err)
+	}
+	return stats, nil
+}
+
+// copier holds the state that is shared by the workers of a copy run.
+type copier struct {
+	src, dst graph.Graph
+	cfg      Config
+	r        partition.Range
+	cp       *checkpoint
+
+	// When set, both graphs assign deterministic IDs so copied links keep
+	// their source ID if it was derived from their URL.
+	sameIDs bool
+
+	// mu guards the fields below.
+	mu sync.Mutex
+	// idMap maps source link IDs to the IDs of the copied links. Links
+	// that kept their source ID are omitted.
+	idMap map[uuid.UUID]uuid.UUID
+	stats *Stats
+}
+
+// deterministicIDs returns true if g reports that it assigns deterministic
+// IDs to new links.
+func deterministicIDs(g graph.Graph) bool {
+	dg, ok := g.(deterministicIDGraph)
+	return ok && dg.DeterministicIDs()
+}
+
+// forEachPartition invokes copyFn for each partition using up to
+// cfg.Workers goroutines. If copyFn fails for any partition, the remaining
+// partitions are not processed and the first error is returned.
+func (c *copier) forEachPartition(ctx context.Context, copyFn func(context.Context, int) error) error {
+	ctx, cancelFn := context.WithCancel(ctx)
+	defer cancelFn()
+
+	partCh := make(chan int)
+	errCh := make(chan error, c.cfg.Workers)
+
+	var wg sync.WaitGroup
+	wg.Add(c.cfg.Workers)
+	for i := 0; i < c.cfg.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			for p := range partCh {
+				if err := copyFn(ctx, p); err != nil {
+					errCh <- err
+					cancelFn()
+					return
+				}
+			}
+		}()
+	}
+
+feed:
+	for p := 0; p < c.r.NumPartitions(); p++ {
+		select {
+		case partCh <- p:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(partCh)
+	wg.Wait()
+	close(errCh)
+
+	if err := <-errCh; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// copyLinks copies the links of partition p to the destination graph and
+// records the IDs assigned to them. If the checkpoint reports the partition
+// as already copied, the ID mapping is restored by looking up the links in
+// the destination graph instead. Links that keep their source ID are neither
+// recorded nor looked up.
+func (c *copier) copyLinks(ctx context.Context, p int) error {
+	from, to, err := c.r.PartitionExtents(p)
+	if err != nil {
+		return err
+	}
+
+	skip := c.cp.linksDone(p)
+	it, err := c.src.LinksContext(ctx, from, to, time.Now())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = it.Close() }()
+
+	var (
+		numCopied int
+		idMap     = make(map[uuid.UUID]uuid.UUID)
+		batch     = make([]*graph.Link, 0, c.cfg.BatchSize)
+		srcIDs    = make([]uuid.UUID, 0, c.cfg.BatchSize)
+		flushFn   = func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			if err := c.dst.UpsertLinksContext(ctx, batch); err != nil {
+				return err
+			}
+			for i, link := range batch {
+				if !c.sameIDs || link.ID != srcIDs[i] {
+					idMap[srcIDs[i]] = link.ID
+				}
+			}
+			numCopied += len(batch)
+			batch, srcIDs = batch[:0], srcIDs[:0]
+			return nil
+		}
+	)
+
+	for it.Next() {
+		link := it.Link()
+		if skip {
+			if c.sameIDs && link.ID == graph.LinkIDFromURL(link.URL) {
+				continue
+			}
+			dstLink, err := c.dst.FindLinkByURLContext(ctx, link.URL)
+			if err != nil {
+				return xerrors.Errorf("restore ID of link %q: %w", link.URL, err)
+			}
+			idMap[link.ID] = dstLink.ID
+			continue
+		}
+
+		srcIDs = append(srcIDs, link.ID)
+		copied := *link
+		copied.ID = uuid.Nil
+		batch = append(batch, &copied)
+		if len(batch) == cap(batch) {
+			if err = flushFn(); err != nil {
+				return err
+			}
+		}
+	}
+	if err = it.Error(); err != nil {
+		return err
+	}
+	if err = it.Close(); err != nil {
+		return err
+	}
+	if err = flushFn(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for srcID, dstID := range idMap {
+		c.idMap[srcID] = dstID
+	}
+	if skip {
+		c.stats.SkippedPartitions++
+		return nil
+	}
+	c.stats.Links += numCopied
+	return c.cp.markLinksDone(p)
+}
+
+// copyEdges copies the edges originating from the links of partition p to
+// the destination graph. It must only be invoked once the links of all
+// partitions have been copied.
+func (c *copier) copyEdges(ctx context.Context, p int) error {
+	if c.cp.edgesDone(p) {
+		c.mu.Lock()
+		c.stats.SkippedPartitions++
+		c.mu.Unlock()
+		return nil
+	}
+
+	from, to, err := c.r.PartitionExtents(p)
+	if err != nil {
+		return err
+	}
+
+	it, err := c.src.EdgesContext(ctx, from, to, time.Now())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = it.Close() }()
+
+	var (
+		count   int
+		batch   = make([]*graph.Edge, 0, c.cfg.BatchSize)
+		flushFn = func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			if err := c.dst.UpsertEdgesContext(ctx, batch); err != nil {
+				return err
+			}
+			count += len(batch)
+			batch = batch[:0]
+			return nil
+		}
+	)
+
+	for it.Next() {
+		edge := it.Edge()
+		srcID, srcFound := c.dstLinkID(edge.Src)
+		dstID, dstFound := c.dstLinkID(edge.Dst)
+		if !srcFound || !dstFound {
+			return xerrors.Errorf("edge %s: %w", edge.ID, graph.ErrUnknownEdgeLinks)
+		}
+
+		copied := *edge
+		copied.ID = uuid.Nil
+		copied.Src, copied.Dst = srcID, dstID
+		batch = append(batch, &copied)
+		if len(batch) == cap(batch) {
+			if err = flushFn(); err != nil {
+				return err
+			}
+		}
+	}
+	if err = it.Error(); err != nil {
+		return err
+	}
+	if err = it.Close(); err != nil {
+		return err
+	}
+	if err = flushFn(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Edges += count
+	return c.cp.markEdgesDone(p)
+}
+
+// dstLinkID returns the ID of the copy of the source link with the specified
+// ID and whether the link has been copied.
+func (c *copier) dstLinkID(srcID uuid.UUID) (uuid.UUID, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if dstID, found := c.idMap[srcID]; found {
+		return dstID, true
+	}
+	return srcID, c.sameIDs
+}
+
+// verifyCounts checks that src and dst contain the same number of links and
+// edges.
+func verifyCounts(ctx context.Context, src, dst graph.Graph) error {
+	srcLinks, srcEdges, err := count(ctx, src)
+	if err != nil {
+		return err
+	}
+	dstLinks, dstEdges, err := count(ctx, dst)
+	if err != nil {
+		return err
+	}
+
+	if srcLinks != dstLinks || srcEdges != dstEdges {
+		return xerrors.Errorf("source has %d links and %d edges, destination has %d links and %d edges: %w",
+			srcLinks, srcEdges, dstLinks, dstEdges, ErrCountMismatch)
+	}
+	return nil
+}
+
+// count returns the number of links and edges in g.
+func count(ctx context.Context, g graph.Graph) (links, edges int, err error) {
+	now := time.Now()
+	linkIt, err := g.LinksContext(ctx, partition.MinUUID, partition.MaxUUID, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	for linkIt.Next() {
+		links++
+	}
+	if err = linkIt.Error(); err != nil {
+		_ = linkIt.Close()
+		return 0, 0, err
+	}
+	if err = linkIt.Close(); err != nil {
+		return 0, 0, err
+	}
+
+	edgeIt, err := g.EdgesContext(ctx, partition.MinUUID, partition.MaxUUID, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	for edgeIt.Next() {
+		edges++
+	}
+	if err = edgeIt.Error(); err != nil {
+		_ = edgeIt.Close()
+		return 0, 0, err
+	}
+	return links, edges, edgeIt.Close()
+}
@@ -0,0 +1,204 @@
+package graphcopy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(CopyTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type CopyTestSuite struct {
+	src *memory.InMemoryGraph
+	dst *memory.InMemoryGraph
+}
+
+// SetUpTest populates the source graph with a small set of links and edges.
+func (s *CopyTestSuite) SetUpTest(c *gc.C) {
+	s.src = memory.NewInMemoryGraph()
+	s.dst = memory.NewInMemoryGraph()
+
+	links := make(map[string]*graph.Link)
+	for i, u := range []string{
+		"http://example.com/",
+		"http://example.com/about",
+		"http://example.com/contact",
+		"http://other.example/",
+		"http://other.example/blog",
+		"http://third.example/",
+	} {
+		link := &graph.Link{
+			URL:         u,
+			RetrievedAt: time.Now().Add(-time.Hour).Truncate(time.Second).UTC(),
+			StatusCode:  200 + i,
+			ContentType: "text/html",
+		}
+		c.Assert(s.src.UpsertLink(link), gc.IsNil)
+		links[u] = link
+	}
+
+	for _, e := range [][2]string{
+		{"http://example.com/", "http://example.com/about"},
+		{"http://example.com/", "http://other.example/"},
+		{"http://example.com/about", "http://example.com/contact"},
+		{"http://other.example/", "http://other.example/blog"},
+		{"http://other.example/blog", "http://example.com/"},
+		{"http://third.example/", "http://third.example/"},
+	} {
+		edge := &graph.Edge{Src: links[e[0]].ID, Dst: links[e[1]].ID, AnchorText: e[1], NoFollow: true, LinkCount: 2}
+		c.Assert(s.src.UpsertEdge(edge), gc.IsNil)
+	}
+}
+
+// TestCopy verifies that all links and edges are copied and that edges are
+// remapped to the IDs assigned by the destination graph.
+func (s *CopyTestSuite) TestCopy(c *gc.C) {
+	stats, err := Run(context.TODO(), s.src, s.dst, Config{NumPartitions: 4, Workers: 3, BatchSize: 2})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Links: 6, Edges: 6})
+
+	srcLinks, dstLinks := graphtest.AllLinks(c, s.src), graphtest.AllLinks(c, s.dst)
+	c.Assert(dstLinks, gc.HasLen, len(srcLinks))
+	for u, srcLink := range srcLinks {
+		dstLink := dstLinks[u]
+		c.Assert(dstLink, gc.NotNil, gc.Commentf("link %q", u))
+		c.Assert(dstLink.ID, gc.Not(gc.Equals), srcLink.ID)
+		c.Assert(dstLink.RetrievedAt, gc.Equals, srcLink.RetrievedAt)
+		c.Assert(dstLink.FirstSeenAt, gc.Equals, srcLink.FirstSeenAt)
+		c.Assert(dstLink.StatusCode, gc.Equals, srcLink.StatusCode)
+		c.Assert(dstLink.ContentType, gc.Equals, srcLink.ContentType)
+	}
+
+	c.Assert(graphtest.AllEdges(c, s.dst), gc.DeepEquals, graphtest.AllEdges(c, s.src))
+
+	it, err := s.dst.InboundEdges(dstLinks["http://example.com/about"].ID, time.Now())
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Edge().AnchorText, gc.Equals, "http://example.com/about")
+	c.Assert(it.Edge().NoFollow, gc.Equals, true)
+	c.Assert(it.Edge().LinkCount, gc.Equals, 2)
+	c.Assert(it.Close(), gc.IsNil)
+}
+
+// TestResume verifies that partitions recorded in the checkpoint file are
+// not copied again and that the checkpoint file is removed on success.
+func (s *CopyTestSuite) TestResume(c *gc.C) {
+	cpPath := filepath.Join(c.MkDir(), "copy.checkpoint")
+
+	// Copy the links of the first partition by hand and record it as done.
+	r, err := partition.NewFullRange(2)
+	c.Assert(err, gc.IsNil)
+	from, to, err := r.PartitionExtents(0)
+	c.Assert(err, gc.IsNil)
+
+	it, err := s.src.Links(from, to, time.Now())
+	c.Assert(err, gc.IsNil)
+	var copied int
+	for it.Next() {
+		link := *it.Link()
+		link.ID = uuid.Nil
+		c.Assert(s.dst.UpsertLink(&link), gc.IsNil)
+		copied++
+	}
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+
+	data, err := json.Marshal(checkpointState{
+		NumPartitions: 2,
+		LinksDone:     []bool{true, false},
+		EdgesDone:     []bool{false, false},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(os.WriteFile(cpPath, data, 0644), gc.IsNil)
+
+	stats, err := Run(context.TODO(), s.src, s.dst, Config{NumPartitions: 2, CheckpointPath: cpPath})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Links: 6 - copied, Edges: 6, SkippedPartitions: 1})
+	c.Assert(graphtest.AllEdges(c, s.dst), gc.DeepEquals, graphtest.AllEdges(c, s.src))
+
+	_, err = os.Stat(cpPath)
+	c.Assert(os.IsNotExist(err), gc.Equals, true)
+}
+
+// TestDeterministicIDs verifies that links keep their source ID if both
+// graphs assign deterministic IDs while links with a random source ID are
+// still remapped, including when resuming from a checkpoint.
+func (s *CopyTestSuite) TestDeterministicIDs(c *gc.C) {
+	// The links of the suite were created with random IDs.
+	legacyLinks := graphtest.AllLinks(c, s.src)
+	s.src.SetDeterministicIDs(true)
+	s.dst.SetDeterministicIDs(true)
+
+	link := &graph.Link{URL: "http://fourth.example/"}
+	c.Assert(s.src.UpsertLink(link), gc.IsNil)
+	c.Assert(link.ID, gc.Equals, graph.LinkIDFromURL(link.URL))
+	c.Assert(s.src.UpsertEdge(&graph.Edge{Src: legacyLinks["http://example.com/"].ID, Dst: link.ID}), gc.IsNil)
+	c.Assert(s.src.UpsertEdge(&graph.Edge{Src: link.ID, Dst: link.ID}), gc.IsNil)
+
+	// Copy the links by hand and record them as done so that the run has
+	// to restore the IDs of the legacy links.
+	for _, srcLink := range graphtest.AllLinks(c, s.src) {
+		copied := *srcLink
+		copied.ID = uuid.Nil
+		c.Assert(s.dst.UpsertLink(&copied), gc.IsNil)
+	}
+	cpPath := filepath.Join(c.MkDir(), "copy.checkpoint")
+	data, err := json.Marshal(checkpointState{
+		NumPartitions: 2,
+		LinksDone:     []bool{true, true},
+		EdgesDone:     []bool{false, false},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(os.WriteFile(cpPath, data, 0644), gc.IsNil)
+
+	stats, err := Run(context.TODO(), s.src, s.dst, Config{NumPartitions: 2, CheckpointPath: cpPath})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Edges: 8, SkippedPartitions: 2})
+
+	dstLinks := graphtest.AllLinks(c, s.dst)
+	c.Assert(dstLinks[link.URL].ID, gc.Equals, link.ID)
+	for u, legacyLink := range legacyLinks {
+		c.Assert(dstLinks[u].ID, gc.Not(gc.Equals), legacyLink.ID)
+	}
+	c.Assert(graphtest.AllEdges(c, s.dst), gc.DeepEquals, graphtest.AllEdges(c, s.src))
+}
+
+// TestCheckpointPartitionMismatch verifies that a checkpoint cannot be used
+// with a different number of partitions.
+func (s *CopyTestSuite) TestCheckpointPartitionMismatch(c *gc.C) {
+	cpPath := filepath.Join(c.MkDir(), "copy.checkpoint")
+	data, err := json.Marshal(checkpointState{
+		NumPartitions: 2,
+		LinksDone:     []bool{true, false},
+		EdgesDone:     []bool{false, false},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(os.WriteFile(cpPath, data, 0644), gc.IsNil)
+
+	_, err = Run(context.TODO(), s.src, s.dst, Config{NumPartitions: 3, CheckpointPath: cpPath})
+	c.Assert(err, gc.ErrorMatches, ".*checkpoint was created for 2 partitions, not 3")
+	c.Assert(graphtest.AllLinks(c, s.dst), gc.HasLen, 0)
+}
+
+// TestCountMismatch verifies that the copy fails verification if the
+// destination graph was not empty.
+func (s *CopyTestSuite) TestCountMismatch(c *gc.C) {
+	c.Assert(s.dst.UpsertLink(&graph.Link{URL: "http://unrelated.example/"}), gc.IsNil)
+
+	stats, err := Run(context.TODO(), s.src, s.dst, Config{NumPartitions: 2, Workers: 2})
+	c.Assert(xerrors.Is(err, ErrCountMismatch), gc.Equals, true, gc.Commentf("err: %v", err))
+	c.Assert(stats, gc.DeepEquals, Stats{Links: 6, Edges: 6})
+}
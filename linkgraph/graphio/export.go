@@ -0,0 +1,280 @@
+package graphio
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// encoder serializes links and edges in a particular format.
+type encoder interface {
+	writeHeader() error
+	writeLink(link *graph.Link) error
+	writeEdge(edge *graph.Edge, srcURL, dstURL string) error
+	writeFooter() error
+}
+
+// Export writes all links and edges of g to w using the specified format.
+// Links are exported before edges; edges whose endpoints were not part of
+// the exported links (e.g. because they were inserted while the export was
+// in progress) are skipped.
+//
+// The URLs of all exported links are kept in memory for the duration of
+// the export so that edges can refer to them.
+func Export(ctx context.Context, g graph.Graph, w io.Writer, format Format) (Stats, error) {
+	var stats Stats
+
+	bw := bufio.NewWriter(w)
+	var enc encoder
+	switch format {
+	case FormatGraphML:
+		enc = &graphmlEncoder{w: bw}
+	case FormatDOT:
+		enc = &dotEncoder{w: bw}
+	case FormatJSONL:
+		enc = &jsonlEncoder{enc: json.NewEncoder(bw)}
+	case FormatCSV:
+		enc = &csvEncoder{w: csv.NewWriter(bw)}
+	default:
+		return stats, xerrors.Errorf("export: %q: %w", format, ErrUnsupportedFormat)
+	}
+
+	if err := exportGraph(ctx, g, enc, &stats); err != nil {
+		return stats, xerrors.Errorf("export: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return stats, xerrors.Errorf("export: %w", err)
+	}
+	return stats, nil
+}
+
+func exportGraph(ctx context.Context, g graph.Graph, enc encoder, stats *Stats) error {
+	if err := enc.writeHeader(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	linkIt, err := g.LinksContext(ctx, partition.MinUUID, partition.MaxUUID, now)
+	if err != nil {
+		return err
+	}
+	urls := make(map[uuid.UUID]string)
+	for linkIt.Next() {
+		link := linkIt.Link()
+		if err = enc.writeLink(link); err != nil {
+			_ = linkIt.Close()
+			return err
+		}
+		urls[link.ID] = link.URL
+		stats.Links++
+	}
+	if err = linkIt.Error(); err != nil {
+		_ = linkIt.Close()
+		return err
+	}
+	if err = linkIt.Close(); err != nil {
+		return err
+	}
+
+	edgeIt, err := g.EdgesContext(ctx, partition.MinUUID, partition.MaxUUID, now)
+	if err != nil {
+		return err
+	}
+	for edgeIt.Next() {
+		edge := edgeIt.Edge()
+		srcURL, srcFound := urls[edge.Src]
+		dstURL, dstFound := urls[edge.Dst]
+		if !srcFound || !dstFound {
+			continue
+		}
+		if err = enc.writeEdge(edge, srcURL, dstURL); err != nil {
+			_ = edgeIt.Close()
+			return err
+		}
+		stats.Edges++
+	}
+	if err = edgeIt.Error(); err != nil {
+		_ = edgeIt.Close()
+		return err
+	}
+	if err = edgeIt.Close(); err != nil {
+		return err
+	}
+
+	return enc.writeFooter()
+}
+
+// graphmlEncoder writes links as nodes and edges as directed edges of a
+// GraphML document.
+type graphmlEncoder struct {
+	w *bufio.Writer
+}
+
+const graphmlHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="url" for="node" attr.name="url" attr.type="string"/>
+  <key id="retrieved_at" for="node" attr.name="retrieved_at" attr.type="string"/>
+  <key id="status_code" for="node" attr.name="status_code" attr.type="int"/>
+  <key id="content_type" for="node" attr.name="content_type" attr.type="string"/>
+  <key id="anchor_text" for="edge" attr.name="anchor_text" attr.type="string"/>
+  <key id="nofollow" for="edge" attr.name="nofollow" attr.type="boolean"/>
+  <key id="sponsored" for="edge" attr.name="sponsored" attr.type="boolean"/>
+  <key id="ugc" for="edge" attr.name="ugc" attr.type="boolean"/>
+  <key id="link_count" for="edge" attr.name="link_count" attr.type="int"/>
+  <graph id="linkgraph" edgedefault="directed">
+`
+
+func (e *graphmlEncoder) writeHeader() error {
+	_, err := e.w.WriteString(graphmlHeader)
+	return err
+}
+
+func (e *graphmlEncoder) writeLink(link *graph.Link) error {
+	fmt.Fprintf(e.w, "    <node id=\"%s\">\n", link.ID)
+	e.writeData("url", link.URL)
+	e.writeData("retrieved_at", link.RetrievedAt.UTC().Format(time.RFC3339))
+	e.writeData("status_code", strconv.Itoa(link.StatusCode))
+	e.writeData("content_type", link.ContentType)
+	_, err := e.w.WriteString("    </node>\n")
+	return err
+}
+
+func (e *graphmlEncoder) writeEdge(edge *graph.Edge, _, _ string) error {
+	fmt.Fprintf(e.w, "    <edge id=\"%s\" source=\"%s\" target=\"%s\">\n", edge.ID, edge.Src, edge.Dst)
+	e.writeData("anchor_text", edge.AnchorText)
+	e.writeData("nofollow", strconv.FormatBool(edge.NoFollow))
+	e.writeData("sponsored", strconv.FormatBool(edge.Sponsored))
+	e.writeData("ugc", strconv.FormatBool(edge.UGC))
+	e.writeData("link_count", strconv.Itoa(edge.LinkCount))
+	_, err := e.w.WriteString("    </edge>\n")
+	return err
+}
+
+// writeData writes a data element for the specified key. Write errors are
+// reported by the bufio.Writer once the enclosing element is written.
+func (e *graphmlEncoder) writeData(key, value string) {
+	fmt.Fprintf(e.w, "      <data key=\"%s\">", key)
+	_ = xml.EscapeText(e.w, []byte(value))
+	_, _ = e.w.WriteString("</data>\n")
+}
+
+func (e *graphmlEncoder) writeFooter() error {
+	_, err := e.w.WriteString("  </graph>\n</graphml>\n")
+	return err
+}
+
+// dotEncoder writes links as nodes labelled with their URL and edges as
+// directed edges labelled with their anchor text.
+type dotEncoder struct {
+	w *bufio.Writer
+}
+
+// dotEscaper escapes the characters that are not allowed to appear
+// unescaped in a quoted DOT identifier.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
+func (e *dotEncoder) writeHeader() error {
+	_, err := e.w.WriteString("digraph linkgraph {\n")
+	return err
+}
+
+func (e *dotEncoder) writeLink(link *graph.Link) error {
+	_, err := fmt.Fprintf(e.w, "  \"%s\" [label=\"%s\"];\n", link.ID, dotEscaper.Replace(link.URL))
+	return err
+}
+
+func (e *dotEncoder) writeEdge(edge *graph.Edge, _, _ string) error {
+	attrs := fmt.Sprintf("label=\"%s\"", dotEscaper.Replace(edge.AnchorText))
+	if edge.NoFollow {
+		attrs += ", style=dashed"
+	}
+	_, err := fmt.Fprintf(e.w, "  \"%s\" -> \"%s\" [%s];\n", edge.Src, edge.Dst, attrs)
+	return err
+}
+
+func (e *dotEncoder) writeFooter() error {
+	_, err := e.w.WriteString("}\n")
+	return err
+}
+
+// jsonlEncoder writes one JSON object per link or edge.
+type jsonlEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *jsonlEncoder) writeHeader() error { return nil }
+
+func (e *jsonlEncoder) writeLink(link *graph.Link) error {
+	return e.enc.Encode(linkRecord{
+		Type:        recordTypeLink,
+		ID:          link.ID,
+		URL:         link.URL,
+		RetrievedAt: link.RetrievedAt.UTC(),
+		FirstSeenAt: link.FirstSeenAt.UTC(),
+		StatusCode:  link.StatusCode,
+		ContentType: link.ContentType,
+		ContentHash: link.ContentHash,
+		FetchErrors: link.FetchErrors,
+		LastError:   link.LastError,
+	})
+}
+
+func (e *jsonlEncoder) writeEdge(edge *graph.Edge, _, _ string) error {
+	return e.enc.Encode(edgeRecord{
+		Type:       recordTypeEdge,
+		ID:         edge.ID,
+		Src:        edge.Src,
+		Dst:        edge.Dst,
+		UpdatedAt:  edge.UpdatedAt.UTC(),
+		AnchorText: edge.AnchorText,
+		NoFollow:   edge.NoFollow,
+		Sponsored:  edge.Sponsored,
+		UGC:        edge.UGC,
+		LinkCount:  edge.LinkCount,
+	})
+}
+
+func (e *jsonlEncoder) writeFooter() error { return nil }
+
+// csvEncoder writes one row per edge; links are only referenced by the
+// edges.
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) writeHeader() error {
+	return e.w.Write(csvHeader)
+}
+
+func (e *csvEncoder) writeLink(*graph.Link) error { return nil }
+
+func (e *csvEncoder) writeEdge(edge *graph.Edge, srcURL, dstURL string) error {
+	return e.w.Write([]string{
+		srcURL,
+		dstURL,
+		edge.Src.String(),
+		edge.Dst.String(),
+		edge.AnchorText,
+		strconv.FormatBool(edge.NoFollow),
+		strconv.FormatBool(edge.Sponsored),
+		strconv.FormatBool(edge.UGC),
+		strconv.Itoa(edge.LinkCount),
+	})
+}
+
+func (e *csvEncoder) writeFooter() error {
+	e.w.Flush()
+	return e.w.Error()
+}
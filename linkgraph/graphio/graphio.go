@@ -0,0 +1,101 @@
+// Package graphio exports link graphs to and imports them from formats that
+// can be processed by external graph analysis tools.
+//
+// The supported export formats are GraphML, Graphviz DOT, JSON Lines and
+// edge-list CSV. JSON Lines and CSV exports can be imported back into any
+// graph.Graph.
+package graphio
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// Format identifies a serialization format for link graphs.
+type Format string
+
+// The supported formats.
+const (
+	// GraphML (http://graphml.graphdrawing.org). Export only.
+	FormatGraphML Format = "graphml"
+
+	// Graphviz DOT. Export only.
+	FormatDOT Format = "dot"
+
+	// JSON Lines with one object per link or edge. Links are always
+	// written before the edges that refer to them.
+	FormatJSONL Format = "jsonl"
+
+	// An edge list with a header row where each row describes an edge by
+	// the URLs of its endpoints. Links without any edges are not included
+	// in the export.
+	FormatCSV Format = "csv"
+)
+
+// ErrUnsupportedFormat is returned for unknown formats and when attempting
+// to import a format that only supports exporting.
+var ErrUnsupportedFormat = xerrors.New("unsupported format")
+
+// ParseFormat converts a format name into a Format.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatGraphML, FormatDOT, FormatJSONL, FormatCSV:
+		return f, nil
+	default:
+		return "", xerrors.Errorf("%q: %w", name, ErrUnsupportedFormat)
+	}
+}
+
+// Stats summarizes the outcome of an export or import.
+type Stats struct {
+	// The number of links that were exported or imported. CSV exports
+	// only include links as edge endpoints but still count all links.
+	Links int
+
+	// The number of edges that were exported or imported.
+	Edges int
+}
+
+// linkRecord is the JSON Lines representation of a link.
+type linkRecord struct {
+	Type        string    `json:"type"`
+	ID          uuid.UUID `json:"id"`
+	URL         string    `json:"url"`
+	RetrievedAt time.Time `json:"retrieved_at"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	ContentHash string    `json:"content_hash,omitempty"`
+	FetchErrors int       `json:"fetch_errors,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// edgeRecord is the JSON Lines representation of an edge.
+type edgeRecord struct {
+	Type       string    `json:"type"`
+	ID         uuid.UUID `json:"id"`
+	Src        uuid.UUID `json:"src"`
+	Dst        uuid.UUID `json:"dst"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	AnchorText string    `json:"anchor_text,omitempty"`
+	NoFollow   bool      `json:"nofollow,omitempty"`
+	Sponsored  bool      `json:"sponsored,omitempty"`
+	UGC        bool      `json:"ugc,omitempty"`
+	LinkCount  int       `json:"link_count,omitempty"`
+}
+
+// The values of the type field of JSON Lines records.
+const (
+	recordTypeLink = "link"
+	recordTypeEdge = "edge"
+)
+
+// csvHeader lists the columns of edge-list CSV exports. Only the URL columns
+// are required when importing.
+var csvHeader = []string{
+	"source_url", "target_url", "source_id", "target_id",
+	"anchor_text", "nofollow", "sponsored", "ugc", "link_count",
+}
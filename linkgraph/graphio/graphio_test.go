@@ -0,0 +1,243 @@
+package graphio
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(GraphIOTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type GraphIOTestSuite struct {
+	g *memory.InMemoryGraph
+
+	// Links populated by SetUpTest, keyed by their URL.
+	links map[string]*graph.Link
+}
+
+// SetUpTest populates a graph with a few links, one of which has no edges.
+func (s *GraphIOTestSuite) SetUpTest(c *gc.C) {
+	s.g = memory.NewInMemoryGraph()
+
+	s.links = make(map[string]*graph.Link)
+	for _, u := range []string{
+		"https://example.com/",
+		"https://example.com/a?b=\"c\"&d=<e>",
+		"https://other.example/",
+		"https://isolated.example/",
+	} {
+		link := &graph.Link{
+			URL:         u,
+			RetrievedAt: time.Now().Add(-time.Hour).Truncate(time.Second).UTC(),
+			StatusCode:  200,
+			ContentType: "text/html",
+			FetchErrors: 1,
+		}
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+		s.links[u] = link
+	}
+
+	for _, edge := range []*graph.Edge{
+		{Src: s.links["https://example.com/"].ID, Dst: s.links["https://example.com/a?b=\"c\"&d=<e>"].ID, AnchorText: "say \"hi\"\n<b>", NoFollow: true, LinkCount: 2},
+		{Src: s.links["https://example.com/a?b=\"c\"&d=<e>"].ID, Dst: s.links["https://other.example/"].ID, UGC: true},
+		{Src: s.links["https://other.example/"].ID, Dst: s.links["https://other.example/"].ID, Sponsored: true},
+	} {
+		c.Assert(s.g.UpsertEdge(edge), gc.IsNil)
+	}
+}
+
+// TestJSONLRoundTrip verifies that a JSON Lines export can be imported into
+// another graph without losing any information.
+func (s *GraphIOTestSuite) TestJSONLRoundTrip(c *gc.C) {
+	var buf bytes.Buffer
+	stats, err := Export(context.TODO(), s.g, &buf, FormatJSONL)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Links: 4, Edges: 3})
+
+	imported := memory.NewInMemoryGraph()
+	stats, err = Import(context.TODO(), imported, &buf, FormatJSONL)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Links: 4, Edges: 3})
+
+	links := graphtest.AllLinks(c, imported)
+	c.Assert(links, gc.HasLen, 4)
+	for u, link := range links {
+		exp := s.links[u]
+		c.Assert(exp, gc.NotNil, gc.Commentf("unexpected link %q", u))
+		c.Assert(link.RetrievedAt, gc.Equals, exp.RetrievedAt)
+		c.Assert(link.FirstSeenAt, gc.Equals, exp.FirstSeenAt)
+		c.Assert(link.StatusCode, gc.Equals, exp.StatusCode)
+		c.Assert(link.ContentType, gc.Equals, exp.ContentType)
+		c.Assert(link.FetchErrors, gc.Equals, exp.FetchErrors)
+	}
+
+	c.Assert(graphtest.AllEdgesWithAttributes(c, imported), gc.DeepEquals, graphtest.AllEdgesWithAttributes(c, s.g))
+}
+
+// TestCSVRoundTrip verifies that an edge-list CSV export can be imported
+// into another graph.
+func (s *GraphIOTestSuite) TestCSVRoundTrip(c *gc.C) {
+	var buf bytes.Buffer
+	stats, err := Export(context.TODO(), s.g, &buf, FormatCSV)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Links: 4, Edges: 3})
+	c.Assert(strings.HasPrefix(buf.String(), strings.Join(csvHeader, ",")+"\n"), gc.Equals, true)
+
+	imported := memory.NewInMemoryGraph()
+	stats, err = Import(context.TODO(), imported, &buf, FormatCSV)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Links: 3, Edges: 3})
+
+	// Links without edges are not part of edge-list exports.
+	links := graphtest.AllLinks(c, imported)
+	c.Assert(links, gc.HasLen, 3)
+	c.Assert(links["https://isolated.example/"], gc.IsNil)
+
+	c.Assert(graphtest.AllEdgesWithAttributes(c, imported), gc.DeepEquals, graphtest.AllEdgesWithAttributes(c, s.g))
+}
+
+// TestImportMinimalCSV verifies that CSV files produced by other tools only
+// need to provide the URL columns.
+func (s *GraphIOTestSuite) TestImportMinimalCSV(c *gc.C) {
+	input := "target_url,source_url\nhttps://b.example/,https://a.example/\nhttps://c.example/,https://a.example/\n"
+
+	imported := memory.NewInMemoryGraph()
+	stats, err := Import(context.TODO(), imported, strings.NewReader(input), FormatCSV)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Links: 3, Edges: 2})
+	c.Assert(graphtest.AllEdgesWithAttributes(c, imported), gc.DeepEquals, []string{
+		"https://a.example/ -> https://b.example/ (, false, false, false, 1)",
+		"https://a.example/ -> https://c.example/ (, false, false, false, 1)",
+	})
+
+	_, err = Import(context.TODO(), imported, strings.NewReader("source_url\nhttps://a.example/\n"), FormatCSV)
+	c.Assert(xerrors.Is(err, ErrMalformedInput), gc.Equals, true, gc.Commentf("%v", err))
+}
+
+// TestImportCSVInvalidURLs verifies that CSV rows with an empty or
+// unparseable URL are rejected before any of their links are created.
+func (s *GraphIOTestSuite) TestImportCSVInvalidURLs(c *gc.C) {
+	specs := []struct {
+		row     string
+		errLine string
+	}{
+		{row: ",https://a.example/", errLine: "line 3: target_url"},
+		{row: "https://b.example/,", errLine: "line 3: source_url"},
+		{row: "https://b.example/,a.example", errLine: "line 3: source_url"},
+		{row: "http://%zz/,https://a.example/", errLine: "line 3: target_url"},
+	}
+
+	for _, spec := range specs {
+		input := "target_url,source_url\nhttps://c.example/,https://a.example/\n" + spec.row + "\n"
+		imported := memory.NewInMemoryGraph()
+		stats, err := Import(context.TODO(), imported, strings.NewReader(input), FormatCSV)
+		c.Assert(xerrors.Is(err, ErrMalformedInput), gc.Equals, true, gc.Commentf("row %q: %v", spec.row, err))
+		c.Assert(err, gc.ErrorMatches, "import: "+spec.errLine+".*", gc.Commentf("row %q", spec.row))
+		c.Assert(stats, gc.DeepEquals, Stats{Links: 2, Edges: 1}, gc.Commentf("row %q", spec.row))
+	}
+}
+
+// TestImportJSONLUnknownLinks verifies that edges must refer to links that
+// appear earlier in the input.
+func (s *GraphIOTestSuite) TestImportJSONLUnknownLinks(c *gc.C) {
+	input := `{"type":"link","id":"` + uuid.New().String() + `","url":"https://a.example/"}
+{"type":"edge","id":"` + uuid.New().String() + `","src":"` + uuid.New().String() + `","dst":"` + uuid.New().String() + `"}
+`
+	stats, err := Import(context.TODO(), memory.NewInMemoryGraph(), strings.NewReader(input), FormatJSONL)
+	c.Assert(xerrors.Is(err, graph.ErrUnknownEdgeLinks), gc.Equals, true, gc.Commentf("%v", err))
+	c.Assert(stats, gc.DeepEquals, Stats{Links: 1})
+
+	_, err = Import(context.TODO(), memory.NewInMemoryGraph(), strings.NewReader(`{"type":"vertex"}`), FormatJSONL)
+	c.Assert(xerrors.Is(err, ErrMalformedInput), gc.Equals, true, gc.Commentf("%v", err))
+}
+
+// TestExportGraphML verifies that GraphML exports are well-formed and
+// contain all links and edges.
+func (s *GraphIOTestSuite) TestExportGraphML(c *gc.C) {
+	var buf bytes.Buffer
+	_, err := Export(context.TODO(), s.g, &buf, FormatGraphML)
+	c.Assert(err, gc.IsNil)
+
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	var doc struct {
+		Graph struct {
+			EdgeDefault string `xml:"edgedefault,attr"`
+			Nodes       []struct {
+				ID   string `xml:"id,attr"`
+				Data []data `xml:"data"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+				Data   []data `xml:"data"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	c.Assert(xml.Unmarshal(buf.Bytes(), &doc), gc.IsNil)
+	c.Assert(doc.Graph.EdgeDefault, gc.Equals, "directed")
+	c.Assert(doc.Graph.Nodes, gc.HasLen, 4)
+	c.Assert(doc.Graph.Edges, gc.HasLen, 3)
+
+	for _, node := range doc.Graph.Nodes {
+		c.Assert(node.Data[0].Key, gc.Equals, "url")
+		c.Assert(s.links[node.Data[0].Value], gc.NotNil, gc.Commentf("unexpected URL %q", node.Data[0].Value))
+		c.Assert(node.ID, gc.Equals, s.links[node.Data[0].Value].ID.String())
+	}
+
+	var anchors []string
+	for _, edge := range doc.Graph.Edges {
+		anchors = append(anchors, edge.Data[0].Value)
+	}
+	sort.Strings(anchors)
+	c.Assert(anchors, gc.DeepEquals, []string{"", "", "say \"hi\"\n<b>"})
+}
+
+// TestExportDOT verifies the structure of DOT exports.
+func (s *GraphIOTestSuite) TestExportDOT(c *gc.C) {
+	var buf bytes.Buffer
+	stats, err := Export(context.TODO(), s.g, &buf, FormatDOT)
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Links: 4, Edges: 3})
+
+	out := buf.String()
+	c.Assert(strings.HasPrefix(out, "digraph linkgraph {\n"), gc.Equals, true)
+	c.Assert(strings.HasSuffix(out, "}\n"), gc.Equals, true)
+
+	root := s.links["https://example.com/"].ID
+	quoted := s.links["https://example.com/a?b=\"c\"&d=<e>"].ID
+	c.Assert(strings.Contains(out, `  "`+quoted.String()+`" [label="https://example.com/a?b=\"c\"&d=<e>"];`), gc.Equals, true, gc.Commentf(out))
+	c.Assert(strings.Contains(out, `  "`+root.String()+`" -> "`+quoted.String()+`" [label="say \"hi\"\n<b>", style=dashed];`), gc.Equals, true, gc.Commentf(out))
+}
+
+// TestUnsupportedFormat verifies that unknown formats and imports of
+// export-only formats are rejected.
+func (s *GraphIOTestSuite) TestUnsupportedFormat(c *gc.C) {
+	f, err := ParseFormat("JSONL")
+	c.Assert(err, gc.IsNil)
+	c.Assert(f, gc.Equals, FormatJSONL)
+
+	_, err = ParseFormat("gexf")
+	c.Assert(xerrors.Is(err, ErrUnsupportedFormat), gc.Equals, true)
+
+	_, err = Export(context.TODO(), s.g, new(bytes.Buffer), Format("gexf"))
+	c.Assert(xerrors.Is(err, ErrUnsupportedFormat), gc.Equals, true)
+
+	_, err = Import(context.TODO(), s.g, new(bytes.Buffer), FormatGraphML)
+	c.Assert(xerrors.Is(err, ErrUnsupportedFormat), gc.Equals, true)
+}
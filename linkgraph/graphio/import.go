@@ -0,0 +1,221 @@
+package graphio
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// ErrMalformedInput is returned by Import if its input cannot be decoded.
+var ErrMalformedInput = xerrors.New("malformed input")
+
+// Import reads links and edges in the specified format from r and upserts
+// them into g. Only the JSON Lines and CSV formats can be imported.
+//
+// The target graph assigns new IDs to imported links; JSON Lines edges are
+// mapped from the exported link IDs to the newly assigned ones, which
+// requires links to appear before any edges that refer to them. Edges that
+// refer to links which are not part of the input fail the import with
+// graph.ErrUnknownEdgeLinks. Edge-list CSV input creates a link for each
+// distinct URL that appears in it.
+func Import(ctx context.Context, g graph.Graph, r io.Reader, format Format) (Stats, error) {
+	var (
+		stats Stats
+		err   error
+	)
+	switch format {
+	case FormatJSONL:
+		err = importJSONL(ctx, g, r, &stats)
+	case FormatCSV:
+		err = importCSV(ctx, g, r, &stats)
+	default:
+		err = xerrors.Errorf("%q: %w", format, ErrUnsupportedFormat)
+	}
+	if err != nil {
+		return stats, xerrors.Errorf("import: %w", err)
+	}
+	return stats, nil
+}
+
+func importJSONL(ctx context.Context, g graph.Graph, r io.Reader, stats *Stats) error {
+	var (
+		dec   = json.NewDecoder(r)
+		idMap = make(map[uuid.UUID]uuid.UUID)
+	)
+	for recNum := 1; ; recNum++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return xerrors.Errorf("record %d: %v: %w", recNum, err, ErrMalformedInput)
+		}
+
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return xerrors.Errorf("record %d: %v: %w", recNum, err, ErrMalformedInput)
+		}
+
+		switch header.Type {
+		case recordTypeLink:
+			var rec linkRecord
+			if err := json.Unmarshal(raw, &rec); err != nil {
+				return xerrors.Errorf("record %d: %v: %w", recNum, err, ErrMalformedInput)
+			}
+			link := &graph.Link{
+				URL:         rec.URL,
+				RetrievedAt: rec.RetrievedAt,
+				FirstSeenAt: rec.FirstSeenAt,
+				StatusCode:  rec.StatusCode,
+				ContentType: rec.ContentType,
+				ContentHash: rec.ContentHash,
+				FetchErrors: rec.FetchErrors,
+				LastError:   rec.LastError,
+			}
+			if err := g.UpsertLinkContext(ctx, link); err != nil {
+				return xerrors.Errorf("record %d: %w", recNum, err)
+			}
+			idMap[rec.ID] = link.ID
+			stats.Links++
+		case recordTypeEdge:
+			var rec edgeRecord
+			if err := json.Unmarshal(raw, &rec); err != nil {
+				return xerrors.Errorf("record %d: %v: %w", recNum, err, ErrMalformedInput)
+			}
+			src, srcFound := idMap[rec.Src]
+			dst, dstFound := idMap[rec.Dst]
+			if !srcFound || !dstFound {
+				return xerrors.Errorf("record %d: %w", recNum, graph.ErrUnknownEdgeLinks)
+			}
+			edge := &graph.Edge{
+				Src:        src,
+				Dst:        dst,
+				AnchorText: rec.AnchorText,
+				NoFollow:   rec.NoFollow,
+				Sponsored:  rec.Sponsored,
+				UGC:        rec.UGC,
+				LinkCount:  rec.LinkCount,
+			}
+			if err := g.UpsertEdgeContext(ctx, edge); err != nil {
+				return xerrors.Errorf("record %d: %w", recNum, err)
+			}
+			stats.Edges++
+		default:
+			return xerrors.Errorf("record %d: unknown record type %q: %w", recNum, header.Type, ErrMalformedInput)
+		}
+	}
+}
+
+func importCSV(ctx context.Context, g graph.Graph, r io.Reader, stats *Stats) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return xerrors.Errorf("%v: %w", err, ErrMalformedInput)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, required := range csvHeader[:2] {
+		if _, found := columns[required]; !found {
+			return xerrors.Errorf("missing %q column: %w", required, ErrMalformedInput)
+		}
+	}
+
+	linkIDs := make(map[string]uuid.UUID)
+	linkID := func(url string) (uuid.UUID, error) {
+		if id, found := linkIDs[url]; found {
+			return id, nil
+		}
+		link := &graph.Link{URL: url}
+		if err := g.UpsertLinkContext(ctx, link); err != nil {
+			return uuid.Nil, err
+		}
+		linkIDs[url] = link.ID
+		stats.Links++
+		return link.ID, nil
+	}
+
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return xerrors.Errorf("%v: %w", err, ErrMalformedInput)
+		}
+		line, _ := cr.FieldPos(0)
+
+		field := func(name string) string {
+			if i, found := columns[name]; found && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+
+		edge := &graph.Edge{AnchorText: field("anchor_text")}
+		for _, flag := range []struct {
+			name string
+			dst  *bool
+		}{
+			{"nofollow", &edge.NoFollow},
+			{"sponsored", &edge.Sponsored},
+			{"ugc", &edge.UGC},
+		} {
+			if v := field(flag.name); v != "" {
+				if *flag.dst, err = strconv.ParseBool(v); err != nil {
+					return xerrors.Errorf("line %d: %s: %v: %w", line, flag.name, err, ErrMalformedInput)
+				}
+			}
+		}
+		if v := field("link_count"); v != "" {
+			if edge.LinkCount, err = strconv.Atoi(v); err != nil {
+				return xerrors.Errorf("line %d: link_count: %v: %w", line, err, ErrMalformedInput)
+			}
+		}
+
+		srcURL, dstURL := field("source_url"), field("target_url")
+		for _, col := range []struct{ name, url string }{{"source_url", srcURL}, {"target_url", dstURL}} {
+			if err = checkURL(col.url); err != nil {
+				return xerrors.Errorf("line %d: %s: %v: %w", line, col.name, err, ErrMalformedInput)
+			}
+		}
+
+		if edge.Src, err = linkID(srcURL); err != nil {
+			return xerrors.Errorf("line %d: %w", line, err)
+		}
+		if edge.Dst, err = linkID(dstURL); err != nil {
+			return xerrors.Errorf("line %d: %w", line, err)
+		}
+		if err = g.UpsertEdgeContext(ctx, edge); err != nil {
+			return xerrors.Errorf("line %d: %w", line, err)
+		}
+		stats.Edges++
+	}
+}
+
+// checkURL returns an error if rawURL is not an absolute URL.
+func checkURL(rawURL string) error {
+	if rawURL == "" {
+		return xerrors.New("empty URL")
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	} else if !u.IsAbs() || u.Host == "" {
+		return xerrors.Errorf("%q is not an absolute URL", rawURL)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+// Package graphmetrics provides a graph.Graph decorator that records
+// latency, error and iterator metrics for each graph method.
+package graphmetrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/metrics"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// Compile-time check for ensuring InstrumentedGraph implements graph.Graph.
+var _ graph.Graph = (*InstrumentedGraph)(nil)
+
+// Namespace is the prefix of the names of the metrics recorded by
+// InstrumentedGraph.
+const Namespace = "linkgraph"
+
+// InstrumentedGraph is a graph.Graph decorator that records the metrics
+// described by metrics.RequestMetrics for each call to the wrapped graph.
+// Calls to a method and its Context variant are reported under the name of
+// the method without the Context suffix. Optional interfaces implemented by
+// the wrapped graph, such as graph.Watcher, are not forwarded.
+type InstrumentedGraph struct {
+	g graph.Graph
+	m *metrics.RequestMetrics
+}
+
+// New returns an InstrumentedGraph that wraps g and registers its metrics
+// with r.
+func New(g graph.Graph, r *metrics.Registry) *InstrumentedGraph {
+	return &InstrumentedGraph{
+		g: g,
+		m: metrics.NewRequestMetrics(r, Namespace, func(err error) bool { return xerrors.Is(err, graph.ErrNotFound) }),
+	}
+}
+
+// UpsertLink implements graph.Graph.
+func (ig *InstrumentedGraph) UpsertLink(link *graph.Link) error {
+	return ig.UpsertLinkContext(context.Background(), link)
+}
+
+// UpsertLinkContext implements graph.Graph.
+func (ig *InstrumentedGraph) UpsertLinkContext(ctx context.Context, link *graph.Link) error {
+	start := time.Now()
+	err := ig.g.UpsertLinkContext(ctx, link)
+	ig.m.Observe("UpsertLink", start, err)
+	return err
+}
+
+// UpsertLinks implements graph.Graph.
+func (ig *InstrumentedGraph) UpsertLinks(links []*graph.Link) error {
+	return ig.UpsertLinksContext(context.Background(), links)
+}
+
+// UpsertLinksContext implements graph.Graph.
+func (ig *InstrumentedGraph) UpsertLinksContext(ctx context.Context, links []*graph.Link) error {
+	start := time.Now()
+	err := ig.g.UpsertLinksContext(ctx, links)
+	ig.m.Observe("UpsertLinks", start, err)
+	return err
+}
+
+// FindLink implements graph.Graph.
+func (ig *InstrumentedGraph) FindLink(id uuid.UUID) (*graph.Link, error) {
+	return ig.FindLinkContext(context.Background(), id)
+}
+
+// FindLinkContext implements graph.Graph.
+func (ig *InstrumentedGraph) FindLinkContext(ctx context.Context, id uuid.UUID) (*graph.Link, error) {
+	start := time.Now()
+	link, err := ig.g.FindLinkContext(ctx, id)
+	ig.m.Observe("FindLink", start, err)
+	return link, err
+}
+
+// FindLinkByURL implements graph.Graph.
+func (ig *InstrumentedGraph) FindLinkByURL(url string) (*graph.Link, error) {
+	return ig.FindLinkByURLContext(context.Background(), url)
+}
+
+// FindLinkByURLContext implements graph.Graph.
+func (ig *InstrumentedGraph) FindLinkByURLContext(ctx context.Context, url string) (*graph.Link, error) {
+	start := time.Now()
+	link, err := ig.g.FindLinkByURLContext(ctx, url)
+	ig.m.Observe("FindLinkByURL", start, err)
+	return link, err
+}
+
+// LinksByHost implements graph.Graph.
+func (ig *InstrumentedGraph) LinksByHost(host string) (graph.LinkIterator, error) {
+	return ig.LinksByHostContext(context.Background(), host)
+}
+
+// LinksByHostContext implements graph.Graph.
+func (ig *InstrumentedGraph) LinksByHostContext(ctx context.Context, host string) (graph.LinkIterator, error) {
+	start := time.Now()
+	it, err := ig.g.LinksByHostContext(ctx, host)
+	ig.m.Observe("LinksByHost", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &linkIterator{LinkIterator: it, iteratorMetrics: ig.iteratorMetrics("LinksByHost")}, nil
+}
+
+// RemoveLink implements graph.Graph.
+func (ig *InstrumentedGraph) RemoveLink(id uuid.UUID) error {
+	return ig.RemoveLinkContext(context.Background(), id)
+}
+
+// RemoveLinkContext implements graph.Graph.
+func (ig *InstrumentedGraph) RemoveLinkContext(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := ig.g.RemoveLinkContext(ctx, id)
+	ig.m.Observe("RemoveLink", start, err)
+	return err
+}
+
+// Links implements graph.Graph.
+func (ig *InstrumentedGraph) Links(fromID, toID uuid.UUID, retrievedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	return ig.LinksContext(context.Background(), fromID, toID, retrievedBefore, filters...)
+}
+
+// LinksContext implements graph.Graph.
+func (ig *InstrumentedGraph) LinksContext(ctx context.Context, fromID, toID uuid.UUID, retrievedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	start := time.Now()
+	it, err := ig.g.LinksContext(ctx, fromID, toID, retrievedBefore, filters...)
+	ig.m.Observe("Links", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &linkIterator{LinkIterator: it, iteratorMetrics: ig.iteratorMetrics("Links")}, nil
+}
+
+// UpsertEdge implements graph.Graph.
+func (ig *InstrumentedGraph) UpsertEdge(edge *graph.Edge) error {
+	return ig.UpsertEdgeContext(context.Background(), edge)
+}
+
+// UpsertEdgeContext implements graph.Graph.
+func (ig *InstrumentedGraph) UpsertEdgeContext(ctx context.Context, edge *graph.Edge) error {
+	start := time.Now()
+	err := ig.g.UpsertEdgeContext(ctx, edge)
+	ig.m.Observe("UpsertEdge", start, err)
+	return err
+}
+
+// UpsertEdges implements graph.Graph.
+func (ig *InstrumentedGraph) UpsertEdges(edges []*graph.Edge) error {
+	return ig.UpsertEdgesContext(context.Background(), edges)
+}
+
+// UpsertEdgesContext implements graph.Graph.
+func (ig *InstrumentedGraph) UpsertEdgesContext(ctx context.Context, edges []*graph.Edge) error {
+	start := time.Now()
+	err := ig.g.UpsertEdgesContext(ctx, edges)
+	ig.m.Observe("UpsertEdges", start, err)
+	return err
+}
+
+// Edges implements graph.Graph.
+func (ig *InstrumentedGraph) Edges(fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	return ig.EdgesContext(context.Background(), fromID, toID, updatedBefore)
+}
+
+// EdgesContext implements graph.Graph.
+func (ig *InstrumentedGraph) EdgesContext(ctx context.Context, fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	start := time.Now()
+	it, err := ig.g.EdgesContext(ctx, fromID, toID, updatedBefore)
+	ig.m.Observe("Edges", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &edgeIterator{EdgeIterator: it, iteratorMetrics: ig.iteratorMetrics("Edges")}, nil
+}
+
+// InboundEdges implements graph.Graph.
+func (ig *InstrumentedGraph) InboundEdges(dstID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	return ig.InboundEdgesContext(context.Background(), dstID, updatedBefore)
+}
+
+// InboundEdgesContext implements graph.Graph.
+func (ig *InstrumentedGraph) InboundEdgesContext(ctx context.Context, dstID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	start := time.Now()
+	it, err := ig.g.InboundEdgesContext(ctx, dstID, updatedBefore)
+	ig.m.Observe("InboundEdges", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &edgeIterator{EdgeIterator: it, iteratorMetrics: ig.iteratorMetrics("InboundEdges")}, nil
+}
+
+// RemoveStaleEdges implements graph.Graph.
+func (ig *InstrumentedGraph) RemoveStaleEdges(fromID uuid.UUID, updatedBefore time.Time) error {
+	return ig.RemoveStaleEdgesContext(context.Background(), fromID, updatedBefore)
+}
+
+// RemoveStaleEdgesContext implements graph.Graph.
+func (ig *InstrumentedGraph) RemoveStaleEdgesContext(ctx context.Context, fromID uuid.UUID, updatedBefore time.Time) error {
+	start := time.Now()
+	err := ig.g.RemoveStaleEdgesContext(ctx, fromID, updatedBefore)
+	ig.m.Observe("RemoveStaleEdges", start, err)
+	return err
+}
+
+// iteratorMetrics returns the iteratorMetrics for an iterator created by
+// method.
+func (ig *InstrumentedGraph) iteratorMetrics(method string) iteratorMetrics {
+	return iteratorMetrics{method: method, m: ig.m, items: ig.m.Items(method)}
+}
+
+// iteratorMetrics counts the items returned by an iterator and records
+// the error that terminated the iteration.
+type iteratorMetrics struct {
+	method string
+	m      *metrics.RequestMetrics
+	items  *metrics.Counter
+	done   bool
+}
+
+// observeNext records the outcome of a call to the Next method of it.
+func (im *iteratorMetrics) observeNext(it graph.Iterator, hasNext bool) bool {
+	if hasNext {
+		im.items.Inc()
+	} else if !im.done {
+		im.done = true
+		im.m.ObserveError(im.method, it.Error())
+	}
+	return hasNext
+}
+
+// linkIterator is a graph.LinkIterator decorator that records metrics.
+type linkIterator struct {
+	graph.LinkIterator
+	iteratorMetrics
+}
+
+// Next implements graph.LinkIterator.
+func (it *linkIterator) Next() bool {
+	return it.observeNext(it.LinkIterator, it.LinkIterator.Next())
+}
+
+// edgeIterator is a graph.EdgeIterator decorator that records metrics.
+type edgeIterator struct {
+	graph.EdgeIterator
+	iteratorMetrics
+}
+
+// Next implements graph.EdgeIterator.
+func (it *edgeIterator) Next() bool {
+	return it.observeNext(it.EdgeIterator, it.EdgeIterator.Next())
+}
@@ -0,0 +1,82 @@
+package graphmetrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/metrics"
+	"github.com/google/uuid"
+	gc "gopkg.in/check.v1"
+)
+
+var (
+	_ = gc.Suite(new(InstrumentedGraphTestSuite))
+	_ = gc.Suite(new(MetricsTestSuite))
+)
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+// InstrumentedGraphTestSuite runs the graph conformance tests against an
+// instrumented in-memory graph.
+type InstrumentedGraphTestSuite struct {
+	graphtest.SuiteBase
+}
+
+func (s *InstrumentedGraphTestSuite) SetUpTest(c *gc.C) {
+	s.SetGraph(New(memory.NewInMemoryGraph(), metrics.NewRegistry()))
+}
+
+type MetricsTestSuite struct{}
+
+// TestScrape verifies that the recorded metrics can be scraped via the
+// registry's HTTP handler.
+func (s *MetricsTestSuite) TestScrape(c *gc.C) {
+	r := metrics.NewRegistry()
+	g := New(memory.NewInMemoryGraph(), r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	links := []*graph.Link{{URL: "http://example.com/"}, {URL: "http://example.com/about"}}
+	c.Assert(g.UpsertLinks(links), gc.IsNil)
+	c.Assert(g.UpsertEdge(&graph.Edge{Src: links[0].ID, Dst: links[1].ID}), gc.IsNil)
+	c.Assert(g.UpsertEdge(&graph.Edge{Src: links[0].ID, Dst: uuid.New()}), gc.NotNil)
+	for i := 0; i < 2; i++ {
+		_, err := g.FindLink(links[0].ID)
+		c.Assert(err, gc.IsNil)
+	}
+	_, err := g.FindLinkByURL("http://missing.example/")
+	c.Assert(err, gc.NotNil)
+
+	it, err := g.Links(partition.MinUUID, partition.MaxUUID, time.Now())
+	c.Assert(err, gc.IsNil)
+	for it.Next() {
+	}
+	c.Assert(it.Close(), gc.IsNil)
+
+	res, err := http.Get(srv.URL)
+	c.Assert(err, gc.IsNil)
+	body, err := io.ReadAll(res.Body)
+	c.Assert(err, gc.IsNil)
+	c.Assert(res.Body.Close(), gc.IsNil)
+	c.Assert(res.StatusCode, gc.Equals, http.StatusOK)
+
+	out := string(body)
+	for _, exp := range []string{
+		"# TYPE linkgraph_request_duration_seconds histogram",
+		`linkgraph_request_duration_seconds_count{method="FindLink"} 2`,
+		`linkgraph_request_duration_seconds_bucket{method="UpsertEdge",le="+Inf"} 2`,
+		`linkgraph_request_errors_total{method="FindLinkByURL",kind="not_found"} 1`,
+		`linkgraph_request_errors_total{method="UpsertEdge",kind="other"} 1`,
+		`linkgraph_iterator_items_total{method="Links"} 2`,
+	} {
+		c.Assert(strings.Contains(out, exp+"\n"), gc.Equals, true, gc.Commentf("missing %q in:\n%s", exp, out))
+	}
+}
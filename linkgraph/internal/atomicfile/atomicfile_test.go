@@ -0,0 +1,54 @@
+package atomicfile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(AtomicFileTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type AtomicFileTestSuite struct{}
+
+// TestWriteFile verifies that files are replaced with the new contents.
+func (s *AtomicFileTestSuite) TestWriteFile(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "state.json")
+	c.Assert(WriteFile(path, []byte("first")), gc.IsNil)
+	c.Assert(WriteFile(path, []byte("second")), gc.IsNil)
+
+	data, err := os.ReadFile(path)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, "second")
+	assertNoTempFiles(c, filepath.Dir(path))
+}
+
+// TestFailedWrite verifies that the file is left untouched and the temporary
+// file is removed if writeFn fails.
+func (s *AtomicFileTestSuite) TestFailedWrite(c *gc.C) {
+	path := filepath.Join(c.MkDir(), "state.json")
+	c.Assert(WriteFile(path, []byte("first")), gc.IsNil)
+
+	errWrite := xerrors.New("write failed")
+	err := Write(path, func(w io.Writer) error {
+		_, _ = w.Write([]byte("partial"))
+		return errWrite
+	})
+	c.Assert(err, gc.Equals, errWrite)
+
+	data, err := os.ReadFile(path)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(data), gc.Equals, "first")
+	assertNoTempFiles(c, filepath.Dir(path))
+}
+
+func assertNoTempFiles(c *gc.C, dir string) {
+	entries, err := os.ReadDir(dir)
+	c.Assert(err, gc.IsNil)
+	c.Assert(entries, gc.HasLen, 1)
+}
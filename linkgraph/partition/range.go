@@ -0,0 +1,165 @@
+// Package partition splits the UUID space into contiguous ranges that can be
+// processed in parallel by independent workers via the Links and Edges
+// methods of graph.Graph.
+package partition
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+var (
+	// MinUUID is the smallest possible UUID (all bits set to zero).
+	MinUUID = uuid.Nil
+
+	// MaxUUID is the largest possible UUID (all bits set to one).
+	MaxUUID = uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff")
+
+	// ErrInvalidPartition is returned when a partition number falls
+	// outside the [0, NumPartitions) range.
+	ErrInvalidPartition = xerrors.New("invalid partition")
+
+	// ErrOutOfRange is returned when looking up the partition for an ID
+	// that does not belong to the range.
+	ErrOutOfRange = xerrors.New("ID is outside the partitioned range")
+)
+
+// Range represents a contiguous UUID region which is split into a number of
+// partitions. Each partition covers the half-open interval [from, to) and the
+// partitions are laid out back-to-back so that the end of one partition is
+// the start of the next one.
+type Range struct {
+	start uuid.UUID
+
+	// rangeSplits contains the end of each partition. The last entry is
+	// always the end of the range.
+	rangeSplits []uuid.UUID
+}
+
+// NewFullRange creates a new range that covers the entire UUID space and
+// splits it into numPartitions partitions.
+func NewFullRange(numPartitions int) (Range, error) {
+	return NewRange(MinUUID, MaxUUID, numPartitions)
+}
+
+// NewRange creates a new range [start, end) and splits it into numPartitions
+// partitions of (almost) equal size. When the range size is not evenly
+// divisible by numPartitions, the remainder is spread across the first
+// partitions so that partition sizes never differ by more than one.
+func NewRange(start, end uuid.UUID, numPartitions int) (Range, error) {
+	if numPartitions <= 0 {
+		return Range{}, xerrors.Errorf("new range: number of partitions must be at least 1")
+	}
+
+	startInt := new(big.Int).SetBytes(start[:])
+	endInt := new(big.Int).SetBytes(end[:])
+	size := new(big.Int).Sub(endInt, startInt)
+	if size.Sign() <= 0 {
+		return Range{}, xerrors.Errorf("new range: range start must be less than the range end")
+	} else if size.Cmp(big.NewInt(int64(numPartitions))) < 0 {
+		return Range{}, xerrors.Errorf("new range: range is too small to be split into %d partitions", numPartitions)
+	}
+
+	partSize, remainder := new(big.Int).QuoRem(size, big.NewInt(int64(numPartitions)), new(big.Int))
+	extra := int(remainder.Int64())
+
+	var (
+		rangeSplits = make([]uuid.UUID, numPartitions)
+		offset      = new(big.Int).Set(startInt)
+		one         = big.NewInt(1)
+	)
+	for partition := 0; partition < numPartitions; partition++ {
+		offset.Add(offset, partSize)
+		if partition < extra {
+			offset.Add(offset, one)
+		}
+		rangeSplits[partition] = uuidFromBigInt(offset)
+	}
+
+	return Range{
+		start:       start,
+		rangeSplits: rangeSplits,
+	}, nil
+}
+
+// Extents returns the [start, end) extents of the range.
+func (r Range) Extents() (uuid.UUID, uuid.UUID) {
+	return r.start, r.rangeSplits[len(r.rangeSplits)-1]
+}
+
+// NumPartitions returns the number of partitions in the range.
+func (r Range) NumPartitions() int {
+	return len(r.rangeSplits)
+}
+
+// PartitionExtents returns the [from, to) extents for the requested partition.
+func (r Range) PartitionExtents(partition int) (uuid.UUID, uuid.UUID, error) {
+	if partition < 0 || partition >= len(r.rangeSplits) {
+		return uuid.Nil, uuid.Nil, xerrors.Errorf("partition extents: %w", ErrInvalidPartition)
+	}
+
+	if partition == 0 {
+		return r.start, r.rangeSplits[0], nil
+	}
+	return r.rangeSplits[partition-1], r.rangeSplits[partition], nil
+}
+
+// PartitionForID returns the number of the partition that contains id.
+//
+// As partitions are half-open intervals, the end of the range does not
+// belong to any of them. The only exception is MaxUUID which cannot be used
+// as an exclusive upper bound; it is mapped to the last partition so that
+// every ID in a full range has an owner.
+func (r Range) PartitionForID(id uuid.UUID) (int, error) {
+	start, end := r.Extents()
+	if compare(id, start) < 0 || compare(id, end) > 0 || (id == end && end != MaxUUID) {
+		return -1, xerrors.Errorf("partition for ID %s: %w", id, ErrOutOfRange)
+	}
+
+	// Find the first partition whose end is greater than id.
+	partition := sort.Search(len(r.rangeSplits), func(i int) bool {
+		return compare(id, r.rangeSplits[i]) < 0
+	})
+	if partition == len(r.rangeSplits) {
+		partition--
+	}
+	return partition, nil
+}
+
+// Repartition returns a new Range with the same extents as r that is split
+// into numPartitions partitions. It allows workers to recalculate their
+// assignments when the number of workers changes.
+func (r Range) Repartition(numPartitions int) (Range, error) {
+	start, end := r.Extents()
+	return NewRange(start, end, numPartitions)
+}
+
+// NextID returns the ID that immediately follows id. As partition ranges are
+// half-open, [id, NextID(id)) only contains id. MaxUUID has no successor and
+// is returned unchanged.
+func NextID(id uuid.UUID) uuid.UUID {
+	for i := len(id) - 1; i >= 0; i-- {
+		id[i]++
+		if id[i] != 0 {
+			return id
+		}
+	}
+	return MaxUUID
+}
+
+// compare returns -1, 0 or 1 if a is less than, equal to or greater than b.
+func compare(a, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// uuidFromBigInt converts v into a UUID. The caller must ensure that v fits
+// in 128 bits.
+func uuidFromBigInt(v *big.Int) uuid.UUID {
+	var id uuid.UUID
+	v.FillBytes(id[:])
+	return id
+}
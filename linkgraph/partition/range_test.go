@@ -0,0 +1,194 @@
+package partition
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(RangeTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type RangeTestSuite struct{}
+
+// TestNewRangeErrors verifies that invalid range specifications are rejected.
+func (s *RangeTestSuite) TestNewRangeErrors(c *gc.C) {
+	_, err := NewFullRange(0)
+	c.Assert(err, gc.ErrorMatches, ".*number of partitions must be at least 1")
+
+	_, err = NewRange(MaxUUID, MinUUID, 1)
+	c.Assert(err, gc.ErrorMatches, ".*range start must be less than the range end")
+
+	_, err = NewRange(MinUUID, MinUUID, 1)
+	c.Assert(err, gc.ErrorMatches, ".*range start must be less than the range end")
+
+	_, err = NewRange(MinUUID, uuidFromBigInt(big.NewInt(3)), 4)
+	c.Assert(err, gc.ErrorMatches, ".*range is too small to be split into 4 partitions")
+}
+
+// TestFullRangeCoverage verifies that the partitions of a full range cover
+// the entire UUID space without any gaps or overlaps.
+func (s *RangeTestSuite) TestFullRangeCoverage(c *gc.C) {
+	for _, numPartitions := range []int{1, 2, 3, 7, 10, 64, 1000} {
+		r, err := NewFullRange(numPartitions)
+		c.Assert(err, gc.IsNil)
+		assertGapFreeCoverage(c, r, MinUUID, MaxUUID)
+	}
+}
+
+// TestBalancedPartitions verifies that partition sizes never differ by more
+// than one when the range is not evenly divisible by the partition count.
+func (s *RangeTestSuite) TestBalancedPartitions(c *gc.C) {
+	start := uuidFromBigInt(big.NewInt(100))
+	end := uuidFromBigInt(big.NewInt(123))
+	r, err := NewRange(start, end, 5)
+	c.Assert(err, gc.IsNil)
+	assertGapFreeCoverage(c, r, start, end)
+
+	expSizes := []int64{5, 5, 5, 4, 4}
+	for partition, expSize := range expSizes {
+		from, to, err := r.PartitionExtents(partition)
+		c.Assert(err, gc.IsNil)
+		size := new(big.Int).Sub(toBigInt(to), toBigInt(from))
+		c.Assert(size.Int64(), gc.Equals, expSize, gc.Commentf("partition %d", partition))
+	}
+
+	r, err = NewFullRange(3)
+	c.Assert(err, gc.IsNil)
+	var minSize, maxSize *big.Int
+	for partition := 0; partition < r.NumPartitions(); partition++ {
+		from, to, err := r.PartitionExtents(partition)
+		c.Assert(err, gc.IsNil)
+		size := new(big.Int).Sub(toBigInt(to), toBigInt(from))
+		if minSize == nil || size.Cmp(minSize) < 0 {
+			minSize = size
+		}
+		if maxSize == nil || size.Cmp(maxSize) > 0 {
+			maxSize = size
+		}
+	}
+	c.Assert(new(big.Int).Sub(maxSize, minSize).Cmp(big.NewInt(1)) <= 0, gc.Equals, true)
+}
+
+// TestPartitionExtentsErrors verifies that out of bounds partition numbers
+// are rejected.
+func (s *RangeTestSuite) TestPartitionExtentsErrors(c *gc.C) {
+	r, err := NewFullRange(4)
+	c.Assert(err, gc.IsNil)
+
+	for _, partition := range []int{-1, 4} {
+		_, _, err = r.PartitionExtents(partition)
+		c.Assert(xerrors.Is(err, ErrInvalidPartition), gc.Equals, true, gc.Commentf("partition %d", partition))
+	}
+}
+
+// TestPartitionForID verifies that IDs are mapped to the partition whose
+// extents contain them.
+func (s *RangeTestSuite) TestPartitionForID(c *gc.C) {
+	r, err := NewFullRange(10)
+	c.Assert(err, gc.IsNil)
+
+	for partition := 0; partition < r.NumPartitions(); partition++ {
+		from, to, err := r.PartitionExtents(partition)
+		c.Assert(err, gc.IsNil)
+
+		got, err := r.PartitionForID(from)
+		c.Assert(err, gc.IsNil)
+		c.Assert(got, gc.Equals, partition, gc.Commentf("start of partition %d", partition))
+
+		lastID := uuidFromBigInt(new(big.Int).Sub(toBigInt(to), big.NewInt(1)))
+		got, err = r.PartitionForID(lastID)
+		c.Assert(err, gc.IsNil)
+		c.Assert(got, gc.Equals, partition, gc.Commentf("end of partition %d", partition))
+	}
+
+	// Random IDs must always land in the partition that contains them.
+	for i := 0; i < 1000; i++ {
+		id := uuid.New()
+		partition, err := r.PartitionForID(id)
+		c.Assert(err, gc.IsNil)
+
+		from, to, err := r.PartitionExtents(partition)
+		c.Assert(err, gc.IsNil)
+		c.Assert(compare(id, from) >= 0 && compare(id, to) < 0, gc.Equals, true, gc.Commentf("ID %s", id))
+	}
+
+	// MaxUUID cannot be used as an exclusive upper bound so it should be
+	// owned by the last partition.
+	partition, err := r.PartitionForID(MaxUUID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(partition, gc.Equals, r.NumPartitions()-1)
+}
+
+// TestPartitionForIDOutOfRange verifies that IDs outside a sub-range are
+// rejected.
+func (s *RangeTestSuite) TestPartitionForIDOutOfRange(c *gc.C) {
+	start := uuidFromBigInt(big.NewInt(100))
+	end := uuidFromBigInt(big.NewInt(200))
+	r, err := NewRange(start, end, 4)
+	c.Assert(err, gc.IsNil)
+
+	for _, id := range []uuid.UUID{MinUUID, uuidFromBigInt(big.NewInt(99)), end, MaxUUID} {
+		_, err = r.PartitionForID(id)
+		c.Assert(xerrors.Is(err, ErrOutOfRange), gc.Equals, true, gc.Commentf("ID %s", id))
+	}
+}
+
+// TestRepartition verifies that a range can be re-split into a different
+// number of partitions while preserving its extents.
+func (s *RangeTestSuite) TestRepartition(c *gc.C) {
+	start := uuid.MustParse("40000000-0000-0000-0000-000000000000")
+	end := uuid.MustParse("c0000000-0000-0000-0000-000000000000")
+	r, err := NewRange(start, end, 3)
+	c.Assert(err, gc.IsNil)
+
+	for _, numPartitions := range []int{1, 5, 16} {
+		r2, err := r.Repartition(numPartitions)
+		c.Assert(err, gc.IsNil)
+		c.Assert(r2.NumPartitions(), gc.Equals, numPartitions)
+		assertGapFreeCoverage(c, r2, start, end)
+	}
+
+	// The original range must not be affected.
+	c.Assert(r.NumPartitions(), gc.Equals, 3)
+	assertGapFreeCoverage(c, r, start, end)
+
+	_, err = r.Repartition(0)
+	c.Assert(err, gc.NotNil)
+}
+
+func assertGapFreeCoverage(c *gc.C, r Range, expStart, expEnd uuid.UUID) {
+	start, end := r.Extents()
+	c.Assert(start, gc.Equals, expStart)
+	c.Assert(end, gc.Equals, expEnd)
+
+	var prevTo uuid.UUID
+	for partition := 0; partition < r.NumPartitions(); partition++ {
+		from, to, err := r.PartitionExtents(partition)
+		c.Assert(err, gc.IsNil)
+		c.Assert(compare(from, to) < 0, gc.Equals, true, gc.Commentf("partition %d is empty", partition))
+
+		if partition == 0 {
+			c.Assert(from, gc.Equals, expStart)
+		} else {
+			c.Assert(from, gc.Equals, prevTo, gc.Commentf("gap or overlap before partition %d", partition))
+		}
+		prevTo = to
+	}
+	c.Assert(prevTo, gc.Equals, expEnd)
+}
+
+func toBigInt(id uuid.UUID) *big.Int {
+	return new(big.Int).SetBytes(id[:])
+}
+
+// TestNextID verifies the calculation of the ID that follows another ID.
+func (s *RangeTestSuite) TestNextID(c *gc.C) {
+	c.Assert(NextID(uuid.Nil), gc.Equals, uuid.MustParse("00000000-0000-0000-0000-000000000001"))
+	c.Assert(NextID(uuid.MustParse("00000000-0000-0000-0000-0000000000ff")), gc.Equals, uuid.MustParse("00000000-0000-0000-0000-000000000100"))
+	c.Assert(NextID(MaxUUID), gc.Equals, MaxUUID)
+}
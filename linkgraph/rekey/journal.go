@@ -0,0 +1,85 @@
+package rekey
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/internal/atomicfile"
+	"golang.org/x/xerrors"
+)
+
+// journal records the original state of the link that is being rekeyed so
+// that a move which was interrupted after the link was removed can be
+// completed by a subsequent run. All methods are safe to call on a nil
+// journal which does not record anything.
+type journal struct {
+	path string
+}
+
+// journalEntry is the on-disk representation of a link that is being moved.
+type journalEntry struct {
+	Link  *graph.Link   `json:"link"`
+	Edges []*graph.Edge `json:"edges"`
+}
+
+// openJournal returns a journal backed by the file at path. A nil journal
+// is returned if path is empty.
+func openJournal(path string) *journal {
+	if path == "" {
+		return nil
+	}
+	return &journal{path: path}
+}
+
+// pending returns the entry left behind by an interrupted run or nil if the
+// journal is empty.
+func (j *journal) pending() (*journalEntry, error) {
+	if j == nil {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, xerrors.Errorf("load journal: %w", err)
+	}
+
+	var entry journalEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return nil, xerrors.Errorf("load journal: %w", err)
+	}
+	if entry.Link == nil {
+		return nil, xerrors.Errorf("load journal: entry does not contain a link")
+	}
+	return &entry, nil
+}
+
+// record atomically replaces the journal contents with entry.
+func (j *journal) record(entry *journalEntry) error {
+	if j == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return xerrors.Errorf("save journal: %w", err)
+	}
+
+	if err = atomicfile.WriteFile(j.path, data); err != nil {
+		return xerrors.Errorf("save journal: %w", err)
+	}
+	return nil
+}
+
+// clear deletes the journal file once the recorded move has completed.
+func (j *journal) clear() error {
+	if j == nil {
+		return nil
+	}
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("clear journal: %w", err)
+	}
+	return nil
+}
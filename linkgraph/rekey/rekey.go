@@ -0,0 +1,210 @@
+// Package rekey migrates links that were stored with random IDs to the
+// deterministic IDs returned by graph.LinkIDFromURL.
+package rekey
+
+import (
+	"context"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/relink"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// ErrRandomIDs is returned by Run if the graph does not assign deterministic
+// IDs to new links.
+var ErrRandomIDs = xerrors.New("graph does not assign deterministic link IDs")
+
+// deterministicIDGraph is implemented by graphs that can report whether they
+// assign deterministic IDs to new links.
+type deterministicIDGraph interface {
+	DeterministicIDs() bool
+}
+
+// Config encapsulates the settings for a rekey run.
+type Config struct {
+	// The number of partitions to split the link ID space into. Links are
+	// scanned and rekeyed one partition at a time which bounds the amount
+	// of memory required for the run. Defaults to 1.
+	NumPartitions int
+
+	// An optional path to a journal file that records the original state
+	// of the link that is currently being moved. If a previous run was
+	// interrupted in the middle of a move, the journaled link and its edges
+	// are restored before any other link is processed. Without a journal,
+	// a crash during a move may lose the link and its edges.
+	JournalPath string
+
+	// When set, links are only scanned and counted; the graph is not
+	// modified.
+	DryRun bool
+}
+
+// Stats summarizes the outcome of a rekey run.
+type Stats struct {
+	// The number of links that were scanned.
+	Scanned int
+
+	// The number of links whose ID did not match the deterministic ID for
+	// their URL. Unless the run is a dry-run, each of these links has been
+	// re-inserted with its deterministic ID.
+	Rekeyed int
+}
+
+// Run scans all links in g and re-inserts each link whose ID differs from
+// the deterministic ID for its URL. The crawl metadata of re-inserted links
+// is retained and the edges that originate from or point to them are moved
+// over to the new ID with their attributes intact; the update timestamp of
+// moved edges is reset, though.
+//
+// The graph must be configured to assign deterministic IDs to new links and
+// report this via a DeterministicIDs method; otherwise, Run fails with
+// ErrRandomIDs before modifying the graph. As the deterministic ID is derived
+// from the link URL as stored, URLs should be canonicalized (see the
+// rewrite-urls command) before running a rekey. Each link is removed before
+// it is re-inserted, so the graph should not be written to by other clients
+// while the run is in progress. Once a link has been removed, its move is
+// completed even if ctx is cancelled.
+func Run(ctx context.Context, g graph.Graph, cfg Config) (Stats, error) {
+	var stats Stats
+	if cfg.NumPartitions <= 0 {
+		cfg.NumPartitions = 1
+	}
+
+	var jrnl *journal
+	if !cfg.DryRun {
+		if dg, ok := g.(deterministicIDGraph); !ok || !dg.DeterministicIDs() {
+			return stats, xerrors.Errorf("rekey: %w", ErrRandomIDs)
+		}
+
+		jrnl = openJournal(cfg.JournalPath)
+		if err := resumeMove(ctx, g, jrnl); err != nil {
+			return stats, xerrors.Errorf("rekey: %w", err)
+		}
+	}
+
+	r, err := partition.NewFullRange(cfg.NumPartitions)
+	if err != nil {
+		return stats, xerrors.Errorf("rekey: %w", err)
+	}
+
+	for p := 0; p < r.NumPartitions(); p++ {
+		from, to, err := r.PartitionExtents(p)
+		if err != nil {
+			return stats, xerrors.Errorf("rekey: %w", err)
+		}
+
+		links, err := randomIDLinks(ctx, g, from, to, &stats)
+		if err != nil {
+			return stats, xerrors.Errorf("rekey: %w", err)
+		}
+
+		for _, link := range links {
+			if !cfg.DryRun {
+				if err = rekeyLink(ctx, g, jrnl, link); err != nil {
+					return stats, xerrors.Errorf("rekey: link %s: %w", link.ID, err)
+				}
+			}
+			stats.Rekeyed++
+		}
+	}
+
+	return stats, nil
+}
+
+// randomIDLinks returns the links in the [from, to) range whose ID does not
+// match the deterministic ID for their URL.
+func randomIDLinks(ctx context.Context, g graph.Graph, from, to uuid.UUID, stats *Stats) ([]*graph.Link, error) {
+	it, err := g.LinksContext(ctx, from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = it.Close() }()
+
+	var links []*graph.Link
+	for it.Next() {
+		stats.Scanned++
+
+		link := it.Link()
+		if link.ID == graph.LinkIDFromURL(link.URL) {
+			continue
+		}
+		links = append(links, link)
+	}
+	if err = it.Error(); err != nil {
+		return nil, err
+	}
+
+	return links, it.Close()
+}
+
+// resumeMove completes the move that is recorded in jrnl by a previous,
+// interrupted run.
+func resumeMove(ctx context.Context, g graph.Graph, jrnl *journal) error {
+	entry, err := jrnl.pending()
+	if err != nil || entry == nil {
+		return err
+	}
+
+	// Upserting the link is a no-op if it was not removed before the
+	// previous run was interrupted; its edges then map to the original ID.
+	if err = moveLink(detachedContext{ctx}, g, entry); err != nil {
+		return xerrors.Errorf("resume move of link %s: %w", entry.Link.ID, err)
+	}
+	return jrnl.clear()
+}
+
+// rekeyLink removes link from the graph, re-inserts it with its deterministic
+// ID and restores its edges. The original state of the link is recorded in
+// jrnl before the link is removed.
+func rekeyLink(ctx context.Context, g graph.Graph, jrnl *journal, link *graph.Link) error {
+	edges, err := relink.LinkEdges(ctx, g, link.ID)
+	if err != nil {
+		return err
+	}
+
+	entry := &journalEntry{Link: link, Edges: edges}
+	if err = jrnl.record(entry); err != nil {
+		return err
+	}
+
+	// Interrupting the move half-way would leave the link removed, so
+	// ignore cancellations from here on.
+	moveCtx := detachedContext{ctx}
+	if err = g.RemoveLinkContext(moveCtx, link.ID); err != nil {
+		return err
+	}
+	if err = moveLink(moveCtx, g, entry); err != nil {
+		return err
+	}
+	return jrnl.clear()
+}
+
+// moveLink re-inserts the link recorded in entry and restores its edges.
+func moveLink(ctx context.Context, g graph.Graph, entry *journalEntry) error {
+	origID := entry.Link.ID
+
+	rekeyed := *entry.Link
+	rekeyed.ID = uuid.Nil
+	if err := g.UpsertLinkContext(ctx, &rekeyed); err != nil {
+		return err
+	}
+
+	if len(entry.Edges) == 0 {
+		return nil
+	}
+
+	return g.UpsertEdgesContext(ctx, relink.Remap(entry.Edges, origID, rekeyed.ID))
+}
+
+// detachedContext passes through the values of the wrapped context but is
+// never cancelled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
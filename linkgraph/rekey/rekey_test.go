@@ -0,0 +1,223 @@
+package rekey
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(RekeyTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+// The edges populated by SetUpTest as returned by graphtest.AllEdges.
+var expEdges = []string{
+	"http://example.com/ -> http://example.com/",
+	"http://example.com/ -> http://example.com/about",
+	"http://example.com/about -> http://other.example/",
+	"http://other.example/ -> http://example.com/",
+}
+
+type RekeyTestSuite struct {
+	g *memory.InMemoryGraph
+
+	// Links populated by SetUpTest, keyed by their URL.
+	links map[string]*graph.Link
+}
+
+// SetUpTest populates a graph with links that were assigned random IDs.
+func (s *RekeyTestSuite) SetUpTest(c *gc.C) {
+	s.g = memory.NewInMemoryGraph()
+
+	s.links = make(map[string]*graph.Link)
+	for _, u := range []string{
+		"http://example.com/",
+		"http://example.com/about",
+		"http://other.example/",
+	} {
+		link := &graph.Link{
+			URL:         u,
+			RetrievedAt: time.Now().Add(-time.Hour).Truncate(time.Second).UTC(),
+			StatusCode:  200,
+			ContentType: "text/html",
+		}
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+		s.links[u] = link
+	}
+
+	for _, e := range [][2]string{
+		{"http://example.com/", "http://example.com/about"},
+		{"http://example.com/", "http://example.com/"},
+		{"http://example.com/about", "http://other.example/"},
+		{"http://other.example/", "http://example.com/"},
+	} {
+		edge := &graph.Edge{Src: s.links[e[0]].ID, Dst: s.links[e[1]].ID, AnchorText: e[1], UGC: true, LinkCount: 2}
+		c.Assert(s.g.UpsertEdge(edge), gc.IsNil)
+	}
+}
+
+// TestRekey verifies that links are re-inserted with their deterministic IDs
+// together with their metadata and edges.
+func (s *RekeyTestSuite) TestRekey(c *gc.C) {
+	s.g.SetDeterministicIDs(true)
+
+	stats, err := Run(context.TODO(), s.g, Config{NumPartitions: 3})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats.Rekeyed, gc.Equals, 3)
+
+	// Rekeyed links are scanned a second time if their new ID falls into a
+	// partition that is processed later.
+	c.Assert(stats.Scanned >= 3 && stats.Scanned <= 6, gc.Equals, true, gc.Commentf("scanned %d links", stats.Scanned))
+
+	links := graphtest.AllLinks(c, s.g)
+	c.Assert(links, gc.HasLen, 3)
+	for u, link := range links {
+		c.Assert(link.ID, gc.Equals, graph.LinkIDFromURL(u))
+		c.Assert(link.RetrievedAt, gc.Equals, s.links[u].RetrievedAt)
+		c.Assert(link.FirstSeenAt, gc.Equals, s.links[u].FirstSeenAt)
+		c.Assert(link.StatusCode, gc.Equals, 200)
+		c.Assert(link.ContentType, gc.Equals, "text/html")
+	}
+
+	c.Assert(graphtest.AllEdges(c, s.g), gc.DeepEquals, expEdges)
+
+	// Moved edges must retain their attributes.
+	it, err := s.g.InboundEdges(graph.LinkIDFromURL("http://other.example/"), time.Now())
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Edge().AnchorText, gc.Equals, "http://other.example/")
+	c.Assert(it.Edge().UGC, gc.Equals, true)
+	c.Assert(it.Edge().LinkCount, gc.Equals, 2)
+	c.Assert(it.Close(), gc.IsNil)
+
+	// Running the rekey again must be a no-op.
+	stats, err = Run(context.TODO(), s.g, Config{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Scanned: 3})
+}
+
+// TestDryRun verifies that a dry-run reports the links that would be rekeyed
+// without modifying the graph.
+func (s *RekeyTestSuite) TestDryRun(c *gc.C) {
+	stats, err := Run(context.TODO(), s.g, Config{DryRun: true})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Scanned: 3, Rekeyed: 3})
+
+	for u, link := range graphtest.AllLinks(c, s.g) {
+		c.Assert(link.ID, gc.Equals, s.links[u].ID)
+	}
+}
+
+// TestRandomIDs verifies that a rekey run is aborted if the graph is not
+// configured to assign deterministic IDs.
+func (s *RekeyTestSuite) TestRandomIDs(c *gc.C) {
+	_, err := Run(context.TODO(), s.g, Config{})
+	c.Assert(xerrors.Is(err, ErrRandomIDs), gc.Equals, true, gc.Commentf("%v", err))
+	s.assertUnchanged(c)
+}
+
+// TestCancelledRun verifies that cancelling a rekey run while a link is
+// being moved does not lose the link or its edges.
+func (s *RekeyTestSuite) TestCancelledRun(c *gc.C) {
+	s.g.SetDeterministicIDs(true)
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	defer cancelFn()
+	journalPath := filepath.Join(c.MkDir(), "rekey.journal")
+
+	fg := &faultyGraph{InMemoryGraph: s.g, onRemove: cancelFn}
+	stats, err := Run(ctx, fg, Config{JournalPath: journalPath})
+	c.Assert(xerrors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("%v", err))
+	c.Assert(stats.Rekeyed, gc.Equals, 1)
+
+	links := graphtest.AllLinks(c, s.g)
+	c.Assert(links, gc.HasLen, 3)
+	var rekeyed int
+	for u, link := range links {
+		if link.ID == graph.LinkIDFromURL(u) {
+			rekeyed++
+		} else {
+			c.Assert(link.ID, gc.Equals, s.links[u].ID)
+		}
+	}
+	c.Assert(rekeyed, gc.Equals, 1)
+	c.Assert(graphtest.AllEdges(c, s.g), gc.DeepEquals, expEdges)
+
+	_, err = os.Stat(journalPath)
+	c.Assert(os.IsNotExist(err), gc.Equals, true, gc.Commentf("journal was not cleared: %v", err))
+}
+
+// TestResumeFailedMove verifies that a move which failed after the link was
+// removed is completed by the next run using the journal.
+func (s *RekeyTestSuite) TestResumeFailedMove(c *gc.C) {
+	s.g.SetDeterministicIDs(true)
+	journalPath := filepath.Join(c.MkDir(), "rekey.journal")
+
+	fg := &faultyGraph{InMemoryGraph: s.g, failEdges: true}
+	_, err := Run(context.TODO(), fg, Config{JournalPath: journalPath})
+	c.Assert(err, gc.ErrorMatches, ".*injected failure")
+
+	_, err = os.Stat(journalPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(len(graphtest.AllEdges(c, s.g)) < len(expEdges), gc.Equals, true)
+
+	stats, err := Run(context.TODO(), fg, Config{JournalPath: journalPath})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats.Rekeyed, gc.Equals, 2)
+
+	links := graphtest.AllLinks(c, s.g)
+	c.Assert(links, gc.HasLen, 3)
+	for u, link := range links {
+		c.Assert(link.ID, gc.Equals, graph.LinkIDFromURL(u))
+		c.Assert(link.StatusCode, gc.Equals, 200)
+	}
+	c.Assert(graphtest.AllEdges(c, s.g), gc.DeepEquals, expEdges)
+
+	_, err = os.Stat(journalPath)
+	c.Assert(os.IsNotExist(err), gc.Equals, true, gc.Commentf("journal was not cleared: %v", err))
+}
+
+// assertUnchanged verifies that the graph still contains the links and edges
+// populated by SetUpTest with their original IDs.
+func (s *RekeyTestSuite) assertUnchanged(c *gc.C) {
+	links := graphtest.AllLinks(c, s.g)
+	c.Assert(links, gc.HasLen, len(s.links))
+	for u, link := range links {
+		c.Assert(link.ID, gc.Equals, s.links[u].ID)
+	}
+	c.Assert(graphtest.AllEdges(c, s.g), gc.DeepEquals, expEdges)
+}
+
+// faultyGraph wraps an in-memory graph and injects failures into rekey runs.
+type faultyGraph struct {
+	*memory.InMemoryGraph
+
+	// If set, the next call to UpsertEdgesContext fails.
+	failEdges bool
+
+	// If set, invoked before a link is removed.
+	onRemove func()
+}
+
+func (g *faultyGraph) RemoveLinkContext(ctx context.Context, id uuid.UUID) error {
+	if g.onRemove != nil {
+		g.onRemove()
+	}
+	return g.InMemoryGraph.RemoveLinkContext(ctx, id)
+}
+
+func (g *faultyGraph) UpsertEdgesContext(ctx context.Context, edges []*graph.Edge) error {
+	if g.failEdges {
+		g.failEdges = false
+		return xerrors.New("upsert edges: injected failure")
+	}
+	return g.InMemoryGraph.UpsertEdgesContext(ctx, edges)
+}
@@ -0,0 +1,75 @@
+// Package relink provides helpers for moving the edges of a link over to a
+// link with a different ID.
+package relink
+
+import (
+	"context"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
+	"github.com/google/uuid"
+)
+
+// LinkEdges returns the edges that originate from or point to the link with
+// the specified ID. Self-loops are only returned once.
+func LinkEdges(ctx context.Context, g graph.Graph, id uuid.UUID) ([]*graph.Edge, error) {
+	var edges []*graph.Edge
+	outIt, err := g.EdgesContext(ctx, id, partition.NextID(id), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for outIt.Next() {
+		edges = append(edges, outIt.Edge())
+	}
+	if err = outIt.Error(); err != nil {
+		_ = outIt.Close()
+		return nil, err
+	}
+	if err = outIt.Close(); err != nil {
+		return nil, err
+	}
+
+	inIt, err := g.InboundEdgesContext(ctx, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for inIt.Next() {
+		edge := inIt.Edge()
+		if edge.Src == id {
+			// Self-loops have already been collected above.
+			continue
+		}
+		edges = append(edges, edge)
+	}
+	if err = inIt.Error(); err != nil {
+		_ = inIt.Close()
+		return nil, err
+	}
+	if err = inIt.Close(); err != nil {
+		return nil, err
+	}
+
+	return edges, nil
+}
+
+// Remap returns copies of edges in which all references to fromID are
+// replaced by toID. The copies retain the attributes of the original edges
+// but have no ID so that they can be upserted as new edges.
+func Remap(edges []*graph.Edge, fromID, toID uuid.UUID) []*graph.Edge {
+	remapID := func(id uuid.UUID) uuid.UUID {
+		if id == fromID {
+			return toID
+		}
+		return id
+	}
+
+	moved := make([]*graph.Edge, len(edges))
+	for i, edge := range edges {
+		eCopy := *edge
+		eCopy.ID = uuid.Nil
+		eCopy.Src, eCopy.Dst = remapID(edge.Src), remapID(edge.Dst)
+		moved[i] = &eCopy
+	}
+	return moved
+}
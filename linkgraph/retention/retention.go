@@ -0,0 +1,218 @@
+// Package retention removes stale links that are no longer reachable from
+// any other link in the graph.
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// Config encapsulates the settings for a garbage collection run.
+type Config struct {
+	// Links that have not been retrieved (and were first seen) within
+	// this window are eligible for removal. Must be positive.
+	Retention time.Duration
+
+	// The number of partitions to split the link ID space into. The IDs of
+	// the expired links in a partition are collected before any of them
+	// is checked, so more partitions mean fewer IDs held at once.
+	// Defaults to 1.
+	NumPartitions int
+
+	// The maximum number of eligible links to check and remove per second.
+	// Each check performs a few queries against the graph, so this setting
+	// bounds the load that the run imposes on a live graph. If zero, the
+	// run is not rate-limited.
+	MaxLinksPerSecond int
+
+	// When set, expired links without inbound edges are counted as
+	// removable in Stats but neither they nor their edges are removed.
+	DryRun bool
+}
+
+// Stats summarizes the outcome of a garbage collection run.
+type Stats struct {
+	// The number of links that fell outside the retention window.
+	Expired int
+
+	// The number of expired links without inbound edges. Unless the run
+	// is a dry-run, each of these links has been removed.
+	Removed int
+
+	// The number of edges originating from the removed links.
+	RemovedEdges int
+}
+
+// Run removes the links in g that have neither been retrieved nor first
+// seen within cfg.Retention and which are not the destination of any edge
+// other than a self-loop. The outgoing edges of removed links are removed
+// as well.
+//
+// As removing a link also removes its outgoing edges, the destinations of
+// these edges may become eligible for removal themselves; they are picked
+// up if they belong to a partition that is processed later or by the next
+// run.
+//
+// Run can be used against a live graph. Each link is looked up again right
+// before it is removed and skipped if it has been retrieved in the meantime;
+// an edge pointing to the link that is inserted between this check and the
+// removal is removed along with the link, though.
+func Run(ctx context.Context, g graph.Graph, cfg Config) (Stats, error) {
+	var stats Stats
+	if cfg.Retention <= 0 {
+		return stats, xerrors.New("gc: retention window must be positive")
+	}
+	if cfg.NumPartitions <= 0 {
+		cfg.NumPartitions = 1
+	}
+
+	r, err := partition.NewFullRange(cfg.NumPartitions)
+	if err != nil {
+		return stats, xerrors.Errorf("gc: %w", err)
+	}
+
+	var throttle <-chan time.Time
+	if cfg.MaxLinksPerSecond > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(cfg.MaxLinksPerSecond))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	cutoff := time.Now().Add(-cfg.Retention)
+	for p := 0; p < r.NumPartitions(); p++ {
+		from, to, err := r.PartitionExtents(p)
+		if err != nil {
+			return stats, xerrors.Errorf("gc: %w", err)
+		}
+
+		ids, err := expiredLinks(ctx, g, from, to, cutoff)
+		if err != nil {
+			return stats, xerrors.Errorf("gc: %w", err)
+		}
+		stats.Expired += len(ids)
+
+		for _, id := range ids {
+			if throttle != nil {
+				select {
+				case <-throttle:
+				case <-ctx.Done():
+					return stats, xerrors.Errorf("gc: %w", ctx.Err())
+				}
+			}
+
+			if err = collectLink(ctx, g, id, cutoff, cfg.DryRun, &stats); err != nil {
+				return stats, xerrors.Errorf("gc: %w", err)
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// expiredLinks returns the IDs of the links in the [from, to) range that
+// have neither been retrieved nor first seen since cutoff.
+func expiredLinks(ctx context.Context, g graph.Graph, from, to uuid.UUID, cutoff time.Time) ([]uuid.UUID, error) {
+	it, err := g.LinksContext(ctx, from, to, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = it.Close() }()
+
+	var ids []uuid.UUID
+	for it.Next() {
+		if link := it.Link(); isExpired(link, cutoff) {
+			ids = append(ids, link.ID)
+		}
+	}
+	if err = it.Error(); err != nil {
+		return nil, err
+	}
+
+	return ids, it.Close()
+}
+
+// collectLink removes the link with the specified ID if it is still expired
+// and has no inbound edges.
+func collectLink(ctx context.Context, g graph.Graph, id uuid.UUID, cutoff time.Time, dryRun bool, stats *Stats) error {
+	link, err := g.FindLinkContext(ctx, id)
+	if xerrors.Is(err, graph.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if !isExpired(link, cutoff) {
+		return nil
+	}
+
+	if referenced, err := hasInboundEdges(ctx, g, id); err != nil || referenced {
+		return err
+	}
+
+	numEdges, err := countOutboundEdges(ctx, g, id)
+	if err != nil {
+		return err
+	}
+
+	if !dryRun {
+		if err = g.RemoveLinkContext(ctx, id); xerrors.Is(err, graph.ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	stats.Removed++
+	stats.RemovedEdges += numEdges
+	return nil
+}
+
+// isExpired returns true if link has neither been retrieved nor first seen
+// since cutoff.
+func isExpired(link *graph.Link, cutoff time.Time) bool {
+	return link.RetrievedAt.Before(cutoff) && link.FirstSeenAt.Before(cutoff)
+}
+
+// hasInboundEdges returns true if any link other than the link with the
+// specified ID points to it.
+func hasInboundEdges(ctx context.Context, g graph.Graph, id uuid.UUID) (bool, error) {
+	it, err := g.InboundEdgesContext(ctx, id, time.Now())
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = it.Close() }()
+
+	for it.Next() {
+		if it.Edge().Src != id {
+			return true, it.Close()
+		}
+	}
+	if err = it.Error(); err != nil {
+		return false, err
+	}
+
+	return false, it.Close()
+}
+
+// countOutboundEdges returns the number of edges originating from the link
+// with the specified ID.
+func countOutboundEdges(ctx context.Context, g graph.Graph, id uuid.UUID) (int, error) {
+	it, err := g.EdgesContext(ctx, id, partition.NextID(id), time.Now())
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = it.Close() }()
+
+	var count int
+	for it.Next() {
+		count++
+	}
+	if err = it.Error(); err != nil {
+		return 0, err
+	}
+
+	return count, it.Close()
+}
@@ -0,0 +1,99 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(RetentionTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type RetentionTestSuite struct {
+	g *memory.InMemoryGraph
+}
+
+// SetUpTest populates a graph with a mix of stale and recent links.
+func (s *RetentionTestSuite) SetUpTest(c *gc.C) {
+	s.g = memory.NewInMemoryGraph()
+
+	stale := time.Now().Add(-48 * time.Hour).UTC()
+	links := make(map[string]*graph.Link)
+	for u, link := range map[string]*graph.Link{
+		// Stale orphan that points to a recent link.
+		"http://example.com/orphan": {RetrievedAt: stale, FirstSeenAt: stale},
+		// Stale link that is still referenced by a recent link.
+		"http://example.com/referenced": {RetrievedAt: stale, FirstSeenAt: stale},
+		// Stale link that only references itself.
+		"http://example.com/self": {FirstSeenAt: stale},
+		// Recently retrieved link.
+		"http://example.com/recent": {RetrievedAt: time.Now().UTC(), FirstSeenAt: stale},
+		// Recently discovered link that has not been retrieved yet.
+		"http://example.com/new": {},
+	} {
+		link.URL = u
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+		links[u] = link
+	}
+
+	for _, e := range [][2]string{
+		{"http://example.com/orphan", "http://example.com/recent"},
+		{"http://example.com/recent", "http://example.com/referenced"},
+		{"http://example.com/self", "http://example.com/self"},
+	} {
+		c.Assert(s.g.UpsertEdge(&graph.Edge{Src: links[e[0]].ID, Dst: links[e[1]].ID}), gc.IsNil)
+	}
+}
+
+// TestRun verifies that only stale links without inbound edges are removed
+// together with their outgoing edges.
+func (s *RetentionTestSuite) TestRun(c *gc.C) {
+	stats, err := Run(context.TODO(), s.g, Config{Retention: 24 * time.Hour, NumPartitions: 3})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Expired: 3, Removed: 2, RemovedEdges: 2})
+
+	c.Assert(graphtest.LinkURLs(c, s.g), gc.DeepEquals, []string{
+		"http://example.com/new",
+		"http://example.com/recent",
+		"http://example.com/referenced",
+	})
+	c.Assert(len(graphtest.AllEdges(c, s.g)), gc.Equals, 1)
+}
+
+// TestDryRun verifies that a dry-run reports the links that would be removed
+// without modifying the graph.
+func (s *RetentionTestSuite) TestDryRun(c *gc.C) {
+	stats, err := Run(context.TODO(), s.g, Config{Retention: 24 * time.Hour, DryRun: true})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Expired: 3, Removed: 2, RemovedEdges: 2})
+	c.Assert(graphtest.LinkURLs(c, s.g), gc.HasLen, 5)
+	c.Assert(len(graphtest.AllEdges(c, s.g)), gc.Equals, 3)
+}
+
+// TestRateLimit verifies that the number of links checked per second is
+// bounded.
+func (s *RetentionTestSuite) TestRateLimit(c *gc.C) {
+	start := time.Now()
+	stats, err := Run(context.TODO(), s.g, Config{Retention: 24 * time.Hour, MaxLinksPerSecond: 20})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats.Removed, gc.Equals, 2)
+	c.Assert(time.Since(start) >= 100*time.Millisecond, gc.Equals, true, gc.Commentf("run took %s", time.Since(start)))
+
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	cancelFn()
+	s.SetUpTest(c)
+	_, err = Run(ctx, s.g, Config{Retention: 24 * time.Hour, MaxLinksPerSecond: 1})
+	c.Assert(err, gc.ErrorMatches, ".*context canceled")
+}
+
+// TestInvalidRetention verifies that a retention window must be specified.
+func (s *RetentionTestSuite) TestInvalidRetention(c *gc.C) {
+	_, err := Run(context.TODO(), s.g, Config{})
+	c.Assert(err, gc.ErrorMatches, ".*retention window must be positive")
+}
@@ -0,0 +1,662 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
+	"github.com/google/uuid"
+	bbolt "go.etcd.io/bbolt"
+	"golang.org/x/xerrors"
+)
+
+var (
+	// linksBucket maps link IDs to JSON-encoded links.
+	linksBucket = []byte("links")
+
+	// linkURLBucket maps link URLs to link IDs.
+	linkURLBucket = []byte("link_urls")
+
+	// edgesBucket maps (src, dst) link ID pairs to JSON-encoded edges. As
+	// keys are prefixed with the source link ID, all edges originating
+	// from the same link are stored next to each other.
+	edgesBucket = []byte("edges")
+
+	// inboundEdgesBucket indexes edges by their destination link. Keys are
+	// (dst, src) link ID pairs and values are empty.
+	inboundEdgesBucket = []byte("inbound_edges")
+
+	// hostLinksBucket indexes links by the host that their URL refers to.
+	// Keys are the canonical host name followed by a zero byte and the
+	// link ID; values are empty.
+	hostLinksBucket = []byte("host_links")
+
+	// Compile-time check for ensuring BoltGraph implements Graph.
+	_ graph.Graph = (*BoltGraph)(nil)
+)
+
+// BoltGraph implements a persistent link graph that is backed by a
+// bbolt database file.
+type BoltGraph struct {
+	db    *bbolt.DB
+	canon *urlcanon.Canonicalizer
+
+	// When set, new links are assigned the ID returned by
+	// graph.LinkIDFromURL instead of a random ID.
+	deterministicIDs bool
+}
+
+// NewBoltGraph opens (or creates) the bbolt database at path and returns a
+// link graph backed by it.
+func NewBoltGraph(path string) (*BoltGraph, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{linksBucket, linkURLBucket, edgesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		// Databases created before the inbound edge and host indexes
+		// were introduced need to have the indexes populated from the
+		// existing edges and links.
+		if tx.Bucket(inboundEdgesBucket) == nil {
+			inbound, err := tx.CreateBucket(inboundEdgesBucket)
+			if err != nil {
+				return err
+			}
+			err = tx.Bucket(edgesBucket).ForEach(func(k, _ []byte) error {
+				return inbound.Put(reverseEdgeKey(k), nil)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if tx.Bucket(hostLinksBucket) == nil {
+			hosts, err := tx.CreateBucket(hostLinksBucket)
+			if err != nil {
+				return err
+			}
+			return tx.Bucket(linksBucket).ForEach(func(_, v []byte) error {
+				link, err := decodeLink(v)
+				if err != nil {
+					return err
+				}
+				return hosts.Put(hostLinkKey(urlcanon.Hostname(link.URL), link.ID), nil)
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &BoltGraph{db: db, canon: urlcanon.Default}, nil
+}
+
+// SetCanonicalizer configures the canonicalizer that is applied to link URLs
+// by UpsertLink and UpsertLinks. Passing a nil canonicalizer disables URL
+// canonicalization. It must be called before the graph is used.
+func (g *BoltGraph) SetCanonicalizer(canon *urlcanon.Canonicalizer) {
+	g.canon = canon
+}
+
+// SetDeterministicIDs configures whether new links are assigned an ID that
+// is derived from their canonical URL (see graph.LinkIDFromURL) instead of a
+// random ID. It must be called before the graph is used.
+func (g *BoltGraph) SetDeterministicIDs(enabled bool) {
+	g.deterministicIDs = enabled
+}
+
+// DeterministicIDs returns true if new links are assigned an ID that is
+// derived from their canonical URL.
+func (g *BoltGraph) DeterministicIDs() bool {
+	return g.deterministicIDs
+}
+
+// Close releases the lock on the underlying database file.
+func (g *BoltGraph) Close() error {
+	return g.db.Close()
+}
+
+// UpsertLink creates a new link or updates an existing link.
+func (g *BoltGraph) UpsertLink(link *graph.Link) error {
+	return g.UpsertLinkContext(context.Background(), link)
+}
+
+// UpsertLinkContext implements graph.Graph.
+func (g *BoltGraph) UpsertLinkContext(ctx context.Context, link *graph.Link) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("upsert link: %w", err)
+	}
+
+	canonicalURL, err := g.canon.Canonicalize(link.URL)
+	if err != nil {
+		return xerrors.Errorf("upsert link: %w", err)
+	}
+	link.URL = canonicalURL
+
+	err = g.db.Update(func(tx *bbolt.Tx) error {
+		return g.upsertLink(tx, link)
+	})
+	if err != nil {
+		return xerrors.Errorf("upsert link: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertLinks creates or updates a batch of links.
+func (g *BoltGraph) UpsertLinks(links []*graph.Link) error {
+	return g.UpsertLinksContext(context.Background(), links)
+}
+
+// UpsertLinksContext implements graph.Graph.
+func (g *BoltGraph) UpsertLinksContext(ctx context.Context, links []*graph.Link) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("upsert links: %w", err)
+	}
+
+	// Links whose URL cannot be canonicalized are reported individually
+	// and skipped.
+	errs := make([]error, len(links))
+	for i, link := range links {
+		canonicalURL, err := g.canon.Canonicalize(link.URL)
+		if err != nil {
+			errs[i] = xerrors.Errorf("upsert links: %w", err)
+			continue
+		}
+		link.URL = canonicalURL
+	}
+
+	err := g.db.Update(func(tx *bbolt.Tx) error {
+		for i, link := range links {
+			if errs[i] != nil {
+				continue
+			}
+			if err := g.upsertLink(tx, link); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("upsert links: %w", err)
+	}
+
+	return graph.NewBatchError(errs)
+}
+
+// upsertLink creates or updates link within tx and updates its ID,
+// RetrievedAt and FirstSeenAt fields. The link URL must already be in
+// canonical form.
+func (g *BoltGraph) upsertLink(tx *bbolt.Tx, link *graph.Link) error {
+	links, urls := tx.Bucket(linksBucket), tx.Bucket(linkURLBucket)
+	stored := *link
+	stored.RetrievedAt = stored.RetrievedAt.UTC()
+	stored.FirstSeenAt = stored.FirstSeenAt.UTC()
+
+	// Check if a link with the same URL already exists. If so, convert
+	// this into an update and point the link ID to the existing link.
+	if existingID := urls.Get([]byte(link.URL)); existingID != nil {
+		existing, err := decodeLink(links.Get(existingID))
+		if err != nil {
+			return err
+		}
+
+		// Unless the upserted link is more recent, the stored link
+		// (including its crawl metadata) is retained.
+		if !stored.RetrievedAt.After(existing.RetrievedAt) {
+			stored = *existing
+		}
+		stored.ID = existing.ID
+		stored.FirstSeenAt = existing.FirstSeenAt
+		if !link.FirstSeenAt.IsZero() && link.FirstSeenAt.Before(existing.FirstSeenAt) {
+			stored.FirstSeenAt = link.FirstSeenAt.UTC()
+		}
+	} else {
+		// Assign new ID and insert link
+		if g.deterministicIDs {
+			stored.ID = graph.LinkIDFromURL(stored.URL)
+		} else {
+			for {
+				stored.ID = uuid.New()
+				if links.Get(stored.ID[:]) == nil {
+					break
+				}
+			}
+		}
+		if stored.FirstSeenAt.IsZero() {
+			stored.FirstSeenAt = time.Now().UTC()
+		}
+
+		if err := urls.Put([]byte(stored.URL), stored.ID[:]); err != nil {
+			return err
+		}
+		if err := tx.Bucket(hostLinksBucket).Put(hostLinkKey(urlcanon.Hostname(stored.URL), stored.ID), nil); err != nil {
+			return err
+		}
+	}
+
+	if err := putLink(links, &stored); err != nil {
+		return err
+	}
+
+	link.ID, link.RetrievedAt, link.FirstSeenAt = stored.ID, stored.RetrievedAt, stored.FirstSeenAt
+	return nil
+}
+
+// FindLink looks up a link by its ID.
+func (g *BoltGraph) FindLink(id uuid.UUID) (*graph.Link, error) {
+	return g.FindLinkContext(context.Background(), id)
+}
+
+// FindLinkContext implements graph.Graph.
+func (g *BoltGraph) FindLinkContext(ctx context.Context, id uuid.UUID) (*graph.Link, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("find link: %w", err)
+	}
+
+	var link *graph.Link
+	err := g.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(linksBucket).Get(id[:])
+		if data == nil {
+			return graph.ErrNotFound
+		}
+
+		var err error
+		link, err = decodeLink(data)
+		return err
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("find link: %w", err)
+	}
+
+	return link, nil
+}
+
+// FindLinkByURL looks up a link by its URL.
+func (g *BoltGraph) FindLinkByURL(url string) (*graph.Link, error) {
+	return g.FindLinkByURLContext(context.Background(), url)
+}
+
+// FindLinkByURLContext implements graph.Graph.
+func (g *BoltGraph) FindLinkByURLContext(ctx context.Context, url string) (*graph.Link, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("find link by URL: %w", err)
+	}
+
+	canonicalURL, err := g.canon.Canonicalize(url)
+	if err != nil {
+		return nil, xerrors.Errorf("find link by URL: %w", err)
+	}
+
+	var link *graph.Link
+	err = g.db.View(func(tx *bbolt.Tx) error {
+		id := tx.Bucket(linkURLBucket).Get([]byte(canonicalURL))
+		if id == nil {
+			return graph.ErrNotFound
+		}
+
+		var err error
+		link, err = decodeLink(tx.Bucket(linksBucket).Get(id))
+		return err
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("find link by URL: %w", err)
+	}
+
+	return link, nil
+}
+
+// RemoveLink deletes the link with the specified ID together with all
+// edges that originate from or point to it.
+func (g *BoltGraph) RemoveLink(id uuid.UUID) error {
+	return g.RemoveLinkContext(context.Background(), id)
+}
+
+// RemoveLinkContext implements graph.Graph.
+func (g *BoltGraph) RemoveLinkContext(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("remove link: %w", err)
+	}
+
+	err := g.db.Update(func(tx *bbolt.Tx) error {
+		links, edges := tx.Bucket(linksBucket), tx.Bucket(edgesBucket)
+		data := links.Get(id[:])
+		if data == nil {
+			return graph.ErrNotFound
+		}
+
+		link, err := decodeLink(data)
+		if err != nil {
+			return err
+		}
+
+		// Outgoing edges share the link ID as their key prefix in the
+		// edges bucket while incoming edges share it as their key prefix
+		// in the inbound edges bucket.
+		var doomed [][]byte
+		c := edges.Cursor()
+		for k, _ := c.Seek(id[:]); k != nil && bytes.HasPrefix(k, id[:]); k, _ = c.Next() {
+			doomed = append(doomed, append([]byte(nil), k...))
+		}
+		c = tx.Bucket(inboundEdgesBucket).Cursor()
+		for k, _ := c.Seek(id[:]); k != nil && bytes.HasPrefix(k, id[:]); k, _ = c.Next() {
+			doomed = append(doomed, reverseEdgeKey(k))
+		}
+		for _, k := range doomed {
+			if err := deleteEdge(tx, k); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Bucket(linkURLBucket).Delete([]byte(link.URL)); err != nil {
+			return err
+		}
+		if err := tx.Bucket(hostLinksBucket).Delete(hostLinkKey(urlcanon.Hostname(link.URL), id)); err != nil {
+			return err
+		}
+		return links.Delete(id[:])
+	})
+	if err != nil {
+		return xerrors.Errorf("remove link: %w", err)
+	}
+
+	return nil
+}
+
+// Links returns an iterator for the set of links whose IDs belong to the
+// [fromID, toID) range and were retrieved before the provided timestamp.
+func (g *BoltGraph) Links(fromID, toID uuid.UUID, retrievedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	return g.LinksContext(context.Background(), fromID, toID, retrievedBefore, filters...)
+}
+
+// LinksContext implements graph.Graph.
+func (g *BoltGraph) LinksContext(ctx context.Context, fromID, toID uuid.UUID, retrievedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("links: %w", err)
+	}
+
+	return &linkIterator{
+		g:               g,
+		ctx:             ctx,
+		nextKey:         fromID[:],
+		toID:            toID,
+		retrievedBefore: retrievedBefore,
+		filters:         filters,
+	}, nil
+}
+
+// LinksByHost returns an iterator for the set of links whose URL refers to
+// the specified host.
+func (g *BoltGraph) LinksByHost(host string) (graph.LinkIterator, error) {
+	return g.LinksByHostContext(context.Background(), host)
+}
+
+// LinksByHostContext implements graph.Graph.
+func (g *BoltGraph) LinksByHostContext(ctx context.Context, host string) (graph.LinkIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("links by host: %w", err)
+	}
+
+	hostname, err := urlcanon.CanonicalHostname(host)
+	if err != nil {
+		return nil, xerrors.Errorf("links by host: %w", err)
+	}
+
+	prefix := append([]byte(hostname), 0)
+	return &hostLinkIterator{
+		g:       g,
+		ctx:     ctx,
+		prefix:  prefix,
+		nextKey: prefix,
+	}, nil
+}
+
+// UpsertEdge creates a new edge or updates an existing edge.
+func (g *BoltGraph) UpsertEdge(edge *graph.Edge) error {
+	return g.UpsertEdgeContext(context.Background(), edge)
+}
+
+// UpsertEdgeContext implements graph.Graph.
+func (g *BoltGraph) UpsertEdgeContext(ctx context.Context, edge *graph.Edge) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("upsert edge: %w", err)
+	}
+
+	err := g.db.Update(func(tx *bbolt.Tx) error {
+		return upsertEdge(tx, edge)
+	})
+	if err != nil {
+		return xerrors.Errorf("upsert edge: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertEdges creates or updates a batch of edges.
+func (g *BoltGraph) UpsertEdges(edges []*graph.Edge) error {
+	return g.UpsertEdgesContext(context.Background(), edges)
+}
+
+// UpsertEdgesContext implements graph.Graph.
+func (g *BoltGraph) UpsertEdgesContext(ctx context.Context, edges []*graph.Edge) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("upsert edges: %w", err)
+	}
+
+	errs := make([]error, len(edges))
+	err := g.db.Update(func(tx *bbolt.Tx) error {
+		for i, edge := range edges {
+			err := upsertEdge(tx, edge)
+			if xerrors.Is(err, graph.ErrUnknownEdgeLinks) {
+				// Only this edge is affected; keep going.
+				errs[i] = xerrors.Errorf("upsert edges: %w", err)
+			} else if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("upsert edges: %w", err)
+	}
+
+	return graph.NewBatchError(errs)
+}
+
+// upsertEdge creates or updates edge within tx and updates its ID,
+// timestamp and link count.
+func upsertEdge(tx *bbolt.Tx, edge *graph.Edge) error {
+	links, edges := tx.Bucket(linksBucket), tx.Bucket(edgesBucket)
+	if links.Get(edge.Src[:]) == nil || links.Get(edge.Dst[:]) == nil {
+		return graph.ErrUnknownEdgeLinks
+	}
+
+	key := edgeKey(edge.Src, edge.Dst)
+	if data := edges.Get(key); data != nil {
+		existing, err := decodeEdge(data)
+		if err != nil {
+			return err
+		}
+		edge.ID = existing.ID
+	} else {
+		edge.ID = uuid.New()
+	}
+
+	edge.UpdatedAt = time.Now().UTC()
+	if edge.LinkCount < 1 {
+		edge.LinkCount = 1
+	}
+	data, err := json.Marshal(edge)
+	if err != nil {
+		return err
+	}
+	if err = tx.Bucket(inboundEdgesBucket).Put(reverseEdgeKey(key), nil); err != nil {
+		return err
+	}
+	return edges.Put(key, data)
+}
+
+// Edges returns an iterator for the set of edges whose source vertex IDs
+// belong to the [fromID, toID) range and were updated before the provided
+// timestamp.
+func (g *BoltGraph) Edges(fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	return g.EdgesContext(context.Background(), fromID, toID, updatedBefore)
+}
+
+// EdgesContext implements graph.Graph.
+func (g *BoltGraph) EdgesContext(ctx context.Context, fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("edges: %w", err)
+	}
+
+	return &edgeIterator{
+		g:             g,
+		ctx:           ctx,
+		nextKey:       fromID[:],
+		toID:          toID,
+		updatedBefore: updatedBefore,
+	}, nil
+}
+
+// InboundEdges returns an iterator for the set of edges that point to the
+// specified link ID and were updated before the provided timestamp.
+func (g *BoltGraph) InboundEdges(dstID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	return g.InboundEdgesContext(context.Background(), dstID, updatedBefore)
+}
+
+// InboundEdgesContext implements graph.Graph.
+func (g *BoltGraph) InboundEdgesContext(ctx context.Context, dstID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("inbound edges: %w", err)
+	}
+
+	return &inboundEdgeIterator{
+		g:             g,
+		ctx:           ctx,
+		dstID:         dstID,
+		nextKey:       dstID[:],
+		updatedBefore: updatedBefore,
+	}, nil
+}
+
+// RemoveStaleEdges removes any edge that originates from the specified link ID
+// and was updated before the specified timestamp.
+func (g *BoltGraph) RemoveStaleEdges(fromID uuid.UUID, updatedBefore time.Time) error {
+	return g.RemoveStaleEdgesContext(context.Background(), fromID, updatedBefore)
+}
+
+// RemoveStaleEdgesContext implements graph.Graph.
+func (g *BoltGraph) RemoveStaleEdgesContext(ctx context.Context, fromID uuid.UUID, updatedBefore time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("remove stale edges: %w", err)
+	}
+
+	err := g.db.Update(func(tx *bbolt.Tx) error {
+		var (
+			edges = tx.Bucket(edgesBucket)
+			stale [][]byte
+		)
+
+		c := edges.Cursor()
+		for k, v := c.Seek(fromID[:]); k != nil && bytes.HasPrefix(k, fromID[:]); k, v = c.Next() {
+			edge, err := decodeEdge(v)
+			if err != nil {
+				return err
+			}
+			if edge.UpdatedAt.Before(updatedBefore) {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+		}
+
+		// Keys are deleted after the scan completes as mutating a bucket
+		// while a cursor is iterating it may cause entries to be skipped.
+		for _, k := range stale {
+			if err := deleteEdge(tx, k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("remove stale edges: %w", err)
+	}
+
+	return nil
+}
+
+// edgeKey returns the edgesBucket key for an edge between src and dst.
+func edgeKey(src, dst uuid.UUID) []byte {
+	key := make([]byte, 0, len(src)+len(dst))
+	key = append(key, src[:]...)
+	return append(key, dst[:]...)
+}
+
+// reverseEdgeKey converts an edgesBucket key into the matching
+// inboundEdgesBucket key and vice versa.
+func reverseEdgeKey(key []byte) []byte {
+	half := len(key) / 2
+	rev := make([]byte, 0, len(key))
+	rev = append(rev, key[half:]...)
+	return append(rev, key[:half]...)
+}
+
+// hostLinkKey returns the hostLinksBucket key for the link with the
+// specified ID and host name.
+func hostLinkKey(hostname string, id uuid.UUID) []byte {
+	key := make([]byte, 0, len(hostname)+1+len(id))
+	key = append(key, hostname...)
+	key = append(key, 0)
+	return append(key, id[:]...)
+}
+
+// deleteEdge removes the edge with the specified edgesBucket key together
+// with its inbound edge index entry.
+func deleteEdge(tx *bbolt.Tx, key []byte) error {
+	if err := tx.Bucket(inboundEdgesBucket).Delete(reverseEdgeKey(key)); err != nil {
+		return err
+	}
+	return tx.Bucket(edgesBucket).Delete(key)
+}
+
+func putLink(b *bbolt.Bucket, link *graph.Link) error {
+	lCopy := *link
+	lCopy.RetrievedAt = lCopy.RetrievedAt.UTC()
+	data, err := json.Marshal(&lCopy)
+	if err != nil {
+		return err
+	}
+	return b.Put(lCopy.ID[:], data)
+}
+
+func decodeLink(data []byte) (*graph.Link, error) {
+	link := new(graph.Link)
+	if err := json.Unmarshal(data, link); err != nil {
+		return nil, err
+	}
+	link.RetrievedAt = link.RetrievedAt.UTC()
+	return link, nil
+}
+
+func decodeEdge(data []byte) (*graph.Edge, error) {
+	edge := new(graph.Edge)
+	if err := json.Unmarshal(data, edge); err != nil {
+		return nil, err
+	}
+	edge.UpdatedAt = edge.UpdatedAt.UTC()
+	if edge.LinkCount < 1 {
+		// Edges stored before link counts were tracked.
+		edge.LinkCount = 1
+	}
+	return edge, nil
+}
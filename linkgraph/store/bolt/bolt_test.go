@@ -0,0 +1,144 @@
+package bolt
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	bbolt "go.etcd.io/bbolt"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(BoltGraphTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type BoltGraphTestSuite struct {
+	graphtest.SuiteBase
+	path string
+	g    *BoltGraph
+}
+
+func (s *BoltGraphTestSuite) SetUpTest(c *gc.C) {
+	s.path = filepath.Join(c.MkDir(), "linkgraph.db")
+	g, err := NewBoltGraph(s.path)
+	c.Assert(err, gc.IsNil)
+	s.SetGraph(g)
+	s.g = g
+}
+
+func (s *BoltGraphTestSuite) TearDownTest(c *gc.C) {
+	c.Assert(s.g.Close(), gc.IsNil)
+}
+
+// TestReopen verifies that links and edges survive closing and re-opening
+// the database file.
+func (s *BoltGraphTestSuite) TestReopen(c *gc.C) {
+	src := &graph.Link{URL: "https://example.com"}
+	dst := &graph.Link{URL: "https://example.com/about"}
+	c.Assert(s.g.UpsertLink(src), gc.IsNil)
+	c.Assert(s.g.UpsertLink(dst), gc.IsNil)
+	edge := &graph.Edge{Src: src.ID, Dst: dst.ID}
+	c.Assert(s.g.UpsertEdge(edge), gc.IsNil)
+
+	c.Assert(s.g.Close(), gc.IsNil)
+	g, err := NewBoltGraph(s.path)
+	c.Assert(err, gc.IsNil)
+	s.g = g
+
+	got, err := g.FindLink(src.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.URL, gc.Equals, src.URL)
+
+	// Re-inserting a known URL must resolve to the persisted ID.
+	again := &graph.Link{URL: dst.URL}
+	c.Assert(g.UpsertLink(again), gc.IsNil)
+	c.Assert(again.ID, gc.Equals, dst.ID)
+
+	// Re-inserting a known edge must resolve to the persisted ID.
+	edgeAgain := &graph.Edge{Src: src.ID, Dst: dst.ID}
+	c.Assert(g.UpsertEdge(edgeAgain), gc.IsNil)
+	c.Assert(edgeAgain.ID, gc.Equals, edge.ID)
+}
+
+// TestInboundEdgeIndexBackfill verifies that the inbound edge index is
+// rebuilt when opening a database that was created without it.
+func (s *BoltGraphTestSuite) TestInboundEdgeIndexBackfill(c *gc.C) {
+	src := &graph.Link{URL: "https://example.com"}
+	dst := &graph.Link{URL: "https://example.com/about"}
+	c.Assert(s.g.UpsertLinks([]*graph.Link{src, dst}), gc.IsNil)
+	edge := &graph.Edge{Src: src.ID, Dst: dst.ID}
+	c.Assert(s.g.UpsertEdge(edge), gc.IsNil)
+
+	// Simulate a database that predates the index.
+	err := s.g.db.Update(func(tx *bbolt.Tx) error {
+		return tx.DeleteBucket(inboundEdgesBucket)
+	})
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(s.g.Close(), gc.IsNil)
+	g, err := NewBoltGraph(s.path)
+	c.Assert(err, gc.IsNil)
+	s.g = g
+
+	it, err := g.InboundEdges(dst.ID, time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Edge().ID, gc.Equals, edge.ID)
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+}
+
+// TestHostIndexBackfill verifies that the host index is rebuilt when opening
+// a database that was created without it.
+func (s *BoltGraphTestSuite) TestHostIndexBackfill(c *gc.C) {
+	link := &graph.Link{URL: "https://example.com/about"}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+
+	// Simulate a database that predates the index.
+	err := s.g.db.Update(func(tx *bbolt.Tx) error {
+		return tx.DeleteBucket(hostLinksBucket)
+	})
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(s.g.Close(), gc.IsNil)
+	g, err := NewBoltGraph(s.path)
+	c.Assert(err, gc.IsNil)
+	s.g = g
+
+	it, err := g.LinksByHost("example.com")
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Link().ID, gc.Equals, link.ID)
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+}
+
+// TestDeterministicIDs verifies that new links are assigned the ID derived
+// from their canonical URL when deterministic IDs are enabled and that the
+// assigned IDs survive re-opening the database file.
+func (s *BoltGraphTestSuite) TestDeterministicIDs(c *gc.C) {
+	s.g.SetDeterministicIDs(true)
+
+	link := &graph.Link{URL: "HTTP://Example.com:80/a#frag"}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+	c.Assert(link.ID, gc.Equals, graph.LinkIDFromURL("http://example.com/a"))
+
+	links := []*graph.Link{{URL: "http://example.com/b"}, {URL: "http://example.com/a"}}
+	c.Assert(s.g.UpsertLinks(links), gc.IsNil)
+	c.Assert(links[0].ID, gc.Equals, graph.LinkIDFromURL("http://example.com/b"))
+	c.Assert(links[1].ID, gc.Equals, link.ID)
+
+	c.Assert(s.g.Close(), gc.IsNil)
+	g, err := NewBoltGraph(s.path)
+	c.Assert(err, gc.IsNil)
+	s.g = g
+
+	got, err := g.FindLinkByURL("http://example.com/b")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got.ID, gc.Equals, links[0].ID)
+}
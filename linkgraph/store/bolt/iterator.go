@@ -0,0 +1,366 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/google/uuid"
+	bbolt "go.etcd.io/bbolt"
+	"golang.org/x/xerrors"
+)
+
+// batchSize controls the number of items that iterators fetch from the
+// database in a single read transaction. Fetching in batches ensures that
+// iterators never keep a read transaction open while the caller is
+// processing results.
+const batchSize = 100
+
+// linkIterator is a graph.LinkIterator implementation for the bolt graph.
+type linkIterator struct {
+	g   *BoltGraph
+	ctx context.Context
+
+	nextKey         []byte
+	toID            uuid.UUID
+	retrievedBefore time.Time
+	filters         []graph.LinkFilter
+
+	batch    []*graph.Link
+	batchIdx int
+	done     bool
+
+	lastErr     error
+	latchedLink *graph.Link
+}
+
+// Next implements graph.LinkIterator.
+func (i *linkIterator) Next() bool {
+	if i.lastErr != nil {
+		return false
+	} else if err := i.ctx.Err(); err != nil {
+		i.lastErr = xerrors.Errorf("link iterator: %w", err)
+		return false
+	}
+
+	for i.lastErr == nil && i.batchIdx >= len(i.batch) {
+		if i.done {
+			return false
+		}
+		i.lastErr = i.fetchBatch()
+	}
+	if i.lastErr != nil {
+		return false
+	}
+
+	i.latchedLink = i.batch[i.batchIdx]
+	i.batchIdx++
+	return true
+}
+
+func (i *linkIterator) fetchBatch() error {
+	i.batch, i.batchIdx = i.batch[:0], 0
+	err := i.g.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(linksBucket).Cursor()
+		for k, v := c.Seek(i.nextKey); k != nil && bytes.Compare(k, i.toID[:]) < 0; k, v = c.Next() {
+			if len(i.batch) == batchSize {
+				i.nextKey = append([]byte(nil), k...)
+				return nil
+			}
+
+			link, err := decodeLink(v)
+			if err != nil {
+				return err
+			}
+			if link.RetrievedAt.Before(i.retrievedBefore) && graph.MatchesAny(link, i.filters) {
+				i.batch = append(i.batch, link)
+			}
+		}
+
+		i.done = true
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("link iterator: %w", err)
+	}
+	return nil
+}
+
+// Error implements graph.LinkIterator.
+func (i *linkIterator) Error() error {
+	return i.lastErr
+}
+
+// Close implements graph.LinkIterator.
+func (i *linkIterator) Close() error {
+	i.done = true
+	i.batch = nil
+	return nil
+}
+
+// Link implements graph.LinkIterator.
+func (i *linkIterator) Link() *graph.Link {
+	return i.latchedLink
+}
+
+// edgeIterator is a graph.EdgeIterator implementation for the bolt graph.
+type edgeIterator struct {
+	g   *BoltGraph
+	ctx context.Context
+
+	nextKey       []byte
+	toID          uuid.UUID
+	updatedBefore time.Time
+
+	batch    []*graph.Edge
+	batchIdx int
+	done     bool
+
+	lastErr     error
+	latchedEdge *graph.Edge
+}
+
+// Next implements graph.EdgeIterator.
+func (i *edgeIterator) Next() bool {
+	if i.lastErr != nil {
+		return false
+	} else if err := i.ctx.Err(); err != nil {
+		i.lastErr = xerrors.Errorf("edge iterator: %w", err)
+		return false
+	}
+
+	for i.lastErr == nil && i.batchIdx >= len(i.batch) {
+		if i.done {
+			return false
+		}
+		i.lastErr = i.fetchBatch()
+	}
+	if i.lastErr != nil {
+		return false
+	}
+
+	i.latchedEdge = i.batch[i.batchIdx]
+	i.batchIdx++
+	return true
+}
+
+func (i *edgeIterator) fetchBatch() error {
+	i.batch, i.batchIdx = i.batch[:0], 0
+	err := i.g.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(edgesBucket).Cursor()
+		// Edge keys are prefixed by the source link ID so we only need to
+		// compare the prefix against the upper bound of the range.
+		for k, v := c.Seek(i.nextKey); k != nil && bytes.Compare(k[:len(i.toID)], i.toID[:]) < 0; k, v = c.Next() {
+			if len(i.batch) == batchSize {
+				i.nextKey = append([]byte(nil), k...)
+				return nil
+			}
+
+			edge, err := decodeEdge(v)
+			if err != nil {
+				return err
+			}
+			if edge.UpdatedAt.Before(i.updatedBefore) {
+				i.batch = append(i.batch, edge)
+			}
+		}
+
+		i.done = true
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("edge iterator: %w", err)
+	}
+	return nil
+}
+
+// Error implements graph.EdgeIterator.
+func (i *edgeIterator) Error() error {
+	return i.lastErr
+}
+
+// Close implements graph.EdgeIterator.
+func (i *edgeIterator) Close() error {
+	i.done = true
+	i.batch = nil
+	return nil
+}
+
+// Edge implements graph.EdgeIterator.
+func (i *edgeIterator) Edge() *graph.Edge {
+	return i.latchedEdge
+}
+
+// inboundEdgeIterator is a graph.EdgeIterator implementation that visits the
+// edges pointing to a particular link.
+type inboundEdgeIterator struct {
+	g   *BoltGraph
+	ctx context.Context
+
+	dstID         uuid.UUID
+	nextKey       []byte
+	updatedBefore time.Time
+
+	batch    []*graph.Edge
+	batchIdx int
+	done     bool
+
+	lastErr     error
+	latchedEdge *graph.Edge
+}
+
+// Next implements graph.EdgeIterator.
+func (i *inboundEdgeIterator) Next() bool {
+	if i.lastErr != nil {
+		return false
+	} else if err := i.ctx.Err(); err != nil {
+		i.lastErr = xerrors.Errorf("inbound edge iterator: %w", err)
+		return false
+	}
+
+	for i.lastErr == nil && i.batchIdx >= len(i.batch) {
+		if i.done {
+			return false
+		}
+		i.lastErr = i.fetchBatch()
+	}
+	if i.lastErr != nil {
+		return false
+	}
+
+	i.latchedEdge = i.batch[i.batchIdx]
+	i.batchIdx++
+	return true
+}
+
+func (i *inboundEdgeIterator) fetchBatch() error {
+	i.batch, i.batchIdx = i.batch[:0], 0
+	err := i.g.db.View(func(tx *bbolt.Tx) error {
+		edges := tx.Bucket(edgesBucket)
+		c := tx.Bucket(inboundEdgesBucket).Cursor()
+		for k, _ := c.Seek(i.nextKey); k != nil && bytes.HasPrefix(k, i.dstID[:]); k, _ = c.Next() {
+			if len(i.batch) == batchSize {
+				i.nextKey = append([]byte(nil), k...)
+				return nil
+			}
+
+			edge, err := decodeEdge(edges.Get(reverseEdgeKey(k)))
+			if err != nil {
+				return err
+			}
+			if edge.UpdatedAt.Before(i.updatedBefore) {
+				i.batch = append(i.batch, edge)
+			}
+		}
+
+		i.done = true
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("inbound edge iterator: %w", err)
+	}
+	return nil
+}
+
+// Error implements graph.EdgeIterator.
+func (i *inboundEdgeIterator) Error() error {
+	return i.lastErr
+}
+
+// Close implements graph.EdgeIterator.
+func (i *inboundEdgeIterator) Close() error {
+	i.done = true
+	i.batch = nil
+	return nil
+}
+
+// Edge implements graph.EdgeIterator.
+func (i *inboundEdgeIterator) Edge() *graph.Edge {
+	return i.latchedEdge
+}
+
+// hostLinkIterator is a graph.LinkIterator implementation that visits the
+// links of a particular host.
+type hostLinkIterator struct {
+	g   *BoltGraph
+	ctx context.Context
+
+	prefix  []byte
+	nextKey []byte
+
+	batch    []*graph.Link
+	batchIdx int
+	done     bool
+
+	lastErr     error
+	latchedLink *graph.Link
+}
+
+// Next implements graph.LinkIterator.
+func (i *hostLinkIterator) Next() bool {
+	if i.lastErr != nil {
+		return false
+	} else if err := i.ctx.Err(); err != nil {
+		i.lastErr = xerrors.Errorf("host link iterator: %w", err)
+		return false
+	}
+
+	for i.lastErr == nil && i.batchIdx >= len(i.batch) {
+		if i.done {
+			return false
+		}
+		i.lastErr = i.fetchBatch()
+	}
+	if i.lastErr != nil {
+		return false
+	}
+
+	i.latchedLink = i.batch[i.batchIdx]
+	i.batchIdx++
+	return true
+}
+
+func (i *hostLinkIterator) fetchBatch() error {
+	i.batch, i.batchIdx = i.batch[:0], 0
+	err := i.g.db.View(func(tx *bbolt.Tx) error {
+		links := tx.Bucket(linksBucket)
+		c := tx.Bucket(hostLinksBucket).Cursor()
+		for k, _ := c.Seek(i.nextKey); k != nil && bytes.HasPrefix(k, i.prefix); k, _ = c.Next() {
+			if len(i.batch) == batchSize {
+				i.nextKey = append([]byte(nil), k...)
+				return nil
+			}
+
+			link, err := decodeLink(links.Get(k[len(i.prefix):]))
+			if err != nil {
+				return err
+			}
+			i.batch = append(i.batch, link)
+		}
+
+		i.done = true
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("host link iterator: %w", err)
+	}
+	return nil
+}
+
+// Error implements graph.LinkIterator.
+func (i *hostLinkIterator) Error() error {
+	return i.lastErr
+}
+
+// Close implements graph.LinkIterator.
+func (i *hostLinkIterator) Close() error {
+	i.done = true
+	i.batch = nil
+	return nil
+}
+
+// Link implements graph.LinkIterator.
+func (i *hostLinkIterator) Link() *graph.Link {
+	return i.latchedLink
+}
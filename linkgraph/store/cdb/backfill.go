@@ -0,0 +1,114 @@
+package cdb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/xerrors"
+)
+
+// defaultBackfillBatchSize is the number of links updated per statement by
+// BackfillHosts unless configured otherwise.
+const defaultBackfillBatchSize = 1000
+
+var (
+	// Links without a host are scanned in ID order via the links_host_idx
+	// index.
+	missingHostsQuery = "SELECT id, url FROM links WHERE host = '' AND id > $1 ORDER BY id LIMIT $2"
+
+	backfillHostsQuery = `
+UPDATE links SET host = backfill.host
+FROM (SELECT unnest($1::UUID[]) AS id, unnest($2::STRING[]) AS host) AS backfill
+WHERE links.id = backfill.id AND links.host = ''`
+)
+
+// BackfillHosts populates the host column of links that were stored before
+// the column was introduced and returns the number of updated links. Hosts
+// are derived with urlcanon.Hostname, just like for newly inserted links.
+//
+// Links are processed in batches of batchSize links, each of which is
+// updated by a separate statement so that the backfill does not run into
+// transaction size limits on large tables. Non-positive values select the
+// default batch size. The backfill can be safely interrupted and re-run.
+func (c *CockroachDBGraph) BackfillHosts(ctx context.Context, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = defaultBackfillBatchSize
+	}
+
+	var (
+		updated int
+		lastID  uuid.UUID
+	)
+	for {
+		ids, urls, err := c.missingHosts(ctx, lastID, batchSize)
+		if err != nil {
+			return updated, xerrors.Errorf("backfill hosts: %w", err)
+		} else if len(ids) == 0 {
+			return updated, nil
+		}
+		lastID = ids[len(ids)-1]
+
+		// Links whose URL has no host keep an empty host; as the scan
+		// resumes after lastID, they are not visited again.
+		var hostIDs, hosts []string
+		for i, url := range urls {
+			if host := urlcanon.Hostname(url); host != "" {
+				hostIDs = append(hostIDs, ids[i].String())
+				hosts = append(hosts, host)
+			}
+		}
+
+		if len(hosts) != 0 {
+			var res sql.Result
+			err = c.withRetries(ctx, isTransientError, func() error {
+				res, err = c.db.ExecContext(ctx, backfillHostsQuery, pq.Array(hostIDs), pq.Array(hosts))
+				return err
+			})
+			if err != nil {
+				return updated, xerrors.Errorf("backfill hosts: %w", err)
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return updated, xerrors.Errorf("backfill hosts: %w", err)
+			}
+			updated += int(n)
+		}
+
+		if len(ids) < batchSize {
+			return updated, nil
+		}
+	}
+}
+
+// missingHosts returns the IDs and URLs of up to limit links without a host
+// whose ID is greater than afterID.
+func (c *CockroachDBGraph) missingHosts(ctx context.Context, afterID uuid.UUID, limit int) ([]uuid.UUID, []string, error) {
+	rows, err := c.db.QueryContext(ctx, missingHostsQuery, afterID, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var (
+		ids  []uuid.UUID
+		urls []string
+	)
+	for rows.Next() {
+		var (
+			id  uuid.UUID
+			url string
+		)
+		if err = rows.Scan(&id, &url); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		urls = append(urls, url)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return ids, urls, rows.Close()
+}
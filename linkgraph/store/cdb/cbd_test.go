@@ -1,18 +1,20 @@
 package cdb
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"os"
+	"sort"
 	"testing"
+	"time"
 
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
 	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
 	gc "gopkg.in/check.v1"
-
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
 var _ = gc.Suite(new(CockroachDbGraphTestSuite))
@@ -21,7 +23,9 @@ func Test(t *testing.T) { gc.TestingT(t) }
 
 type CockroachDbGraphTestSuite struct {
 	graphtest.SuiteBase
-	db *sql.DB
+	db  *sql.DB
+	g   *CockroachDBGraph
+	dsn string
 }
 
 func (s *CockroachDbGraphTestSuite) SetUpSuite(c *gc.C) {
@@ -30,26 +34,14 @@ func (s *CockroachDbGraphTestSuite) SetUpSuite(c *gc.C) {
 		c.Skip("Missing CDB_DSN envvar; skipping cockroachdb-backed graph test suite")
 	}
 
-	//migrating
-	path, err := os.Getwd()
-	if err != nil {
-    	log.Println(err)
-	}
-	fmt.Println("Current working directory: %w", path)
-
-
-	m, err := migrate.New("file://migrations", dsn)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if err := m.Up(); err != nil {
-		log.Fatal(err)
-	}
+	c.Assert(Migrate(dsn, LatestSchemaVersion), gc.IsNil)
 
-	g, err := NewCockroachDbGraph(dsn)
+	g, err := NewCockroachDbGraphWithOptions(dsn, Options{VerifySchemaVersion: true})
 	c.Assert(err, gc.IsNil)
 	s.SetGraph(g)
 	s.db = g.db
+	s.g = g
+	s.dsn = dsn
 }
 
 func (s *CockroachDbGraphTestSuite) SetUpTest(c *gc.C) {
@@ -69,3 +61,178 @@ func (s *CockroachDbGraphTestSuite) flushDB(c *gc.C) {
 	_, err = s.db.Exec("DELETE FROM edges")
 	c.Assert(err, gc.IsNil)
 }
+
+// TestDeterministicIDs verifies that new links are assigned the ID derived
+// from their canonical URL when deterministic IDs are enabled.
+func (s *CockroachDbGraphTestSuite) TestDeterministicIDs(c *gc.C) {
+	s.g.SetDeterministicIDs(true)
+	defer s.g.SetDeterministicIDs(false)
+
+	link := &graph.Link{URL: "HTTP://Example.com:80/a#frag"}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+	c.Assert(link.ID, gc.Equals, graph.LinkIDFromURL("http://example.com/a"))
+
+	links := []*graph.Link{{URL: "http://example.com/b"}, {URL: "http://example.com/a"}}
+	c.Assert(s.g.UpsertLinks(links), gc.IsNil)
+	c.Assert(links[0].ID, gc.Equals, graph.LinkIDFromURL("http://example.com/b"))
+	c.Assert(links[1].ID, gc.Equals, link.ID)
+}
+
+// TestMigrate verifies that the schema can be rolled back and forward and
+// that the schema version check detects outdated schemas.
+func (s *CockroachDbGraphTestSuite) TestMigrate(c *gc.C) {
+	version, dirty, err := SchemaVersion(s.dsn)
+	c.Assert(err, gc.IsNil)
+	c.Assert(version, gc.Equals, LatestSchemaVersion)
+	c.Assert(dirty, gc.Equals, false)
+
+	c.Assert(Migrate(s.dsn, LatestSchemaVersion-1), gc.IsNil)
+	defer func() { c.Assert(Migrate(s.dsn, LatestSchemaVersion), gc.IsNil) }()
+
+	version, _, err = SchemaVersion(s.dsn)
+	c.Assert(err, gc.IsNil)
+	c.Assert(version, gc.Equals, LatestSchemaVersion-1)
+
+	_, err = NewCockroachDbGraphWithOptions(s.dsn, Options{VerifySchemaVersion: true})
+	c.Assert(xerrors.Is(err, ErrSchemaVersion), gc.Equals, true, gc.Commentf("%v", err))
+
+	err = Migrate(s.dsn, LatestSchemaVersion+1)
+	c.Assert(err, gc.NotNil)
+}
+
+// TestBackfillHosts verifies that the hosts of links stored before the host
+// column was introduced are derived in the same way as for new links.
+func (s *CockroachDbGraphTestSuite) TestBackfillHosts(c *gc.C) {
+	urls := []string{"http://bücher.example./a", "https://Example.com:8443/b", "http://[::1]/c"}
+	for _, u := range urls {
+		c.Assert(s.g.UpsertLink(&graph.Link{URL: u}), gc.IsNil)
+	}
+	_, err := s.db.Exec("UPDATE links SET host = ''")
+	c.Assert(err, gc.IsNil)
+
+	updated, err := s.g.BackfillHosts(context.TODO(), 2)
+	c.Assert(err, gc.IsNil)
+	c.Assert(updated, gc.Equals, 3)
+
+	for _, host := range []string{"xn--bcher-kva.example", "example.com", "::1"} {
+		it, err := s.g.LinksByHost(host)
+		c.Assert(err, gc.IsNil)
+		c.Assert(it.Next(), gc.Equals, true, gc.Commentf("host %q", host))
+		c.Assert(it.Close(), gc.IsNil)
+	}
+
+	// Re-running the backfill is a no-op.
+	updated, err = s.g.BackfillHosts(context.TODO(), 2)
+	c.Assert(err, gc.IsNil)
+	c.Assert(updated, gc.Equals, 0)
+}
+
+// TestPagination verifies that link and edge iterators fetch results in
+// pages and that an iteration can be resumed from the iterator cursor.
+func (s *CockroachDbGraphTestSuite) TestPagination(c *gc.C) {
+	s.g.SetPageSize(2)
+	defer s.g.SetPageSize(0)
+
+	var ids []uuid.UUID
+	for i := 0; i < 5; i++ {
+		link := &graph.Link{URL: fmt.Sprintf("http://example.com/%d", i)}
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+		ids = append(ids, link.ID)
+	}
+	for _, src := range ids[:3] {
+		for _, dst := range ids {
+			c.Assert(s.g.UpsertEdge(&graph.Edge{Src: src, Dst: dst}), gc.IsNil)
+		}
+	}
+
+	now := time.Now()
+	linkIt, err := s.g.Links(partition.MinUUID, partition.MaxUUID, now)
+	c.Assert(err, gc.IsNil)
+	var seen []uuid.UUID
+	for len(seen) < 3 && linkIt.Next() {
+		seen = append(seen, linkIt.Link().ID)
+	}
+	cursor := linkIt.(Resumable).Cursor()
+	c.Assert(cursor, gc.Equals, Cursor(seen[2].String()))
+	c.Assert(linkIt.Close(), gc.IsNil)
+
+	linkIt, err = s.g.LinksFromCursor(context.TODO(), cursor, partition.MinUUID, partition.MaxUUID, now)
+	c.Assert(err, gc.IsNil)
+	for linkIt.Next() {
+		seen = append(seen, linkIt.Link().ID)
+	}
+	c.Assert(linkIt.Error(), gc.IsNil)
+	c.Assert(linkIt.Close(), gc.IsNil)
+	c.Assert(seen, gc.HasLen, len(ids))
+	c.Assert(sort.SliceIsSorted(seen, func(i, j int) bool { return seen[i].String() < seen[j].String() }), gc.Equals, true)
+
+	edgeIt, err := s.g.Edges(partition.MinUUID, partition.MaxUUID, time.Now())
+	c.Assert(err, gc.IsNil)
+	var numEdges int
+	for numEdges < 7 && edgeIt.Next() {
+		numEdges++
+	}
+	cursor = edgeIt.(Resumable).Cursor()
+	c.Assert(edgeIt.Close(), gc.IsNil)
+
+	edgeIt, err = s.g.EdgesFromCursor(context.TODO(), cursor, partition.MinUUID, partition.MaxUUID, time.Now())
+	c.Assert(err, gc.IsNil)
+	for edgeIt.Next() {
+		numEdges++
+	}
+	c.Assert(edgeIt.Error(), gc.IsNil)
+	c.Assert(edgeIt.Close(), gc.IsNil)
+	c.Assert(numEdges, gc.Equals, 15)
+
+	_, err = s.g.EdgesFromCursor(context.TODO(), "bogus", partition.MinUUID, partition.MaxUUID, time.Now())
+	c.Assert(xerrors.Is(err, ErrInvalidCursor), gc.Equals, true)
+
+	// Changing the page size does not affect iterators that are already in
+	// progress.
+	linkIt, err = s.g.Links(partition.MinUUID, partition.MaxUUID, now)
+	c.Assert(err, gc.IsNil)
+	s.g.SetPageSize(1)
+	var numLinks int
+	for linkIt.Next() {
+		numLinks++
+	}
+	c.Assert(linkIt.Error(), gc.IsNil)
+	c.Assert(linkIt.Close(), gc.IsNil)
+	c.Assert(numLinks, gc.Equals, len(ids))
+}
+
+// TestTrimEvents verifies that trimmed events are removed from the outbox
+// table and that watchers cannot resume from a cursor preceding them.
+func (s *CockroachDbGraphTestSuite) TestTrimEvents(c *gc.C) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+
+	staleCursor, err := s.g.LastEventCursor(ctx)
+	c.Assert(err, gc.IsNil)
+	for i := 0; i < 3; i++ {
+		c.Assert(s.g.UpsertLink(&graph.Link{URL: fmt.Sprintf("http://example.com/%d", i)}), gc.IsNil)
+	}
+
+	removed, err := s.g.TrimEvents(ctx, time.Now())
+	c.Assert(err, gc.IsNil)
+	c.Assert(removed >= 3, gc.Equals, true, gc.Commentf("removed %d events", removed))
+
+	var remaining int
+	c.Assert(s.db.QueryRow("SELECT COUNT(*) FROM graph_events").Scan(&remaining), gc.IsNil)
+	c.Assert(remaining, gc.Equals, 0)
+
+	_, err = s.g.Watch(ctx, staleCursor)
+	c.Assert(xerrors.Is(err, graph.ErrEventsUnavailable), gc.Equals, true, gc.Commentf("%v", err))
+
+	// Watchers can resume from the last event cursor once all events have
+	// been trimmed.
+	cursor, err := s.g.LastEventCursor(ctx)
+	c.Assert(err, gc.IsNil)
+	it, err := s.g.Watch(ctx, cursor)
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(s.g.UpsertLink(&graph.Link{URL: "http://example.com/3"}), gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true, gc.Commentf("%v", it.Error()))
+	c.Assert(it.Event().Link.URL, gc.Equals, "http://example.com/3")
+	c.Assert(it.Close(), gc.IsNil)
+}
@@ -1,31 +1,96 @@
 package cdb
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"golang.org/x/xerrors"
 )
 
 var (
-	upsertLinkQuery = `
-	INSERT INTO links (url, retrieved_at) VALUES ($1, $2)
-ON CONFLICT (url) DO UPDATE SET retrieved_at=GREATEST(links.retrieved_at, $2)
-RETURNING id, retrieved_at
-	`
-	findLinkQuery         = "SELECT url, retrieved_at FROM links WHERE id=$1"
-	linksInPartitionQuery = "SELECT id, url, retrieved_at FROM links WHERE id >= $1 AND id < $2 AND retrieved_at < $3"
-
-	upsertEdgeQuery = `
-INSERT INTO edges (src, dst, updated_at) VALUES ($1, $2, NOW())
-ON CONFLICT (src,dst) DO UPDATE SET updated_at=NOW()
-RETURNING id, updated_at
+	// upsertLinkConflictClause only replaces the crawl metadata of an
+	// existing link if the upserted link was retrieved more recently.
+	upsertLinkConflictClause = `
+ON CONFLICT (url) DO UPDATE SET
+	status_code=CASE WHEN excluded.retrieved_at > links.retrieved_at THEN excluded.status_code ELSE links.status_code END,
+	content_type=CASE WHEN excluded.retrieved_at > links.retrieved_at THEN excluded.content_type ELSE links.content_type END,
+	content_hash=CASE WHEN excluded.retrieved_at > links.retrieved_at THEN excluded.content_hash ELSE links.content_hash END,
+	fetch_errors=CASE WHEN excluded.retrieved_at > links.retrieved_at THEN excluded.fetch_errors ELSE links.fetch_errors END,
+	last_error=CASE WHEN excluded.retrieved_at > links.retrieved_at THEN excluded.last_error ELSE links.last_error END,
+	first_seen_at=LEAST(links.first_seen_at, excluded.first_seen_at),
+	retrieved_at=GREATEST(links.retrieved_at, excluded.retrieved_at)
+`
+	// The link and edge mutations are wrapped in a common table expression
+	// that records an event for each affected row in the graph_events
+	// outbox table as part of the same statement (see logEventsCTE). The
+	// first argument of these statements is the ID of the request.
+	linkEventColumns = "id, url, retrieved_at, first_seen_at, status_code, content_type, content_hash, fetch_errors, last_error"
+	logUpsertedLinks = logEventsCTE(graph.EventLinkUpserted, "upserted", linkEventColumns, "link_id, url, retrieved_at, first_seen_at, status_code, content_type, content_hash, fetch_errors, last_error")
+	upsertLinkQuery  = `
+WITH upserted AS (
+INSERT INTO links (id, url, host, retrieved_at, first_seen_at, status_code, content_type, content_hash, fetch_errors, last_error)
+VALUES ($2, $3, $4, $5, $6, $7, $8, $9, $10, $11)` + upsertLinkConflictClause + `
+RETURNING ` + linkEventColumns + `
+)` + logUpsertedLinks + `
+SELECT id, retrieved_at, first_seen_at FROM upserted
+`
+	upsertLinksQueryPrefix = "WITH upserted AS (INSERT INTO links (id, url, host, retrieved_at, first_seen_at, status_code, content_type, content_hash, fetch_errors, last_error) VALUES "
+	upsertLinksQuerySuffix = upsertLinkConflictClause + `
+RETURNING ` + linkEventColumns + `
+)` + logUpsertedLinks + `
+SELECT id, url, retrieved_at, first_seen_at FROM upserted
+`
+	findLinkQuery         = "SELECT url, retrieved_at, first_seen_at, status_code, content_type, content_hash, fetch_errors, last_error FROM links WHERE id=$1"
+	findLinkByURLQuery    = "SELECT id, url, retrieved_at, first_seen_at, status_code, content_type, content_hash, fetch_errors, last_error FROM links WHERE url=$1"
+	removeLinkQuery       = "WITH removed AS (DELETE FROM links WHERE id=$2 RETURNING id)" + logEventsCTE(graph.EventLinkRemoved, "removed", "id", "link_id") + "SELECT id FROM removed"
+	linksInPartitionQuery = "SELECT id, url, retrieved_at, first_seen_at, status_code, content_type, content_hash, fetch_errors, last_error FROM links WHERE id >= $1 AND id < $2 AND retrieved_at < $3"
+	linksByHostQuery      = "SELECT id, url, retrieved_at, first_seen_at, status_code, content_type, content_hash, fetch_errors, last_error FROM links WHERE host=$1"
+
+	upsertEdgeConflictClause = `
+ON CONFLICT (src,dst) DO UPDATE SET
+	updated_at=NOW(),
+	anchor_text=excluded.anchor_text,
+	nofollow=excluded.nofollow,
+	sponsored=excluded.sponsored,
+	ugc=excluded.ugc,
+	link_count=excluded.link_count
+`
+	edgeEventColumns = "id, src, dst, updated_at, anchor_text, nofollow, sponsored, ugc, link_count"
+	logUpsertedEdges = logEventsCTE(graph.EventEdgeUpserted, "upserted", edgeEventColumns, "edge_id, src, dst, updated_at, anchor_text, nofollow, sponsored, ugc, link_count")
+	upsertEdgeQuery  = `
+WITH upserted AS (
+INSERT INTO edges (src, dst, anchor_text, nofollow, sponsored, ugc, link_count, updated_at)
+VALUES ($2, $3, $4, $5, $6, $7, $8, NOW())` + upsertEdgeConflictClause + `
+RETURNING ` + edgeEventColumns + `
+)` + logUpsertedEdges + `
+SELECT id, updated_at FROM upserted
+`
+	upsertEdgesQueryPrefix = "WITH upserted AS (INSERT INTO edges (src, dst, anchor_text, nofollow, sponsored, ugc, link_count, updated_at) VALUES "
+	upsertEdgesQuerySuffix = upsertEdgeConflictClause + `
+RETURNING ` + edgeEventColumns + `
+)` + logUpsertedEdges + `
+SELECT id, src, dst, updated_at FROM upserted
+`
+	existingLinksQuery    = "SELECT id FROM links WHERE id = ANY($1::UUID[])"
+	edgesInPartitionQuery = "SELECT id, src, dst, updated_at, anchor_text, nofollow, sponsored, ugc, link_count FROM edges WHERE src >= $1 AND src < $2 AND updated_at < $3"
+	inboundEdgesQuery     = "SELECT id, src, dst, updated_at, anchor_text, nofollow, sponsored, ugc, link_count FROM edges WHERE dst = $1 AND updated_at < $2"
+	removeStaleEdgesQuery = `
+WITH removed AS (DELETE FROM edges WHERE src=$2 AND updated_at < $3 RETURNING id),
+stale AS (SELECT $2::UUID AS id, $3::TIMESTAMP AS updated_before)` + logEventsCTE(graph.EventStaleEdgesRemoved, "stale", "id, updated_before", "link_id, updated_before") + `
+SELECT count(*) FROM removed
 `
-	edgesInPartitionQuery = "SELECT id, src, dst, updated_at FROM edges WHERE src >= $1 AND src < $2 AND updated_at < $3"
-	removeStaleEdgesQuery = "DELETE FROM edges WHERE src=$1 AND updated_at < $2"
+
+	// likePrefixEscaper escapes the LIKE wildcards in content type
+	// prefixes.
+	likePrefixEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
 
 	// Compile-time check for ensuring CockroachDbGraph implements Graph.
 	_ graph.Graph = (*CockroachDBGraph)(nil)
@@ -33,39 +98,273 @@ RETURNING id, updated_at
 
 // Stores the connection to the db
 type CockroachDBGraph struct {
-	db *sql.DB
+	db    *sql.DB
+	canon *urlcanon.Canonicalizer
+
+	// When set, new links are assigned the ID returned by
+	// graph.LinkIDFromURL instead of a random ID.
+	deterministicIDs bool
+
+	// The number of rows fetched per page by link and edge iterators.
+	pageSize int
+
+	// The policy for retrying operations that fail with a transient error.
+	retryPolicy   RetryPolicy
+	retryCounters retryCounters
+
+	// Used to stop the background trimming of old events.
+	trimStopCh chan struct{}
+	trimDoneCh chan struct{}
+}
+
+// Options configures the graph returned by NewCockroachDbGraphWithOptions.
+type Options struct {
+	// When set, the schema version of the database is checked against
+	// LatestSchemaVersion before the graph is returned. A mismatch results
+	// in ErrSchemaVersion.
+	VerifySchemaVersion bool
+
+	// The age after which events are trimmed from the graph_events outbox
+	// table by a background task (see TrimEvents). Defaults to 7 days;
+	// negative values disable the background task.
+	EventRetention time.Duration
 }
 
 // Creates the connection to the database
 func NewCockroachDbGraph(dsn string) (*CockroachDBGraph, error){
+	return NewCockroachDbGraphWithOptions(dsn, Options{})
+}
+
+// NewCockroachDbGraphWithOptions creates the connection to the database
+// using the specified options.
+func NewCockroachDbGraphWithOptions(dsn string, opts Options) (*CockroachDBGraph, error) {
+	if opts.VerifySchemaVersion {
+		if err := verifySchemaVersion(dsn); err != nil {
+			return nil, err
+		}
+	}
+
 	db, err := sql.Open("postgres", dsn)
 
 	if err != nil {
 		return nil, err
 	}
-	return &CockroachDBGraph{db:db}, nil
+	g := &CockroachDBGraph{db: db, canon: urlcanon.Default, pageSize: defaultPageSize, retryPolicy: DefaultRetryPolicy}
+
+	retention := opts.EventRetention
+	if retention == 0 {
+		retention = defaultEventRetention
+	}
+	if retention > 0 {
+		g.trimStopCh, g.trimDoneCh = make(chan struct{}), make(chan struct{})
+		go g.trimEventsLoop(retention, g.trimStopCh, g.trimDoneCh)
+	}
+	return g, nil
+}
+
+// SetCanonicalizer configures the canonicalizer that is applied to link URLs
+// by UpsertLink and UpsertLinks. Passing a nil canonicalizer disables URL
+// canonicalization. It must be called before the graph is used.
+func (c *CockroachDBGraph) SetCanonicalizer(canon *urlcanon.Canonicalizer) {
+	c.canon = canon
+}
+
+// SetDeterministicIDs configures whether new links are assigned an ID that
+// is derived from their canonical URL (see graph.LinkIDFromURL) instead of a
+// random ID. It must be called before the graph is used.
+func (c *CockroachDBGraph) SetDeterministicIDs(enabled bool) {
+	c.deterministicIDs = enabled
+}
+
+// DeterministicIDs returns true if new links are assigned an ID that is
+// derived from their canonical URL.
+func (c *CockroachDBGraph) DeterministicIDs() bool {
+	return c.deterministicIDs
+}
+
+// SetPageSize configures the number of rows that link and edge iterators
+// fetch with a single query. Non-positive values select the default page
+// size. It must be called before the graph is used.
+func (c *CockroachDBGraph) SetPageSize(n int) {
+	if n <= 0 {
+		n = defaultPageSize
+	}
+	c.pageSize = n
 }
 
 // Terminates the database connection
 func (c *CockroachDBGraph) Close() error {
+	if c.trimStopCh != nil {
+		close(c.trimStopCh)
+		<-c.trimDoneCh
+		c.trimStopCh = nil
+	}
 	return c.db.Close()
 }
 
 // Creates or Updates link
 func (c *CockroachDBGraph) UpsertLink(link *graph.Link) error {
-	row := c.db.QueryRow(upsertLinkQuery, link.URL, link.RetrievedAt.UTC())
-	if err := row.Scan(&link.ID, &link.RetrievedAt); err != nil {
+	return c.UpsertLinkContext(context.Background(), link)
+}
+
+// UpsertLinkContext implements graph.Graph.
+func (c *CockroachDBGraph) UpsertLinkContext(ctx context.Context, link *graph.Link) error {
+	canonicalURL, err := c.canon.Canonicalize(link.URL)
+	if err != nil {
+		return xerrors.Errorf("upsert link: %w", err)
+	}
+
+	args := append([]interface{}{uuid.New()}, c.linkColumnArgs(canonicalURL, link, time.Now())...)
+	err = c.withRetries(ctx, isTransientError, func() error {
+		row := c.db.QueryRowContext(ctx, upsertLinkQuery, args...)
+		return row.Scan(&link.ID, &link.RetrievedAt, &link.FirstSeenAt)
+	})
+	if err != nil {
 		return xerrors.Errorf("upsert link:%w", err)
 	}
 
+	link.URL = canonicalURL
 	link.RetrievedAt = link.RetrievedAt.UTC()
+	link.FirstSeenAt = link.FirstSeenAt.UTC()
 	return nil
 }
 
+// UpsertLinks creates or updates a batch of links using multi-row INSERT
+// statements.
+func (c *CockroachDBGraph) UpsertLinks(links []*graph.Link) error {
+	return c.UpsertLinksContext(context.Background(), links)
+}
+
+// UpsertLinksContext implements graph.Graph.
+func (c *CockroachDBGraph) UpsertLinksContext(ctx context.Context, links []*graph.Link) error {
+	// A single INSERT ... ON CONFLICT statement cannot update the same row
+	// twice so links that share a URL are collapsed into a single row that
+	// carries the most recently retrieved copy of the link and the
+	// earliest first-seen timestamp.
+	var (
+		errs       = make([]error, len(links))
+		urls       []string
+		rowsByURL  = make(map[string]*graph.Link)
+		linksByURL = make(map[string][]*graph.Link)
+	)
+	for i, link := range links {
+		canonicalURL, err := c.canon.Canonicalize(link.URL)
+		if err != nil {
+			errs[i] = xerrors.Errorf("upsert links: %w", err)
+			continue
+		}
+		link.URL = canonicalURL
+
+		row, seen := rowsByURL[link.URL]
+		if !seen {
+			urls = append(urls, link.URL)
+			row = new(graph.Link)
+			*row = *link
+			rowsByURL[link.URL] = row
+		} else if link.RetrievedAt.After(row.RetrievedAt) {
+			firstSeenAt := row.FirstSeenAt
+			*row = *link
+			row.FirstSeenAt = firstSeenAt
+		}
+		if !link.FirstSeenAt.IsZero() && (row.FirstSeenAt.IsZero() || link.FirstSeenAt.Before(row.FirstSeenAt)) {
+			row.FirstSeenAt = link.FirstSeenAt
+		}
+		linksByURL[link.URL] = append(linksByURL[link.URL], link)
+	}
+
+	now := time.Now()
+	for len(urls) != 0 {
+		n := len(urls)
+		if n > maxRowsPerInsert {
+			n = maxRowsPerInsert
+		}
+
+		args := make([]interface{}, 0, 1+numLinkColumns*n)
+		args = append(args, uuid.New())
+		for _, url := range urls[:n] {
+			args = append(args, c.linkColumnArgs(url, rowsByURL[url], now)...)
+		}
+
+		rows, err := c.db.QueryContext(ctx, multiRowQuery(upsertLinksQueryPrefix, upsertLinksQuerySuffix, n, numLinkColumns), args...)
+		if err != nil {
+			return xerrors.Errorf("upsert links: %w", err)
+		}
+
+		for rows.Next() {
+			var (
+				id                    uuid.UUID
+				url                   string
+				retrievedAt, firstSeen time.Time
+			)
+			if err = rows.Scan(&id, &url, &retrievedAt, &firstSeen); err != nil {
+				_ = rows.Close()
+				return xerrors.Errorf("upsert links: %w", err)
+			}
+
+			for _, link := range linksByURL[url] {
+				link.ID = id
+				link.RetrievedAt = retrievedAt.UTC()
+				link.FirstSeenAt = firstSeen.UTC()
+			}
+		}
+		if err = rows.Err(); err != nil {
+			_ = rows.Close()
+			return xerrors.Errorf("upsert links: %w", err)
+		}
+		if err = rows.Close(); err != nil {
+			return xerrors.Errorf("upsert links: %w", err)
+		}
+
+		urls = urls[n:]
+	}
+
+	return graph.NewBatchError(errs)
+}
+
+// numLinkColumns is the number of columns populated when inserting a link.
+const numLinkColumns = 10
+
+// linkColumnArgs returns the query arguments for all inserted link columns.
+// Links without a first-seen timestamp are assumed to be first seen at now.
+// The ID argument is only used if the link does not exist yet.
+func (c *CockroachDBGraph) linkColumnArgs(canonicalURL string, link *graph.Link, now time.Time) []interface{} {
+	id := uuid.New()
+	if c.deterministicIDs {
+		id = graph.LinkIDFromURL(canonicalURL)
+	}
+
+	firstSeenAt := link.FirstSeenAt
+	if firstSeenAt.IsZero() {
+		firstSeenAt = now
+	}
+
+	return []interface{}{
+		id,
+		canonicalURL,
+		urlcanon.Hostname(canonicalURL),
+		link.RetrievedAt.UTC(),
+		firstSeenAt.UTC(),
+		link.StatusCode,
+		link.ContentType,
+		link.ContentHash,
+		link.FetchErrors,
+		link.LastError,
+	}
+}
+
 func (c *CockroachDBGraph) FindLink(id uuid.UUID) (*graph.Link, error) {
-	row := c.db.QueryRow(findLinkQuery,id)
+	return c.FindLinkContext(context.Background(), id)
+}
+
+// FindLinkContext implements graph.Graph.
+func (c *CockroachDBGraph) FindLinkContext(ctx context.Context, id uuid.UUID) (*graph.Link, error) {
 	link := &graph.Link{ID: id}
-	if err := row.Scan(&link.URL, &link.RetrievedAt); err != nil {
+	err := c.withRetries(ctx, isTransientError, func() error {
+		row := c.db.QueryRowContext(ctx, findLinkQuery, id)
+		return row.Scan(&link.URL, &link.RetrievedAt, &link.FirstSeenAt, &link.StatusCode,
+			&link.ContentType, &link.ContentHash, &link.FetchErrors, &link.LastError)
+	})
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, xerrors.Errorf("find link: %w", graph.ErrNotFound)
 		}
@@ -73,20 +372,158 @@ func (c *CockroachDBGraph) FindLink(id uuid.UUID) (*graph.Link, error) {
 	}
 
 	link.RetrievedAt = link.RetrievedAt.UTC()
+	link.FirstSeenAt = link.FirstSeenAt.UTC()
+	return link, nil
+}
+
+
+// FindLinkByURL looks up a link by its URL.
+func (c *CockroachDBGraph) FindLinkByURL(url string) (*graph.Link, error) {
+	return c.FindLinkByURLContext(context.Background(), url)
+}
+
+// FindLinkByURLContext implements graph.Graph.
+func (c *CockroachDBGraph) FindLinkByURLContext(ctx context.Context, url string) (*graph.Link, error) {
+	canonicalURL, err := c.canon.Canonicalize(url)
+	if err != nil {
+		return nil, xerrors.Errorf("find link by URL: %w", err)
+	}
+
+	row := c.db.QueryRowContext(ctx, findLinkByURLQuery, canonicalURL)
+	link := new(graph.Link)
+	err = row.Scan(&link.ID, &link.URL, &link.RetrievedAt, &link.FirstSeenAt, &link.StatusCode,
+		&link.ContentType, &link.ContentHash, &link.FetchErrors, &link.LastError)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, xerrors.Errorf("find link by URL: %w", graph.ErrNotFound)
+		}
+		return nil, xerrors.Errorf("find link by URL: %w", err)
+	}
+
+	link.RetrievedAt = link.RetrievedAt.UTC()
+	link.FirstSeenAt = link.FirstSeenAt.UTC()
 	return link, nil
 }
 
+// RemoveLink deletes the link with the specified ID. Edges that originate
+// from or point to the link are removed by the ON DELETE CASCADE foreign
+// key constraints of the edges table.
+func (c *CockroachDBGraph) RemoveLink(id uuid.UUID) error {
+	return c.RemoveLinkContext(context.Background(), id)
+}
+
+// RemoveLinkContext implements graph.Graph.
+func (c *CockroachDBGraph) RemoveLinkContext(ctx context.Context, id uuid.UUID) error {
+	res, err := c.db.ExecContext(ctx, removeLinkQuery, uuid.New(), id)
+	if err != nil {
+		return xerrors.Errorf("remove link: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return xerrors.Errorf("remove link: %w", err)
+	} else if affected == 0 {
+		return xerrors.Errorf("remove link: %w", graph.ErrNotFound)
+	}
+
+	return nil
+}
+
+// Returns link iterator for the provided values. The iterator fetches the
+// links in pages ordered by their ID (see SetPageSize) and implements
+// Resumable.
+func (c *CockroachDBGraph) Links(fromID, toID uuid.UUID, accessedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	return c.LinksContext(context.Background(), fromID, toID, accessedBefore, filters...)
+}
+
+// LinksContext implements graph.Graph.
+func (c *CockroachDBGraph) LinksContext(ctx context.Context, fromID, toID uuid.UUID, accessedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	return c.LinksFromCursor(ctx, "", fromID, toID, accessedBefore, filters...)
+}
 
-// Returns link iterator for the provided values
-func (c *CockroachDBGraph) Links(fromID, toID uuid.UUID, accessedBefore time.Time) (graph.LinkIterator, error) {
-	rows, err := c.db.Query(linksInPartitionQuery, fromID,toID,accessedBefore.UTC())
+// LinksFromCursor resumes an iteration started by Links from the position
+// identified by cursor. The remaining arguments must match the ones passed
+// to Links. An empty cursor starts the iteration from the beginning.
+//
+// Each page is fetched with a separate query, so links that are upserted or
+// removed while an iteration is in progress may or may not be returned.
+func (c *CockroachDBGraph) LinksFromCursor(ctx context.Context, cursor Cursor, fromID, toID uuid.UUID, accessedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	query, args := appendLinkFilters(linksInPartitionQuery, []interface{}{fromID, toID, accessedBefore.UTC()}, filters)
+	pageSize := c.pageSize
+	it, err := newLinkIterator(ctx, c.fetchLinks(query, args, pageSize), pageSize, cursor)
 	if err != nil {
 		return nil, xerrors.Errorf("links: %w", err)
 	}
 
-	return &linkIterator{rows: rows}, nil
+	return it, nil
+}
+
+
+// LinksByHost returns an iterator for the set of links whose URL refers to
+// the specified host. Links stored before the host column was introduced are
+// only returned once BackfillHosts has been run.
+func (c *CockroachDBGraph) LinksByHost(host string) (graph.LinkIterator, error) {
+	return c.LinksByHostContext(context.Background(), host)
 }
 
+// LinksByHostContext implements graph.Graph.
+func (c *CockroachDBGraph) LinksByHostContext(ctx context.Context, host string) (graph.LinkIterator, error) {
+	hostname, err := urlcanon.CanonicalHostname(host)
+	if err != nil {
+		return nil, xerrors.Errorf("links by host: %w", err)
+	}
+
+	pageSize := c.pageSize
+	it, err := newLinkIterator(ctx, c.fetchLinks(linksByHostQuery, []interface{}{hostname}, pageSize), pageSize, "")
+	if err != nil {
+		return nil, xerrors.Errorf("links by host: %w", err)
+	}
+
+	return it, nil
+}
+
+// appendLinkFilters extends a link query with a condition that matches the
+// links satisfying any of the provided filters. The values referenced by the
+// condition are appended to args.
+func appendLinkFilters(query string, args []interface{}, filters []graph.LinkFilter) (string, []interface{}) {
+	if len(filters) == 0 {
+		return query, args
+	}
+
+	numArgs := len(args)
+	alternatives := make([]string, 0, len(filters))
+	for _, f := range filters {
+		var conds []string
+		addCond := func(format string, v interface{}) {
+			args = append(args, v)
+			conds = append(conds, fmt.Sprintf(format, len(args)))
+		}
+
+		if f.MinStatusCode != 0 {
+			addCond("status_code >= $%d", f.MinStatusCode)
+		}
+		if f.MaxStatusCode != 0 {
+			addCond("status_code <= $%d", f.MaxStatusCode)
+		}
+		if f.ContentTypePrefix != "" {
+			addCond("content_type LIKE $%d", likePrefixEscaper.Replace(f.ContentTypePrefix)+"%")
+		}
+		if f.MinFetchErrors != 0 {
+			addCond("fetch_errors >= $%d", f.MinFetchErrors)
+		}
+		if !f.FirstSeenAfter.IsZero() {
+			addCond("first_seen_at >= $%d", f.FirstSeenAfter.UTC())
+		}
+
+		if len(conds) == 0 {
+			// An empty filter matches all links.
+			return query, args[:numArgs]
+		}
+		alternatives = append(alternatives, "("+strings.Join(conds, " AND ")+")")
+	}
+
+	return query + " AND (" + strings.Join(alternatives, " OR ") + ")", args
+}
 
 // isForeignKeyViolationError returns true if err indicates a foreign key
 // constraint violation.
@@ -100,8 +537,18 @@ func isForeignKeyViolationError(err error) bool {
 }
 
 func (c *CockroachDBGraph) UpsertEdge(edge *graph.Edge) error {
-	row := c.db.QueryRow(upsertEdgeQuery,edge.Src,edge.Dst)
-	if err := row.Scan(&edge.ID, edge.UpdatedAt);  err != nil {
+	return c.UpsertEdgeContext(context.Background(), edge)
+}
+
+// UpsertEdgeContext implements graph.Graph.
+func (c *CockroachDBGraph) UpsertEdgeContext(ctx context.Context, edge *graph.Edge) error {
+	normalizeLinkCount(edge)
+	args := append([]interface{}{uuid.New(), edge.Src, edge.Dst}, edgeAttributeArgs(edge)...)
+	err := c.withRetries(ctx, isTransientError, func() error {
+		row := c.db.QueryRowContext(ctx, upsertEdgeQuery, args...)
+		return row.Scan(&edge.ID, &edge.UpdatedAt)
+	})
+	if err != nil {
 		if isForeignKeyViolationError(err) {
 			err = graph.ErrUnknownEdgeLinks
 		}
@@ -111,18 +558,211 @@ func (c *CockroachDBGraph) UpsertEdge(edge *graph.Edge) error {
 	return nil
 }
 
+// UpsertEdges creates or updates a batch of edges using multi-row INSERT
+// statements. Edges that refer to unknown links are skipped and reported
+// via a *graph.BatchError.
+func (c *CockroachDBGraph) UpsertEdges(edges []*graph.Edge) error {
+	return c.UpsertEdgesContext(context.Background(), edges)
+}
+
+// UpsertEdgesContext implements graph.Graph.
+func (c *CockroachDBGraph) UpsertEdgesContext(ctx context.Context, edges []*graph.Edge) error {
+	if len(edges) == 0 {
+		return nil
+	}
+
+	// A foreign key violation would abort the entire statement so the
+	// edge endpoints are validated up front.
+	known, err := c.existingLinks(ctx, edges)
+	if err != nil {
+		return xerrors.Errorf("upsert edges: %w", err)
+	}
+
+	// Collapse edges with the same endpoints into a single row as the
+	// same row cannot be updated twice by an INSERT ... ON CONFLICT. The
+	// attributes of the last such edge in the batch win.
+	var (
+		errs       = make([]error, len(edges))
+		keys       [][2]uuid.UUID
+		edgesByKey = make(map[[2]uuid.UUID][]*graph.Edge)
+	)
+	for i, edge := range edges {
+		if !known[edge.Src] || !known[edge.Dst] {
+			errs[i] = xerrors.Errorf("upsert edges: %w", graph.ErrUnknownEdgeLinks)
+			continue
+		}
+		normalizeLinkCount(edge)
+
+		key := [2]uuid.UUID{edge.Src, edge.Dst}
+		if _, seen := edgesByKey[key]; !seen {
+			keys = append(keys, key)
+		}
+		edgesByKey[key] = append(edgesByKey[key], edge)
+	}
+
+	for len(keys) != 0 {
+		n := len(keys)
+		if n > maxRowsPerInsert {
+			n = maxRowsPerInsert
+		}
+
+		args := make([]interface{}, 0, 1+numEdgeColumns*n)
+		args = append(args, uuid.New())
+		for _, key := range keys[:n] {
+			sameKey := edgesByKey[key]
+			args = append(args, key[0], key[1])
+			args = append(args, edgeAttributeArgs(sameKey[len(sameKey)-1])...)
+		}
+
+		rows, err := c.db.QueryContext(ctx, multiRowQuery(upsertEdgesQueryPrefix, upsertEdgesQuerySuffix, n, numEdgeColumns, "NOW()"), args...)
+		if err != nil {
+			if isForeignKeyViolationError(err) {
+				err = graph.ErrUnknownEdgeLinks
+			}
+			return xerrors.Errorf("upsert edges: %w", err)
+		}
+
+		for rows.Next() {
+			var (
+				id, src, dst uuid.UUID
+				updatedAt    time.Time
+			)
+			if err = rows.Scan(&id, &src, &dst, &updatedAt); err != nil {
+				_ = rows.Close()
+				return xerrors.Errorf("upsert edges: %w", err)
+			}
+
+			for _, edge := range edgesByKey[[2]uuid.UUID{src, dst}] {
+				edge.ID = id
+				edge.UpdatedAt = updatedAt.UTC()
+			}
+		}
+		if err = rows.Err(); err != nil {
+			_ = rows.Close()
+			return xerrors.Errorf("upsert edges: %w", err)
+		}
+		if err = rows.Close(); err != nil {
+			return xerrors.Errorf("upsert edges: %w", err)
+		}
+
+		keys = keys[n:]
+	}
+
+	return graph.NewBatchError(errs)
+}
+
+// numEdgeColumns is the number of columns populated via query arguments
+// when inserting an edge.
+const numEdgeColumns = 7
+
+// edgeAttributeArgs returns the query arguments for the attribute columns of
+// an inserted edge.
+func edgeAttributeArgs(edge *graph.Edge) []interface{} {
+	return []interface{}{edge.AnchorText, edge.NoFollow, edge.Sponsored, edge.UGC, edge.LinkCount}
+}
+
+// normalizeLinkCount ensures that edge links its destination at least once.
+func normalizeLinkCount(edge *graph.Edge) {
+	if edge.LinkCount < 1 {
+		edge.LinkCount = 1
+	}
+}
+
+// existingLinks returns the set of link IDs referenced by edges that exist
+// in the database.
+func (c *CockroachDBGraph) existingLinks(ctx context.Context, edges []*graph.Edge) (map[uuid.UUID]bool, error) {
+	var (
+		ids  []string
+		seen = make(map[uuid.UUID]bool)
+	)
+	for _, edge := range edges {
+		for _, id := range [2]uuid.UUID{edge.Src, edge.Dst} {
+			if _, dup := seen[id]; !dup {
+				seen[id] = false
+				ids = append(ids, id.String())
+			}
+		}
+	}
+
+	rows, err := c.db.QueryContext(ctx, existingLinksQuery, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		seen[id] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return seen, nil
+}
+
+// Edges returns an iterator for the set of edges whose source vertex IDs
+// belong to the [fromID, toID) range and were updated before the provided
+// timestamp. The iterator fetches the edges in pages ordered by their source
+// and destination (see SetPageSize) and implements Resumable.
 func (c *CockroachDBGraph) Edges(fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
-	rows, err := c.db.Query(edgesInPartitionQuery, fromID, toID, updatedBefore.UTC())
+	return c.EdgesContext(context.Background(), fromID, toID, updatedBefore)
+}
+
+// EdgesContext implements graph.Graph.
+func (c *CockroachDBGraph) EdgesContext(ctx context.Context, fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	return c.EdgesFromCursor(ctx, "", fromID, toID, updatedBefore)
+}
+
+// EdgesFromCursor resumes an iteration started by Edges from the position
+// identified by cursor. The remaining arguments must match the ones passed
+// to Edges. An empty cursor starts the iteration from the beginning.
+//
+// Each page is fetched with a separate query, so edges that are upserted or
+// removed while an iteration is in progress may or may not be returned.
+func (c *CockroachDBGraph) EdgesFromCursor(ctx context.Context, cursor Cursor, fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	pageSize := c.pageSize
+	fetchFn := c.fetchEdges(edgesInPartitionQuery, []interface{}{fromID, toID, updatedBefore.UTC()}, edgeKeyColumns, pageSize)
+	it, err := newEdgeIterator(ctx, fetchFn, edgeCursor, pageSize, cursor)
 	if err != nil {
-		return nil,xerrors.Errorf("edges: %w", err)
+		return nil, xerrors.Errorf("edges: %w", err)
 	}
 
-	return &edgeIterator{rows:rows}, nil
+	return it, nil
 }
 
 
+// InboundEdges returns an iterator for the set of edges that point to the
+// specified link ID and were updated before the provided timestamp.
+func (c *CockroachDBGraph) InboundEdges(dstID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	return c.InboundEdgesContext(context.Background(), dstID, updatedBefore)
+}
+
+// InboundEdgesContext implements graph.Graph.
+func (c *CockroachDBGraph) InboundEdgesContext(ctx context.Context, dstID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	pageSize := c.pageSize
+	fetchFn := c.fetchEdges(inboundEdgesQuery, []interface{}{dstID, updatedBefore.UTC()}, edgeIDKeyColumns, pageSize)
+	it, err := newEdgeIterator(ctx, fetchFn, edgeIDCursor, pageSize, "")
+	if err != nil {
+		return nil, xerrors.Errorf("inbound edges: %w", err)
+	}
+
+	return it, nil
+}
+
 func (c *CockroachDBGraph) RemoveStaleEdges(fromID uuid.UUID, updatedBefore time.Time) error {
-	_, err := c.db.Exec(removeStaleEdgesQuery,fromID, updatedBefore.UTC())
+	return c.RemoveStaleEdgesContext(context.Background(), fromID, updatedBefore)
+}
+
+// RemoveStaleEdgesContext implements graph.Graph.
+func (c *CockroachDBGraph) RemoveStaleEdgesContext(ctx context.Context, fromID uuid.UUID, updatedBefore time.Time) error {
+	requestID := uuid.New()
+	err := c.withRetries(ctx, isTransientError, func() error {
+		_, err := c.db.ExecContext(ctx, removeStaleEdgesQuery, requestID, fromID, updatedBefore.UTC())
+		return err
+	})
 	if err != nil {
 		return xerrors.Errorf("remove stale edges: %w", err)
 	}
@@ -130,3 +770,69 @@ func (c *CockroachDBGraph) RemoveStaleEdges(fromID uuid.UUID, updatedBefore time
 	return nil
 
 }
+
+// eventTypeLiteral returns the SQL literal for the event_type column value
+// of the specified event type.
+func eventTypeLiteral(t graph.EventType) string {
+	return strconv.Itoa(int(t))
+}
+
+// logEventsCTE returns the common table expressions that record an event of
+// type t in the graph_events outbox table for each row of the source CTE.
+// The sourceColumns of each row are stored in the eventColumns of its event.
+//
+// Events are keyed by the commit timestamp of the statement, the request ID
+// passed as the first statement argument and their index within the
+// statement. Using cluster_logical_timestamp() pins the commit timestamp of
+// the statement, so watchers that read the table in key order never observe
+// an event whose predecessors have yet to commit (see Watch). Events that
+// were already recorded for the request ID are not recorded again.
+func logEventsCTE(t graph.EventType, source, sourceColumns, eventColumns string) string {
+	return `,
+numbered AS (
+	SELECT row_number() OVER () AS idx, ` + sourceColumns + ` FROM ` + source + `
+),
+logged AS (
+	INSERT INTO graph_events (commit_ts, request_id, idx, event_type, ` + eventColumns + `)
+	SELECT cluster_logical_timestamp(), $1::UUID, idx, ` + eventTypeLiteral(t) + `, ` + sourceColumns + `
+	FROM numbered
+	ON CONFLICT (request_id, idx) DO NOTHING
+)
+`
+}
+
+// maxRowsPerInsert caps the number of rows sent with a single multi-row
+// INSERT statement.
+const maxRowsPerInsert = 500
+
+// multiRowQuery assembles a multi-row INSERT statement with numRows value
+// tuples. Each tuple consists of numArgs positional placeholders followed
+// by the provided literal expressions. Placeholder $1 is reserved for the
+// request ID (see logEventsCTE).
+func multiRowQuery(prefix, suffix string, numRows, numArgs int, literals ...string) string {
+	var (
+		b   strings.Builder
+		arg = 2
+	)
+	b.WriteString(prefix)
+	for row := 0; row < numRows; row++ {
+		if row != 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('(')
+		for i := 0; i < numArgs; i++ {
+			if i != 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "$%d", arg)
+			arg++
+		}
+		for _, lit := range literals {
+			b.WriteString(", ")
+			b.WriteString(lit)
+		}
+		b.WriteByte(')')
+	}
+	b.WriteString(suffix)
+	return b.String()
+}
@@ -1,93 +1,238 @@
 package cdb
 
 import (
-	"database/sql"
+	"context"
+	"time"
 
 	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
 	"golang.org/x/xerrors"
 )
 
-//implements graph.LinkIterator
+// linkIterator is a graph.LinkIterator implementation that fetches links
+// one page at a time. Each page is read in full before it is returned so no
+// database connection is held while the iterator is being consumed.
 type linkIterator struct {
-	rows *sql.Rows
-	lastErr	error
-	latchedLink	*graph.Link
+	ctx      context.Context
+	fetchFn  func(context.Context, Cursor) ([]*graph.Link, error)
+	pageSize int
+
+	page        []*graph.Link
+	pageIdx     int
+	lastPage    bool
+	cursor      Cursor
+	lastErr     error
+	latchedLink *graph.Link
 }
 
-func (i *linkIterator) Next() bool {
-	if i.lastErr != nil || !i.rows.Next() {
-		return false
+// newLinkIterator returns a linkIterator that resumes after cursor. The
+// first page is fetched eagerly so that query errors are reported to the
+// caller right away.
+func newLinkIterator(ctx context.Context, fetchFn func(context.Context, Cursor) ([]*graph.Link, error), pageSize int, cursor Cursor) (*linkIterator, error) {
+	it := &linkIterator{ctx: ctx, fetchFn: fetchFn, pageSize: pageSize, cursor: cursor}
+	if err := it.fetchPage(); err != nil {
+		return nil, err
 	}
+	return it, nil
+}
 
-	l := new(graph.Link)
-	i.lastErr = i.rows.Scan(&l.ID,&l.URL, &l.RetrievedAt)
+func (i *linkIterator) fetchPage() error {
+	page, err := i.fetchFn(i.ctx, i.cursor)
+	if err != nil {
+		return err
+	}
+	i.page, i.pageIdx, i.lastPage = page, 0, len(page) < i.pageSize
+	return nil
+}
+
+// Next implements graph.LinkIterator.
+func (i *linkIterator) Next() bool {
 	if i.lastErr != nil {
 		return false
+	} else if i.lastErr = i.ctx.Err(); i.lastErr != nil {
+		return false
 	}
-	l.RetrievedAt = l.RetrievedAt.UTC()
 
-	i.latchedLink = l
+	if i.pageIdx >= len(i.page) {
+		if i.lastPage {
+			return false
+		} else if i.lastErr = i.fetchPage(); i.lastErr != nil {
+			i.lastErr = xerrors.Errorf("link iterator: %w", i.lastErr)
+			return false
+		} else if len(i.page) == 0 {
+			return false
+		}
+	}
+
+	i.latchedLink = i.page[i.pageIdx]
+	i.cursor = linkCursor(i.latchedLink)
+	i.pageIdx++
 	return true
 }
 
-
+// Error implements graph.LinkIterator.
 func (i *linkIterator) Error() error {
 	return i.lastErr
 }
 
+// Close implements graph.LinkIterator.
 func (i *linkIterator) Close() error {
-	err := i.rows.Close()
-	if err != nil {
-		return xerrors.Errorf("Link iterator: %w", err)
-	}
-
+	i.page, i.lastPage = nil, true
 	return nil
 }
 
+// Link implements graph.LinkIterator.
 func (i *linkIterator) Link() *graph.Link {
 	return i.latchedLink
 }
 
+// Cursor implements Resumable.
+func (i *linkIterator) Cursor() Cursor {
+	return i.cursor
+}
 
-//edgeIterator
+// edgeIterator is a graph.EdgeIterator implementation that fetches edges
+// one page at a time. Each page is read in full before it is returned so no
+// database connection is held while the iterator is being consumed.
 type edgeIterator struct {
-	rows 	*sql.Rows
-	lastErr	error
+	ctx      context.Context
+	fetchFn  func(context.Context, Cursor) ([]*graph.Edge, error)
+	cursorFn func(*graph.Edge) Cursor
+	pageSize int
+
+	page        []*graph.Edge
+	pageIdx     int
+	lastPage    bool
+	cursor      Cursor
+	lastErr     error
 	latchedEdge *graph.Edge
 }
 
-
-func (i *edgeIterator) Next() bool {
-	if i.lastErr !=nil || !i.rows.Next() {
-		return false
+// newEdgeIterator returns an edgeIterator that resumes after cursor. The
+// position of each returned edge is obtained via cursorFn. The first page is
+// fetched eagerly so that query errors are reported to the caller right
+// away.
+func newEdgeIterator(ctx context.Context, fetchFn func(context.Context, Cursor) ([]*graph.Edge, error), cursorFn func(*graph.Edge) Cursor, pageSize int, cursor Cursor) (*edgeIterator, error) {
+	it := &edgeIterator{ctx: ctx, fetchFn: fetchFn, cursorFn: cursorFn, pageSize: pageSize, cursor: cursor}
+	if err := it.fetchPage(); err != nil {
+		return nil, err
 	}
+	return it, nil
+}
 
-	e := new(graph.Edge)
-	i.lastErr = i.rows.Scan(&e.ID, &e.Src, &e.UpdatedAt)
+func (i *edgeIterator) fetchPage() error {
+	page, err := i.fetchFn(i.ctx, i.cursor)
+	if err != nil {
+		return err
+	}
+	i.page, i.pageIdx, i.lastPage = page, 0, len(page) < i.pageSize
+	return nil
+}
 
+// Next implements graph.EdgeIterator.
+func (i *edgeIterator) Next() bool {
 	if i.lastErr != nil {
 		return false
+	} else if i.lastErr = i.ctx.Err(); i.lastErr != nil {
+		return false
+	}
+
+	if i.pageIdx >= len(i.page) {
+		if i.lastPage {
+			return false
+		} else if i.lastErr = i.fetchPage(); i.lastErr != nil {
+			i.lastErr = xerrors.Errorf("edge iterator: %w", i.lastErr)
+			return false
+		} else if len(i.page) == 0 {
+			return false
+		}
 	}
 
-	e.UpdatedAt = e.UpdatedAt.UTC()
-	i.latchedEdge = e
+	i.latchedEdge = i.page[i.pageIdx]
+	i.cursor = i.cursorFn(i.latchedEdge)
+	i.pageIdx++
 	return true
 }
 
-
+// Error implements graph.EdgeIterator.
 func (i *edgeIterator) Error() error {
 	return i.lastErr
 }
 
+// Close implements graph.EdgeIterator.
 func (i *edgeIterator) Close() error {
-	err := i.rows.Close()
-	if err != nil {
-		return xerrors.Errorf("edge iterator: %w", err)
-	}
+	i.page, i.lastPage = nil, true
 	return nil
 }
 
+// Edge implements graph.EdgeIterator.
 func (i *edgeIterator) Edge() *graph.Edge {
 	return i.latchedEdge
 }
 
+// Cursor implements Resumable.
+func (i *edgeIterator) Cursor() Cursor {
+	return i.cursor
+}
+
+// eventIterator is a graph.EventIterator implementation that polls the
+// graph_events table. Once the iterator has caught up, Next blocks until new
+// events become available or the context is cancelled.
+type eventIterator struct {
+	ctx     context.Context
+	g       *CockroachDBGraph
+	lastKey eventKey
+
+	page         []*graph.Event
+	pageKeys     []eventKey
+	pageIdx      int
+	latchedEvent *graph.Event
+	lastErr      error
+	closed       bool
+}
+
+// Next implements graph.EventIterator.
+func (i *eventIterator) Next() bool {
+	if i.lastErr != nil || i.closed {
+		return false
+	}
+
+	for i.pageIdx >= len(i.page) {
+		if i.lastErr = i.ctx.Err(); i.lastErr != nil {
+			return false
+		}
+
+		if i.page, i.pageKeys, i.lastErr = i.g.fetchEvents(i.ctx, i.lastKey); i.lastErr != nil {
+			i.lastErr = xerrors.Errorf("event iterator: %w", i.lastErr)
+			return false
+		}
+		i.pageIdx = 0
+
+		if len(i.page) == 0 {
+			select {
+			case <-i.ctx.Done():
+			case <-time.After(watchPollInterval):
+			}
+		}
+	}
+
+	i.latchedEvent = i.page[i.pageIdx]
+	i.lastKey = i.pageKeys[i.pageIdx]
+	i.pageIdx++
+	return true
+}
+
+// Error implements graph.EventIterator.
+func (i *eventIterator) Error() error {
+	return i.lastErr
+}
+
+// Close implements graph.EventIterator.
+func (i *eventIterator) Close() error {
+	i.page, i.pageKeys, i.closed = nil, nil, true
+	return nil
+}
+
+// Event implements graph.EventIterator.
+func (i *eventIterator) Event() *graph.Event {
+	return i.latchedEvent
+}
@@ -0,0 +1,95 @@
+package cdb
+
+import (
+	"embed"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres" // postgres migration driver
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"golang.org/x/xerrors"
+)
+
+// LatestSchemaVersion is the schema version that the queries used by
+// CockroachDBGraph have been written against.
+const LatestSchemaVersion uint = 7
+
+// ErrSchemaVersion is returned by NewCockroachDbGraphWithOptions if the
+// database schema does not match LatestSchemaVersion.
+var ErrSchemaVersion = xerrors.New("unexpected schema version")
+
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// Migrate applies the up or down migrations that are required for bringing
+// the schema of the database at dsn to targetVersion. A target version of
+// zero rolls back all migrations.
+func Migrate(dsn string, targetVersion uint) error {
+	if targetVersion > LatestSchemaVersion {
+		return xerrors.Errorf("migrate: unknown schema version %d; latest version is %d", targetVersion, LatestSchemaVersion)
+	}
+
+	m, err := newMigrator(dsn)
+	if err != nil {
+		return xerrors.Errorf("migrate: %w", err)
+	}
+	defer func() { _, _ = m.Close() }()
+
+	if targetVersion == 0 {
+		err = m.Down()
+	} else {
+		err = m.Migrate(targetVersion)
+	}
+	if err != nil && err != migrate.ErrNoChange {
+		return xerrors.Errorf("migrate: %w", err)
+	}
+	return nil
+}
+
+// SchemaVersion returns the schema version of the database at dsn and
+// whether the last applied migration failed half-way. A zero version is
+// returned if no migrations have been applied yet.
+func SchemaVersion(dsn string) (version uint, dirty bool, err error) {
+	m, err := newMigrator(dsn)
+	if err != nil {
+		return 0, false, xerrors.Errorf("schema version: %w", err)
+	}
+	defer func() { _, _ = m.Close() }()
+
+	version, dirty, err = m.Version()
+	if err == migrate.ErrNilVersion {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, xerrors.Errorf("schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// verifySchemaVersion returns ErrSchemaVersion unless the database at dsn
+// has been cleanly migrated to LatestSchemaVersion.
+func verifySchemaVersion(dsn string) error {
+	version, dirty, err := SchemaVersion(dsn)
+	if err != nil {
+		return err
+	} else if dirty {
+		return xerrors.Errorf("schema version %d is dirty: %w", version, ErrSchemaVersion)
+	} else if version != LatestSchemaVersion {
+		return xerrors.Errorf("schema version %d does not match expected version %d: %w", version, LatestSchemaVersion, ErrSchemaVersion)
+	}
+	return nil
+}
+
+// newMigrator returns a migrator for the database at dsn that sources its
+// migrations from the embedded SQL files.
+func newMigrator(dsn string) (*migrate.Migrate, error) {
+	src, err := iofs.New(migrationFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, dsn)
+	if err != nil {
+		_ = src.Close()
+		return nil, err
+	}
+	return m, nil
+}
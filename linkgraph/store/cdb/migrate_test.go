@@ -0,0 +1,38 @@
+package cdb
+
+import (
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(MigrationsTestSuite))
+
+type MigrationsTestSuite struct{}
+
+// TestEmbeddedMigrations verifies that the embedded migrations form a
+// contiguous sequence of up and down steps ending at LatestSchemaVersion.
+func (s *MigrationsTestSuite) TestEmbeddedMigrations(c *gc.C) {
+	src, err := iofs.New(migrationFS, "migrations")
+	c.Assert(err, gc.IsNil)
+	defer func() { c.Assert(src.Close(), gc.IsNil) }()
+
+	version, err := src.First()
+	c.Assert(err, gc.IsNil)
+	c.Assert(version, gc.Equals, uint(1))
+	for {
+		up, _, err := src.ReadUp(version)
+		c.Assert(err, gc.IsNil, gc.Commentf("up migration %d", version))
+		c.Assert(up.Close(), gc.IsNil)
+		down, _, err := src.ReadDown(version)
+		c.Assert(err, gc.IsNil, gc.Commentf("down migration %d", version))
+		c.Assert(down.Close(), gc.IsNil)
+
+		next, err := src.Next(version)
+		if err != nil {
+			break
+		}
+		c.Assert(next, gc.Equals, version+1)
+		version = next
+	}
+	c.Assert(version, gc.Equals, LatestSchemaVersion)
+}
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS edges@edges_dst_idx;
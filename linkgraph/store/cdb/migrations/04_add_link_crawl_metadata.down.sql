@@ -0,0 +1,7 @@
+ALTER TABLE links
+	DROP COLUMN IF EXISTS first_seen_at,
+	DROP COLUMN IF EXISTS status_code,
+	DROP COLUMN IF EXISTS content_type,
+	DROP COLUMN IF EXISTS content_hash,
+	DROP COLUMN IF EXISTS fetch_errors,
+	DROP COLUMN IF EXISTS last_error;
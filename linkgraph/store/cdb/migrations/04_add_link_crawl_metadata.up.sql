@@ -0,0 +1,7 @@
+ALTER TABLE links
+	ADD COLUMN IF NOT EXISTS first_seen_at TIMESTAMP NOT NULL DEFAULT now(),
+	ADD COLUMN IF NOT EXISTS status_code INT NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS content_type STRING NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS content_hash STRING NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS fetch_errors INT NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS last_error STRING NOT NULL DEFAULT '';
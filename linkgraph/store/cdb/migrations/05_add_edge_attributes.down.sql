@@ -0,0 +1,6 @@
+ALTER TABLE edges
+	DROP COLUMN IF EXISTS anchor_text,
+	DROP COLUMN IF EXISTS nofollow,
+	DROP COLUMN IF EXISTS sponsored,
+	DROP COLUMN IF EXISTS ugc,
+	DROP COLUMN IF EXISTS link_count;
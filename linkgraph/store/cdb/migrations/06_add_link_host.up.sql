@@ -0,0 +1,2 @@
+ALTER TABLE links ADD COLUMN IF NOT EXISTS host STRING NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS links_host_idx ON links (host);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS graph_event_trims;
+DROP TABLE IF EXISTS graph_events;
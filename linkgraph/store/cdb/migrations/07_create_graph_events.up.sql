@@ -0,0 +1,54 @@
+-- graph_events is an outbox table that records every graph mutation in the
+-- same statement that applies it. Watchers poll the table in key order.
+--
+-- Events are keyed by the commit timestamp of the statement that recorded
+-- them (as returned by cluster_logical_timestamp()), the ID of the request
+-- that issued the statement and their index within the statement. The
+-- primary key is hash-sharded so that inserts with increasing timestamps are
+-- spread across ranges. The unique (request_id, idx) index ensures that a
+-- retried request does not record its events twice.
+CREATE TABLE IF NOT EXISTS graph_events (
+	commit_ts DECIMAL NOT NULL,
+	request_id UUID NOT NULL,
+	idx INT NOT NULL,
+	event_type INT2 NOT NULL,
+
+	-- Upserted links; the ID of removed links or the source of removed
+	-- stale edges.
+	link_id UUID,
+	url STRING,
+	retrieved_at TIMESTAMP,
+	first_seen_at TIMESTAMP,
+	status_code INT,
+	content_type STRING,
+	content_hash STRING,
+	fetch_errors INT,
+	last_error STRING,
+
+	-- Upserted edges.
+	edge_id UUID,
+	src UUID,
+	dst UUID,
+	updated_at TIMESTAMP,
+	anchor_text STRING,
+	nofollow BOOL,
+	sponsored BOOL,
+	ugc BOOL,
+	link_count INT,
+
+	-- Removed stale edges.
+	updated_before TIMESTAMP,
+
+	PRIMARY KEY (commit_ts, request_id, idx) USING HASH,
+	UNIQUE INDEX graph_events_request_idx (request_id, idx)
+);
+
+-- graph_event_trims records the commit timestamp before which events may
+-- have been removed from graph_events so that watchers which fall behind can
+-- be rejected.
+CREATE TABLE IF NOT EXISTS graph_event_trims (
+	id INT PRIMARY KEY,
+	trimmed_before DECIMAL NOT NULL
+);
+
+INSERT INTO graph_event_trims (id, trimmed_before) VALUES (1, 0) ON CONFLICT (id) DO NOTHING;
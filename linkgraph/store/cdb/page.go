@@ -0,0 +1,155 @@
+package cdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// defaultPageSize is the number of rows fetched per page by link and edge
+// iterators unless configured otherwise via SetPageSize.
+const defaultPageSize = 1000
+
+// ErrInvalidCursor is returned when resuming an iteration from a cursor that
+// was not obtained from a compatible iterator.
+var ErrInvalidCursor = xerrors.New("invalid cursor")
+
+// Cursor identifies the position of a link or edge iterator. The empty
+// cursor refers to the start of the iteration.
+type Cursor string
+
+// Resumable is implemented by the link and edge iterators returned by
+// CockroachDBGraph. Cursor returns the position of the item that was most
+// recently returned by the iterator. Passing the cursor of an iterator
+// obtained via Links or Edges to LinksFromCursor or EdgesFromCursor
+// together with the original arguments resumes the iteration.
+type Resumable interface {
+	Cursor() Cursor
+}
+
+// Links are paginated by their ID.
+var linkKeyColumns = []string{"id"}
+
+// linkCursor returns the cursor for link.
+func linkCursor(link *graph.Link) Cursor {
+	return Cursor(link.ID.String())
+}
+
+// Edges within a partition are paginated by their endpoints as the
+// edge_links index orders them by (src, dst).
+var edgeKeyColumns = []string{"src", "dst"}
+
+// edgeCursor returns the cursor for an edge returned by Edges.
+func edgeCursor(edge *graph.Edge) Cursor {
+	return Cursor(edge.Src.String() + "," + edge.Dst.String())
+}
+
+// Inbound edges are paginated by their ID which is implicitly part of the
+// edges_dst_idx index.
+var edgeIDKeyColumns = []string{"id"}
+
+// edgeIDCursor returns the cursor for an edge returned by InboundEdges.
+func edgeIDCursor(edge *graph.Edge) Cursor {
+	return Cursor(edge.ID.String())
+}
+
+// pageQuery extends query with a keyset pagination clause that selects the
+// first pageSize rows following the row identified by cursor when rows are
+// ordered by keyColumns. The values referenced by the clause are appended
+// to a copy of args.
+func pageQuery(query string, args []interface{}, keyColumns []string, cursor Cursor, pageSize int) (string, []interface{}, error) {
+	args = append(make([]interface{}, 0, len(args)+len(keyColumns)+1), args...)
+
+	if cursor != "" {
+		values := strings.Split(string(cursor), ",")
+		if len(values) != len(keyColumns) {
+			return "", nil, xerrors.Errorf("%q: %w", cursor, ErrInvalidCursor)
+		}
+
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return "", nil, xerrors.Errorf("%q: %w", cursor, ErrInvalidCursor)
+			}
+			args = append(args, id)
+			placeholders[i] = fmt.Sprintf("$%d::UUID", len(args))
+		}
+		query += fmt.Sprintf(" AND (%s) > (%s)", strings.Join(keyColumns, ", "), strings.Join(placeholders, ", "))
+	}
+
+	args = append(args, pageSize)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d", strings.Join(keyColumns, ", "), len(args))
+	return query, args, nil
+}
+
+// fetchLinks returns a function that fetches the links selected by query one
+// page of pageSize rows at a time.
+func (c *CockroachDBGraph) fetchLinks(query string, args []interface{}, pageSize int) func(context.Context, Cursor) ([]*graph.Link, error) {
+	return func(ctx context.Context, cursor Cursor) ([]*graph.Link, error) {
+		q, qArgs, err := pageQuery(query, args, linkKeyColumns, cursor, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := c.db.QueryContext(ctx, q, qArgs...)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rows.Close() }()
+
+		links := make([]*graph.Link, 0, pageSize)
+		for rows.Next() {
+			l := new(graph.Link)
+			err = rows.Scan(&l.ID, &l.URL, &l.RetrievedAt, &l.FirstSeenAt, &l.StatusCode,
+				&l.ContentType, &l.ContentHash, &l.FetchErrors, &l.LastError)
+			if err != nil {
+				return nil, err
+			}
+			l.RetrievedAt = l.RetrievedAt.UTC()
+			l.FirstSeenAt = l.FirstSeenAt.UTC()
+			links = append(links, l)
+		}
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+		return links, rows.Close()
+	}
+}
+
+// fetchEdges returns a function that fetches the edges selected by query one
+// page of pageSize rows at a time, ordering them by keyColumns.
+func (c *CockroachDBGraph) fetchEdges(query string, args []interface{}, keyColumns []string, pageSize int) func(context.Context, Cursor) ([]*graph.Edge, error) {
+	return func(ctx context.Context, cursor Cursor) ([]*graph.Edge, error) {
+		q, qArgs, err := pageQuery(query, args, keyColumns, cursor, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := c.db.QueryContext(ctx, q, qArgs...)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rows.Close() }()
+
+		edges := make([]*graph.Edge, 0, pageSize)
+		for rows.Next() {
+			e := new(graph.Edge)
+			err = rows.Scan(&e.ID, &e.Src, &e.Dst, &e.UpdatedAt,
+				&e.AnchorText, &e.NoFollow, &e.Sponsored, &e.UGC, &e.LinkCount)
+			if err != nil {
+				return nil, err
+			}
+			e.UpdatedAt = e.UpdatedAt.UTC()
+			edges = append(edges, e)
+		}
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+		return edges, rows.Close()
+	}
+}
@@ -0,0 +1,36 @@
+package cdb
+
+import (
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(PageQueryTestSuite))
+
+type PageQueryTestSuite struct{}
+
+// TestPageQuery verifies the keyset pagination clauses appended to queries.
+func (s *PageQueryTestSuite) TestPageQuery(c *gc.C) {
+	const (
+		query = "SELECT id FROM edges WHERE src >= $1"
+		src   = "8fb3a4c0-d3b5-4b8a-9a4e-0b0b6e6b1f10"
+		dst   = "00000000-0000-0000-0000-000000000001"
+	)
+	args := []interface{}{"arg"}
+
+	q, qArgs, err := pageQuery(query, args, edgeKeyColumns, "", 10)
+	c.Assert(err, gc.IsNil)
+	c.Assert(q, gc.Equals, query+" ORDER BY src, dst LIMIT $2")
+	c.Assert(qArgs, gc.HasLen, 2)
+
+	q, qArgs, err = pageQuery(query, args, edgeKeyColumns, Cursor(src+","+dst), 10)
+	c.Assert(err, gc.IsNil)
+	c.Assert(q, gc.Equals, query+" AND (src, dst) > ($2::UUID, $3::UUID) ORDER BY src, dst LIMIT $4")
+	c.Assert(qArgs, gc.HasLen, 4)
+	c.Assert(args, gc.HasLen, 1)
+
+	for _, cursor := range []Cursor{Cursor(src), "foo,bar", Cursor(src + "," + dst + "," + dst)} {
+		_, _, err = pageQuery(query, args, edgeKeyColumns, cursor, 10)
+		c.Assert(xerrors.Is(err, ErrInvalidCursor), gc.Equals, true, gc.Commentf("cursor %q", cursor))
+	}
+}
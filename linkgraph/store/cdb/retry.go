@@ -0,0 +1,167 @@
+package cdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"math/rand"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+	"golang.org/x/xerrors"
+)
+
+// RetryPolicy configures how idempotent graph operations are retried when
+// they fail with a transient error.
+type RetryPolicy struct {
+	// The maximum number of attempts for each operation including the
+	// initial attempt. Values less than 2 disable retries.
+	MaxAttempts int
+
+	// The delay before the first retry. The delay is doubled for each
+	// subsequent retry up to MaxBackoff.
+	InitialBackoff time.Duration
+
+	// The upper bound for the delay between retries.
+	MaxBackoff time.Duration
+
+	// The fraction of each delay that is randomized to spread out retries
+	// of concurrent operations. It must be in the [0, 1] range; a value of
+	// 0.2 yields delays between 80% and 100% of the nominal delay.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is the retry policy used by new graph instances.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Jitter:         0.5,
+}
+
+// backoff returns the delay before the specified retry (starting at 0).
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 0; i < retry && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		delay -= time.Duration(p.Jitter * rand.Float64() * float64(delay))
+	}
+	return delay
+}
+
+// RetryStats reports the number of retries performed by a graph instance.
+type RetryStats struct {
+	// The number of times an operation was retried after failing with a
+	// transient error.
+	Retries uint64
+
+	// The number of operations that still failed with a transient error
+	// after exhausting all attempts.
+	Exhausted uint64
+}
+
+// retryCounters tracks the RetryStats of a graph instance.
+type retryCounters struct {
+	retries   uint64
+	exhausted uint64
+}
+
+// SetRetryPolicy configures the retry policy for the operations UpsertLink,
+// UpsertEdge, RemoveStaleEdges and FindLink. These operations are also
+// retried if the connection breaks while their outcome is unknown: applying
+// one of the mutations twice leaves the graph in the same state and, as
+// events are keyed by the ID of the request that recorded them (see
+// logEventsCTE), does not record its events twice. Passing the zero
+// RetryPolicy disables retries. It must be called before the graph is used.
+func (c *CockroachDBGraph) SetRetryPolicy(p RetryPolicy) {
+	c.retryPolicy = p
+}
+
+// RetryStats returns the number of retries performed so far.
+func (c *CockroachDBGraph) RetryStats() RetryStats {
+	return RetryStats{
+		Retries:   atomic.LoadUint64(&c.retryCounters.retries),
+		Exhausted: atomic.LoadUint64(&c.retryCounters.exhausted),
+	}
+}
+
+// withRetries invokes opFn until it succeeds, fails with an error for which
+// isRetryable returns false, the attempts allowed by the retry policy are
+// exhausted or ctx expires. The error of the last attempt is returned.
+func (c *CockroachDBGraph) withRetries(ctx context.Context, isRetryable func(error) bool, opFn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := opFn()
+		if err == nil || !isRetryable(err) {
+			return err
+		} else if attempt >= c.retryPolicy.MaxAttempts {
+			if c.retryPolicy.MaxAttempts > 1 {
+				atomic.AddUint64(&c.retryCounters.exhausted, 1)
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(c.retryPolicy.backoff(attempt - 1)):
+		}
+		atomic.AddUint64(&c.retryCounters.retries, 1)
+	}
+}
+
+// isAbortedError returns true if err indicates a transient failure which
+// guarantees that the failed statement was not applied, i.e. a transaction
+// that was aborted due to contention or a connection that could not be
+// established. Mutations can be safely retried after such errors.
+func isAbortedError(err error) bool {
+	var pqErr *pq.Error
+	if xerrors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "40001": // serialization_failure
+			return true
+		case "40P01": // deadlock_detected
+			return true
+		case "08001": // sqlclient_unable_to_establish_sqlconnection
+			return true
+		case "08004": // sqlserver_rejected_establishment_of_sqlconnection
+			return true
+		}
+		return false
+	}
+
+	return xerrors.Is(err, driver.ErrBadConn) ||
+		xerrors.Is(err, syscall.ECONNREFUSED)
+}
+
+// isTransientError returns true if err indicates a transient failure after
+// which an idempotent operation can be retried. In addition to the errors
+// reported by isAbortedError, this includes connections that broke while a
+// statement was in flight, i.e. the statement might have been committed.
+func isTransientError(err error) bool {
+	if isAbortedError(err) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if xerrors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == "57P01": // admin_shutdown
+			return true
+		case pqErr.Code.Class() == "08": // connection_exception
+			return true
+		}
+		return false
+	}
+
+	return xerrors.Is(err, io.ErrUnexpectedEOF) ||
+		xerrors.Is(err, syscall.ECONNRESET) ||
+		xerrors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,182 @@
+package cdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(RetryTestSuite))
+
+type RetryTestSuite struct{}
+
+// TestRetryableErrors verifies the classification of transient errors.
+func (s *RetryTestSuite) TestRetryableErrors(c *gc.C) {
+	connReset := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	connRefused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	specs := []struct {
+		descr        string
+		err          error
+		expAborted   bool
+		expTransient bool
+	}{
+		{descr: "serialization failure", err: &pq.Error{Code: "40001"}, expAborted: true, expTransient: true},
+		{descr: "wrapped serialization failure", err: xerrors.Errorf("upsert link: %w", &pq.Error{Code: "40001"}), expAborted: true, expTransient: true},
+		{descr: "connection refused", err: connRefused, expAborted: true, expTransient: true},
+		{descr: "connection failure", err: &pq.Error{Code: "08006"}, expTransient: true},
+		{descr: "connection reset", err: connReset, expTransient: true},
+		{descr: "unexpected EOF", err: io.ErrUnexpectedEOF, expTransient: true},
+		{descr: "unique violation", err: &pq.Error{Code: "23505"}},
+		{descr: "no rows", err: sql.ErrNoRows},
+		{descr: "context cancelled", err: context.Canceled},
+	}
+
+	for specIndex, spec := range specs {
+		c.Assert(isAbortedError(spec.err), gc.Equals, spec.expAborted, gc.Commentf("[spec %d] %s", specIndex, spec.descr))
+		c.Assert(isTransientError(spec.err), gc.Equals, spec.expTransient, gc.Commentf("[spec %d] %s", specIndex, spec.descr))
+	}
+}
+
+// TestWithRetries verifies that transient errors are retried until the
+// attempts allowed by the retry policy are exhausted.
+func (s *RetryTestSuite) TestWithRetries(c *gc.C) {
+	g := &CockroachDBGraph{retryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, Jitter: 0.5}}
+
+	var calls int
+	err := g.withRetries(context.TODO(), isAbortedError, func() error {
+		if calls++; calls < 3 {
+			return &pq.Error{Code: "40001"}
+		}
+		return nil
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(calls, gc.Equals, 3)
+	c.Assert(g.RetryStats(), gc.Equals, RetryStats{Retries: 2})
+
+	calls = 0
+	err = g.withRetries(context.TODO(), isAbortedError, func() error {
+		calls++
+		return &pq.Error{Code: "40001"}
+	})
+	c.Assert(err, gc.FitsTypeOf, &pq.Error{})
+	c.Assert(calls, gc.Equals, 3)
+	c.Assert(g.RetryStats(), gc.Equals, RetryStats{Retries: 4, Exhausted: 1})
+
+	// Permanent errors are returned right away.
+	calls = 0
+	err = g.withRetries(context.TODO(), isAbortedError, func() error {
+		calls++
+		return sql.ErrNoRows
+	})
+	c.Assert(err, gc.Equals, sql.ErrNoRows)
+	c.Assert(calls, gc.Equals, 1)
+
+	// Retries stop once the context expires.
+	ctx, cancelFn := context.WithCancel(context.TODO())
+	cancelFn()
+	calls = 0
+	_ = g.withRetries(ctx, isAbortedError, func() error {
+		calls++
+		return &pq.Error{Code: "40001"}
+	})
+	c.Assert(calls, gc.Equals, 1)
+}
+
+// TestRetryAfterAmbiguousCommit verifies that operations are retried if the
+// connection breaks while their outcome is unknown and that all attempts of
+// a mutation record their events with the same request ID.
+func (s *RetryTestSuite) TestRetryAfterAmbiguousCommit(c *gc.C) {
+	connector := new(brokenConnector)
+	g := &CockroachDBGraph{
+		db:          sql.OpenDB(connector),
+		canon:       urlcanon.Default,
+		retryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond},
+	}
+	defer func() { _ = g.db.Close() }()
+
+	mutations := map[string]func() error{
+		"upsert link":        func() error { return g.UpsertLink(&graph.Link{URL: "http://example.com/"}) },
+		"upsert edge":        func() error { return g.UpsertEdge(&graph.Edge{Src: uuid.New(), Dst: uuid.New()}) },
+		"remove stale edges": func() error { return g.RemoveStaleEdges(uuid.New(), time.Now()) },
+	}
+	for descr, mutateFn := range mutations {
+		connector.firstArgs = nil
+		err := mutateFn()
+		c.Assert(xerrors.Is(err, syscall.ECONNRESET), gc.Equals, true, gc.Commentf("%s: %v", descr, err))
+		c.Assert(connector.firstArgs, gc.HasLen, 3, gc.Commentf(descr))
+		for _, requestID := range connector.firstArgs[1:] {
+			c.Assert(requestID, gc.Equals, connector.firstArgs[0], gc.Commentf(descr))
+		}
+	}
+
+	connector.firstArgs = nil
+	_, err := g.FindLink(uuid.New())
+	c.Assert(xerrors.Is(err, syscall.ECONNRESET), gc.Equals, true, gc.Commentf("%v", err))
+	c.Assert(connector.firstArgs, gc.HasLen, 3)
+	c.Assert(g.RetryStats(), gc.Equals, RetryStats{Retries: 8, Exhausted: 4})
+}
+
+// TestBackoff verifies that retry delays grow exponentially up to the
+// configured maximum and stay within the jitter bounds.
+func (s *RetryTestSuite) TestBackoff(c *gc.C) {
+	p := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	c.Assert(p.backoff(0), gc.Equals, 10*time.Millisecond)
+	c.Assert(p.backoff(1), gc.Equals, 20*time.Millisecond)
+	c.Assert(p.backoff(2), gc.Equals, 40*time.Millisecond)
+	c.Assert(p.backoff(3), gc.Equals, 50*time.Millisecond)
+	c.Assert(p.backoff(100), gc.Equals, 50*time.Millisecond)
+
+	p.Jitter = 0.2
+	for i := 0; i < 100; i++ {
+		delay := p.backoff(1)
+		c.Assert(delay >= 16*time.Millisecond && delay <= 20*time.Millisecond, gc.Equals, true, gc.Commentf("delay %s", delay))
+	}
+}
+
+// brokenConnector is a driver.Connector whose connections record the first
+// argument of the statements sent to them and then fail as if the connection
+// was reset before the result could be read.
+type brokenConnector struct {
+	firstArgs []driver.Value
+}
+
+func (bc *brokenConnector) Connect(context.Context) (driver.Conn, error) { return brokenConn{bc}, nil }
+func (bc *brokenConnector) Driver() driver.Driver                        { return nil }
+
+func (bc *brokenConnector) record(args []driver.NamedValue) {
+	var firstArg driver.Value
+	if len(args) != 0 {
+		firstArg = args[0].Value
+	}
+	bc.firstArgs = append(bc.firstArgs, firstArg)
+}
+
+type brokenConn struct {
+	connector *brokenConnector
+}
+
+func (bc brokenConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	bc.connector.record(args)
+	return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+}
+
+func (bc brokenConn) ExecContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Result, error) {
+	bc.connector.record(args)
+	return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+}
+
+func (brokenConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (brokenConn) Close() error                        { return nil }
+func (brokenConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
@@ -0,0 +1,405 @@
+package cdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// watchPollInterval is the delay between polls of the graph_events
+	// table once a watcher has caught up.
+	watchPollInterval = 500 * time.Millisecond
+
+	// watchPageSize is the maximum number of events fetched by each poll.
+	watchPageSize = 100
+
+	// maxEventIdx is the largest per-statement event index that can be
+	// encoded in a cursor.
+	maxEventIdx = 999999
+
+	// defaultEventRetention is the age after which events are trimmed from
+	// the outbox table unless configured otherwise.
+	defaultEventRetention = 7 * 24 * time.Hour
+
+	// eventTrimInterval is the delay between background runs of TrimEvents.
+	eventTrimInterval = 10 * time.Minute
+
+	// trimBatchSize is the maximum number of events removed per statement
+	// by TrimEvents.
+	trimBatchSize = 1000
+)
+
+var (
+	// Watchers read the graph_events table as of five seconds ago (see
+	// Watch).
+	watchEventsQuery = `
+SELECT commit_ts::STRING, request_id, idx, event_type, link_id, url, retrieved_at, first_seen_at, status_code, content_type, content_hash, fetch_errors, last_error,
+	edge_id, src, dst, updated_at, anchor_text, nofollow, sponsored, ugc, link_count, updated_before
+FROM graph_events AS OF SYSTEM TIME '-5s'
+WHERE (commit_ts, request_id, idx) > ($1::DECIMAL, $2::UUID, $3)
+ORDER BY commit_ts, request_id, idx
+LIMIT $4
+`
+	lastEventQuery = `
+SELECT commit_ts::STRING, request_id, idx
+FROM graph_events AS OF SYSTEM TIME '-5s'
+ORDER BY commit_ts DESC, request_id DESC, idx DESC
+LIMIT 1
+`
+	// watchBoundsQuery returns the commit timestamp before which events
+	// may have been trimmed along with the current time.
+	watchBoundsQuery = "SELECT trimmed_before::STRING, now() FROM graph_event_trims WHERE id = 1"
+
+	trimWatermarkQuery = "UPDATE graph_event_trims SET trimmed_before = GREATEST(trimmed_before, $1::DECIMAL) WHERE id = 1"
+	trimEventsQuery    = "DELETE FROM graph_events WHERE commit_ts < $1::DECIMAL LIMIT $2"
+
+	// maxUUID is the largest request ID.
+	maxUUID = uuid.Must(uuid.Parse("ffffffff-ffff-ffff-ffff-ffffffffffff"))
+
+	// Compile-time check for ensuring CockroachDbGraph implements Watcher.
+	_ graph.Watcher = (*CockroachDBGraph)(nil)
+)
+
+// hlcTimestamp is a CockroachDB hybrid logical clock timestamp as returned by
+// cluster_logical_timestamp(): a wall time in nanoseconds and a logical
+// counter that orders timestamps with the same wall time.
+type hlcTimestamp struct {
+	wallTime int64
+	logical  int64
+}
+
+// parseHLCTimestamp parses the decimal representation of a timestamp.
+func parseHLCTimestamp(s string) (hlcTimestamp, error) {
+	wall, logical := s, ""
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		wall, logical = s[:dot], s[dot+1:]
+	}
+	if len(logical) > 10 {
+		return hlcTimestamp{}, xerrors.Errorf("malformed timestamp %q", s)
+	}
+
+	var (
+		ts  hlcTimestamp
+		err error
+	)
+	if ts.wallTime, err = strconv.ParseInt(wall, 10, 64); err != nil || ts.wallTime < 0 {
+		return hlcTimestamp{}, xerrors.Errorf("malformed timestamp %q", s)
+	}
+	if logical != "" {
+		// The logical counter occupies the first ten fractional digits.
+		logical += strings.Repeat("0", 10-len(logical))
+		if ts.logical, err = strconv.ParseInt(logical, 10, 64); err != nil || ts.logical < 0 {
+			return hlcTimestamp{}, xerrors.Errorf("malformed timestamp %q", s)
+		}
+	}
+	return ts, nil
+}
+
+// String returns the decimal representation of the timestamp.
+func (ts hlcTimestamp) String() string {
+	return fmt.Sprintf("%d.%010d", ts.wallTime, ts.logical)
+}
+
+// Before returns true if ts precedes other.
+func (ts hlcTimestamp) Before(other hlcTimestamp) bool {
+	return ts.wallTime < other.wallTime || (ts.wallTime == other.wallTime && ts.logical < other.logical)
+}
+
+// eventKey is the primary key of a graph_events row. Events are ordered by
+// the commit timestamp of the statement that recorded them, then by the ID
+// of the request that issued the statement and finally by their position
+// within the statement.
+type eventKey struct {
+	commitTS  hlcTimestamp
+	requestID uuid.UUID
+	idx       int64
+}
+
+// cursor encodes the key as a graph.EventCursor. All fields are zero-padded
+// to a fixed width so cursors compare in key order.
+func (k eventKey) cursor() graph.EventCursor {
+	return graph.EventCursor(fmt.Sprintf("%019d.%010d/%s/%06d", k.commitTS.wallTime, k.commitTS.logical, k.requestID, k.idx))
+}
+
+// parseEventCursor decodes a cursor returned by eventKey.cursor. The empty
+// cursor is decoded as the zero key which precedes all events.
+func parseEventCursor(cursor graph.EventCursor) (eventKey, error) {
+	if cursor == "" {
+		return eventKey{}, nil
+	}
+
+	parts := strings.Split(string(cursor), "/")
+	if len(parts) != 3 {
+		return eventKey{}, graph.ErrInvalidEventCursor
+	}
+
+	var (
+		key eventKey
+		err error
+	)
+	if key.commitTS, err = parseHLCTimestamp(parts[0]); err != nil {
+		return eventKey{}, graph.ErrInvalidEventCursor
+	}
+	if key.requestID, err = uuid.Parse(parts[1]); err != nil {
+		return eventKey{}, graph.ErrInvalidEventCursor
+	}
+	if key.idx, err = strconv.ParseInt(parts[2], 10, 64); err != nil || key.idx < 0 || key.idx > maxEventIdx {
+		return eventKey{}, graph.ErrInvalidEventCursor
+	}
+
+	// Reject non-canonical encodings as they would not compare in key order
+	// with the cursors of the returned events.
+	if key.cursor() != cursor {
+		return eventKey{}, graph.ErrInvalidEventCursor
+	}
+	return key, nil
+}
+
+// Watch implements graph.Watcher. Events are read from the graph_events
+// outbox table which is polled once the watcher has caught up.
+//
+// Events are ordered by the commit timestamp of the statement that recorded
+// them. As a statement may commit with a timestamp that precedes the
+// timestamp of statements that committed before it, watchers read the table
+// as of five seconds ago: reading in the past waits for any pending writes in
+// the scanned range to resolve, so no event can later appear behind a
+// watcher that has moved past it. As a consequence, events are delivered to
+// watchers with a delay of about five seconds.
+//
+// Events older than the configured retention are trimmed from the outbox
+// table (see TrimEvents). Watching from a cursor whose successors may have
+// been trimmed fails with graph.ErrEventsUnavailable; so does an iterator
+// whose pending events are trimmed while it is being consumed.
+func (c *CockroachDBGraph) Watch(ctx context.Context, after graph.EventCursor) (graph.EventIterator, error) {
+	key, err := parseEventCursor(after)
+	if err != nil {
+		return nil, xerrors.Errorf("watch: %w", err)
+	}
+
+	trimmedBefore, now, err := c.watchBounds(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("watch: %w", err)
+	} else if key.commitTS.Before(trimmedBefore) || key.commitTS.wallTime > now.UnixNano() {
+		return nil, xerrors.Errorf("watch: %w", graph.ErrEventsUnavailable)
+	}
+
+	return &eventIterator{ctx: ctx, g: c, lastKey: key}, nil
+}
+
+// LastEventCursor implements graph.Watcher. The returned cursor refers to
+// the last event visible to watchers which lags behind the most recently
+// recorded event by about five seconds.
+func (c *CockroachDBGraph) LastEventCursor(ctx context.Context) (graph.EventCursor, error) {
+	trimmedBefore, _, err := c.watchBounds(ctx)
+	if err != nil {
+		return "", xerrors.Errorf("last event cursor: %w", err)
+	}
+
+	var (
+		commitTS string
+		key      eventKey
+	)
+	err = c.db.QueryRowContext(ctx, lastEventQuery).Scan(&commitTS, &key.requestID, &key.idx)
+	if err != nil && err != sql.ErrNoRows {
+		return "", xerrors.Errorf("last event cursor: %w", err)
+	} else if err == nil {
+		if key.commitTS, err = parseHLCTimestamp(commitTS); err != nil {
+			return "", xerrors.Errorf("last event cursor: %w", err)
+		}
+	}
+
+	// As the table is read in the past, the last event may already have
+	// been trimmed. In that case, point past the last key that may have been
+	// removed instead.
+	if key.commitTS.Before(trimmedBefore) {
+		key = eventKey{commitTS: trimmedBefore, requestID: maxUUID, idx: maxEventIdx}
+	}
+	return key.cursor(), nil
+}
+
+// TrimEvents removes the events recorded before the specified time from the
+// graph_events outbox table and returns the number of removed events.
+// Watchers whose cursor precedes before subsequently fail with
+// graph.ErrEventsUnavailable.
+//
+// Unless disabled via Options.EventRetention, the graph periodically trims
+// events older than the configured retention in the background.
+func (c *CockroachDBGraph) TrimEvents(ctx context.Context, before time.Time) (int, error) {
+	ts := hlcTimestamp{wallTime: before.UnixNano()}.String()
+
+	// The watermark is raised first so that watchers never miss events that
+	// are about to be removed.
+	if _, err := c.db.ExecContext(ctx, trimWatermarkQuery, ts); err != nil {
+		return 0, xerrors.Errorf("trim events: %w", err)
+	}
+
+	// Events are removed in batches to avoid running into transaction size
+	// limits.
+	var removed int
+	for {
+		res, err := c.db.ExecContext(ctx, trimEventsQuery, ts, trimBatchSize)
+		if err != nil {
+			return removed, xerrors.Errorf("trim events: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return removed, xerrors.Errorf("trim events: %w", err)
+		}
+		removed += int(n)
+		if n < trimBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+// trimEventsLoop trims events older than retention every eventTrimInterval
+// until stopCh is closed.
+func (c *CockroachDBGraph) trimEventsLoop(retention time.Duration, stopCh <-chan struct{}, doneCh chan<- struct{}) {
+	defer close(doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(eventTrimInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Errors are not reported as there is no caller to report them
+			// to; the next run picks up where a failed run stopped.
+			_, _ = c.TrimEvents(ctx, time.Now().Add(-retention))
+		}
+	}
+}
+
+// watchBounds returns the commit timestamp before which events may have been
+// trimmed and the current time.
+func (c *CockroachDBGraph) watchBounds(ctx context.Context) (hlcTimestamp, time.Time, error) {
+	var (
+		trimmedBefore string
+		now           time.Time
+	)
+	if err := c.db.QueryRowContext(ctx, watchBoundsQuery).Scan(&trimmedBefore, &now); err != nil {
+		return hlcTimestamp{}, time.Time{}, err
+	}
+	ts, err := parseHLCTimestamp(trimmedBefore)
+	if err != nil {
+		return hlcTimestamp{}, time.Time{}, err
+	}
+	return ts, now, nil
+}
+
+// fetchEvents returns the next page of events following after along with
+// their keys.
+func (c *CockroachDBGraph) fetchEvents(ctx context.Context, after eventKey) ([]*graph.Event, []eventKey, error) {
+	rows, err := c.db.QueryContext(ctx, watchEventsQuery, after.commitTS.String(), after.requestID, after.idx, watchPageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var (
+		events []*graph.Event
+		keys   []eventKey
+	)
+	for rows.Next() {
+		ev, key, err := scanEvent(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, ev)
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	if err = rows.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	// The events following after may have been trimmed while the page was
+	// being read; the watermark is checked last so such gaps are detected.
+	trimmedBefore, _, err := c.watchBounds(ctx)
+	if err != nil {
+		return nil, nil, err
+	} else if after.commitTS.Before(trimmedBefore) {
+		return nil, nil, graph.ErrEventsUnavailable
+	}
+	return events, keys, nil
+}
+
+// scanEvent converts a graph_events row into an event. Only the columns that
+// are relevant to the event type are populated.
+func scanEvent(rows *sql.Rows) (*graph.Event, eventKey, error) {
+	var (
+		ev                                                 = new(graph.Event)
+		key                                                eventKey
+		commitTS                                           string
+		eventType                                          int
+		linkID, edgeID, src, dst                           uuid.NullUUID
+		url, contentType, contentHash, lastError, anchor   sql.NullString
+		retrievedAt, firstSeenAt, updatedAt, updatedBefore sql.NullTime
+		statusCode, fetchErrors, linkCount                 sql.NullInt64
+		noFollow, sponsored, ugc                           sql.NullBool
+	)
+	err := rows.Scan(&commitTS, &key.requestID, &key.idx, &eventType, &linkID, &url, &retrievedAt, &firstSeenAt, &statusCode, &contentType, &contentHash, &fetchErrors, &lastError,
+		&edgeID, &src, &dst, &updatedAt, &anchor, &noFollow, &sponsored, &ugc, &linkCount, &updatedBefore)
+	if err != nil {
+		return nil, eventKey{}, err
+	}
+	if key.commitTS, err = parseHLCTimestamp(commitTS); err != nil {
+		return nil, eventKey{}, err
+	}
+
+	ev.Cursor = key.cursor()
+	ev.Type = graph.EventType(eventType)
+	switch ev.Type {
+	case graph.EventLinkUpserted:
+		ev.Link = &graph.Link{
+			ID:          linkID.UUID,
+			URL:         url.String,
+			RetrievedAt: retrievedAt.Time.UTC(),
+			FirstSeenAt: firstSeenAt.Time.UTC(),
+			StatusCode:  int(statusCode.Int64),
+			ContentType: contentType.String,
+			ContentHash: contentHash.String,
+			FetchErrors: int(fetchErrors.Int64),
+			LastError:   lastError.String,
+		}
+	case graph.EventEdgeUpserted:
+		ev.Edge = &graph.Edge{
+			ID:         edgeID.UUID,
+			Src:        src.UUID,
+			Dst:        dst.UUID,
+			UpdatedAt:  updatedAt.Time.UTC(),
+			AnchorText: anchor.String,
+			NoFollow:   noFollow.Bool,
+			Sponsored:  sponsored.Bool,
+			UGC:        ugc.Bool,
+			LinkCount:  int(linkCount.Int64),
+		}
+	case graph.EventStaleEdgesRemoved:
+		ev.LinkID = linkID.UUID
+		ev.UpdatedBefore = updatedBefore.Time.UTC()
+	case graph.EventLinkRemoved:
+		ev.LinkID = linkID.UUID
+	}
+	return ev, key, nil
+}
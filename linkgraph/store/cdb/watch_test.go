@@ -0,0 +1,65 @@
+package cdb
+
+import (
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/google/uuid"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(EventCursorTestSuite))
+
+type EventCursorTestSuite struct{}
+
+// TestParseHLCTimestamp verifies the parsing of commit timestamps returned by
+// cluster_logical_timestamp().
+func (s *EventCursorTestSuite) TestParseHLCTimestamp(c *gc.C) {
+	specs := []struct {
+		in  string
+		exp hlcTimestamp
+	}{
+		{in: "1697000000123456789", exp: hlcTimestamp{wallTime: 1697000000123456789}},
+		{in: "1697000000123456789.0000000000", exp: hlcTimestamp{wallTime: 1697000000123456789}},
+		{in: "1697000000123456789.0000000002", exp: hlcTimestamp{wallTime: 1697000000123456789, logical: 2}},
+		{in: "1697000000123456789.00000001", exp: hlcTimestamp{wallTime: 1697000000123456789, logical: 100}},
+	}
+	for _, spec := range specs {
+		ts, err := parseHLCTimestamp(spec.in)
+		c.Assert(err, gc.IsNil, gc.Commentf("timestamp %q", spec.in))
+		c.Assert(ts, gc.Equals, spec.exp, gc.Commentf("timestamp %q", spec.in))
+	}
+
+	for _, in := range []string{"", "foo", "-1.0000000000", "1.00000000001", "1.-000000001"} {
+		_, err := parseHLCTimestamp(in)
+		c.Assert(err, gc.NotNil, gc.Commentf("timestamp %q", in))
+	}
+}
+
+// TestEventCursor verifies that event keys survive a round trip through
+// their cursor and that cursors compare in key order.
+func (s *EventCursorTestSuite) TestEventCursor(c *gc.C) {
+	keys := []eventKey{
+		{},
+		{commitTS: hlcTimestamp{wallTime: 9}, requestID: maxUUID, idx: maxEventIdx},
+		{commitTS: hlcTimestamp{wallTime: 10}, requestID: uuid.New(), idx: 1},
+		{commitTS: hlcTimestamp{wallTime: 10, logical: 1}, requestID: uuid.Nil, idx: 1},
+		{commitTS: hlcTimestamp{wallTime: 1697000000123456789, logical: 3}, requestID: maxUUID, idx: 2},
+	}
+	for i, key := range keys {
+		got, err := parseEventCursor(key.cursor())
+		c.Assert(err, gc.IsNil)
+		c.Assert(got, gc.DeepEquals, key)
+		if i > 0 {
+			c.Assert(keys[i-1].cursor() < key.cursor(), gc.Equals, true, gc.Commentf("cursor %q", key.cursor()))
+		}
+	}
+
+	key, err := parseEventCursor("")
+	c.Assert(err, gc.IsNil)
+	c.Assert(key, gc.DeepEquals, eventKey{})
+
+	valid := keys[2].cursor()
+	for _, cursor := range []graph.EventCursor{"foo", "1/2/3", valid + "/1", valid[1:], valid[:len(valid)-1]} {
+		_, err = parseEventCursor(cursor)
+		c.Assert(err, gc.Equals, graph.ErrInvalidEventCursor, gc.Commentf("cursor %q", cursor))
+	}
+}
@@ -0,0 +1,224 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// maxStringLen guards against allocating huge buffers when decoding a
+// corrupted length prefix.
+const maxStringLen = 1 << 20
+
+// errTruncated is reported by decoder when the underlying stream ends
+// before a value could be fully read.
+var errTruncated = xerrors.New("truncated stream")
+
+// encoder writes the primitives used by the snapshot and WAL record
+// formats. All integers are encoded in big-endian order. The first
+// encountered error is latched and all subsequent writes become no-ops.
+type encoder struct {
+	w   *bufio.Writer
+	buf [8]byte
+	err error
+}
+
+func newEncoder(w io.Writer) *encoder {
+	return &encoder{w: bufio.NewWriter(w)}
+}
+
+func (e *encoder) writeBytes(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *encoder) writeUint8(v uint8) {
+	e.buf[0] = v
+	e.writeBytes(e.buf[:1])
+}
+
+func (e *encoder) writeBool(v bool) {
+	if v {
+		e.writeUint8(1)
+		return
+	}
+	e.writeUint8(0)
+}
+
+func (e *encoder) writeUint16(v uint16) {
+	binary.BigEndian.PutUint16(e.buf[:2], v)
+	e.writeBytes(e.buf[:2])
+}
+
+func (e *encoder) writeUint64(v uint64) {
+	binary.BigEndian.PutUint64(e.buf[:8], v)
+	e.writeBytes(e.buf[:8])
+}
+
+func (e *encoder) writeUUID(id uuid.UUID) {
+	e.writeBytes(id[:])
+}
+
+func (e *encoder) writeString(v string) {
+	e.writeUint64(uint64(len(v)))
+	e.writeBytes([]byte(v))
+}
+
+func (e *encoder) writeTime(t time.Time) {
+	e.writeUint64(uint64(t.Unix()))
+	e.writeUint64(uint64(t.Nanosecond()))
+}
+
+// writeLink encodes all fields of link.
+func (e *encoder) writeLink(link *graph.Link) {
+	e.writeUUID(link.ID)
+	e.writeString(link.URL)
+	e.writeTime(link.RetrievedAt)
+	e.writeTime(link.FirstSeenAt)
+	e.writeUint64(uint64(link.StatusCode))
+	e.writeString(link.ContentType)
+	e.writeString(link.ContentHash)
+	e.writeUint64(uint64(link.FetchErrors))
+	e.writeString(link.LastError)
+}
+
+// writeEdge encodes all fields of edge.
+func (e *encoder) writeEdge(edge *graph.Edge) {
+	e.writeUUID(edge.ID)
+	e.writeUUID(edge.Src)
+	e.writeUUID(edge.Dst)
+	e.writeTime(edge.UpdatedAt)
+	e.writeString(edge.AnchorText)
+	e.writeBool(edge.NoFollow)
+	e.writeBool(edge.Sponsored)
+	e.writeBool(edge.UGC)
+	e.writeUint64(uint64(edge.LinkCount))
+}
+
+// flush writes any buffered data to the underlying writer and returns the
+// first error encountered by the encoder.
+func (e *encoder) flush() error {
+	if e.err == nil {
+		e.err = e.w.Flush()
+	}
+	return e.err
+}
+
+// decoder reads the primitives written by encoder. The first encountered
+// error is latched and all subsequent reads return zero values.
+type decoder struct {
+	r   io.Reader
+	buf [8]byte
+	err error
+}
+
+func newDecoder(r io.Reader) *decoder {
+	return &decoder{r: r}
+}
+
+func (d *decoder) readFull(b []byte) {
+	if d.err != nil {
+		return
+	}
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = errTruncated
+		}
+		d.err = err
+	}
+}
+
+func (d *decoder) readBytes(n int) []byte {
+	b := make([]byte, n)
+	d.readFull(b)
+	return b
+}
+
+func (d *decoder) readUint8() uint8 {
+	d.readFull(d.buf[:1])
+	if d.err != nil {
+		return 0
+	}
+	return d.buf[0]
+}
+
+func (d *decoder) readBool() bool {
+	return d.readUint8() != 0
+}
+
+func (d *decoder) readUint16() uint16 {
+	d.readFull(d.buf[:2])
+	if d.err != nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(d.buf[:2])
+}
+
+func (d *decoder) readUint64() uint64 {
+	d.readFull(d.buf[:8])
+	if d.err != nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(d.buf[:8])
+}
+
+func (d *decoder) readUUID() uuid.UUID {
+	var id uuid.UUID
+	d.readFull(id[:])
+	return id
+}
+
+func (d *decoder) readString() string {
+	n := d.readUint64()
+	if d.err == nil && n > maxStringLen {
+		d.err = xerrors.Errorf("string length %d exceeds limit", n)
+	}
+	if d.err != nil {
+		return ""
+	}
+	return string(d.readBytes(int(n)))
+}
+
+func (d *decoder) readTime() time.Time {
+	sec, nsec := int64(d.readUint64()), int64(d.readUint64())
+	if d.err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, nsec).UTC()
+}
+
+// readLink decodes a link written by encoder.writeLink.
+func (d *decoder) readLink() *graph.Link {
+	return &graph.Link{
+		ID:          d.readUUID(),
+		URL:         d.readString(),
+		RetrievedAt: d.readTime(),
+		FirstSeenAt: d.readTime(),
+		StatusCode:  int(d.readUint64()),
+		ContentType: d.readString(),
+		ContentHash: d.readString(),
+		FetchErrors: int(d.readUint64()),
+		LastError:   d.readString(),
+	}
+}
+
+// readEdge decodes an edge written by encoder.writeEdge.
+func (d *decoder) readEdge() *graph.Edge {
+	return &graph.Edge{
+		ID:         d.readUUID(),
+		Src:        d.readUUID(),
+		Dst:        d.readUUID(),
+		UpdatedAt:  d.readTime(),
+		AnchorText: d.readString(),
+		NoFollow:   d.readBool(),
+		Sponsored:  d.readBool(),
+		UGC:        d.readBool(),
+		LinkCount:  int(d.readUint64()),
+	}
+}
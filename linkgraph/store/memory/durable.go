@@ -0,0 +1,284 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/internal/atomicfile"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory/wal"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// WAL record types.
+const (
+	opUpsertLink uint8 = iota + 1
+	opUpsertEdge
+	opRemoveStaleEdges
+	opRemoveLink
+
+	// opUpsertLinkWithMetadata supersedes opUpsertLink and also records
+	// the crawl metadata of the link. Records of the old type are still
+	// replayed.
+	opUpsertLinkWithMetadata
+
+	// opUpsertEdgeWithAttributes supersedes opUpsertEdge and also records
+	// the attributes of the edge. Records of the old type are still
+	// replayed.
+	opUpsertEdgeWithAttributes
+)
+
+const (
+	walDir         = "wal"
+	snapshotPrefix = "snapshot-"
+	snapshotExt    = ".snap"
+)
+
+// ErrNotDurable is returned when attempting to checkpoint a graph that is
+// not backed by a write-ahead log.
+var ErrNotDurable = xerrors.New("graph is not backed by a write-ahead log")
+
+// NewDurableInMemoryGraph creates an in-memory link graph whose mutations
+// are recorded in a write-ahead log stored in dir.
+//
+// On startup, the most recent snapshot created by Checkpoint (if any) is
+// restored and all WAL records appended after it are replayed.
+func NewDurableInMemoryGraph(dir string, opts wal.Options) (*InMemoryGraph, error) {
+	s := NewInMemoryGraph()
+	s.dir = dir
+
+	snapSeq, err := s.restoreLatestSnapshot()
+	if err != nil {
+		return nil, xerrors.Errorf("open durable graph: %w", err)
+	}
+
+	log, err := wal.Open(filepath.Join(dir, walDir), opts)
+	if err != nil {
+		return nil, xerrors.Errorf("open durable graph: %w", err)
+	}
+
+	if log.LastSeq() < snapSeq {
+		_ = log.Close()
+		return nil, xerrors.Errorf("open durable graph: WAL ends at record %d but snapshot covers up to record %d", log.LastSeq(), snapSeq)
+	}
+
+	if err = log.Replay(snapSeq+1, s.replayRecord); err != nil {
+		_ = log.Close()
+		return nil, xerrors.Errorf("open durable graph: %w", err)
+	}
+
+	// Event sequence numbers continue from the last WAL record so that
+	// they match the sequence numbers of the records appended from now on.
+	s.events.reset(log.LastSeq())
+	s.wal = log
+	return s, nil
+}
+
+// Checkpoint writes a snapshot of the graph to the data directory and
+// removes the WAL segments that are fully covered by it, bounding both the
+// disk usage of the WAL and the time needed to replay it on startup.
+func (s *InMemoryGraph) Checkpoint() error {
+	if s.wal == nil {
+		return xerrors.Errorf("checkpoint: %w", ErrNotDurable)
+	}
+
+	state := s.copyState()
+	target := snapshotPath(s.dir, state.walSeq)
+	if err := atomicfile.Write(target, func(w io.Writer) error { return writeSnapshot(w, state) }); err != nil {
+		return xerrors.Errorf("checkpoint: %w", err)
+	}
+
+	// Older snapshots are superseded by the one we just wrote.
+	seqs, err := listSnapshots(s.dir)
+	if err != nil {
+		return xerrors.Errorf("checkpoint: %w", err)
+	}
+	for _, seq := range seqs {
+		if seq < state.walSeq {
+			_ = os.Remove(snapshotPath(s.dir, seq))
+		}
+	}
+
+	if err = s.wal.TruncateBefore(state.walSeq + 1); err != nil {
+		return xerrors.Errorf("checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the write-ahead log, if one is attached.
+func (s *InMemoryGraph) Close() error {
+	if s.wal == nil {
+		return nil
+	}
+	return s.wal.Close()
+}
+
+// restoreLatestSnapshot restores the most recent snapshot in the data
+// directory and returns the sequence number of the last WAL record that it
+// covers.
+func (s *InMemoryGraph) restoreLatestSnapshot() (uint64, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return 0, err
+	}
+
+	seqs, err := listSnapshots(s.dir)
+	if err != nil || len(seqs) == 0 {
+		return 0, err
+	}
+
+	latest := seqs[len(seqs)-1]
+	f, err := os.Open(snapshotPath(s.dir, latest))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	if err = s.Restore(f); err != nil {
+		return 0, err
+	}
+	return latest, nil
+}
+
+func (s *InMemoryGraph) logUpsertLink(link *graph.Link) error {
+	return s.appendWAL(func(enc *encoder) {
+		enc.writeUint8(opUpsertLinkWithMetadata)
+		enc.writeLink(link)
+	})
+}
+
+func (s *InMemoryGraph) logUpsertEdge(edge *graph.Edge) error {
+	return s.appendWAL(func(enc *encoder) {
+		enc.writeUint8(opUpsertEdgeWithAttributes)
+		enc.writeEdge(edge)
+	})
+}
+
+func (s *InMemoryGraph) logRemoveStaleEdges(fromID uuid.UUID, updatedBefore time.Time) error {
+	return s.appendWAL(func(enc *encoder) {
+		enc.writeUint8(opRemoveStaleEdges)
+		enc.writeUUID(fromID)
+		enc.writeTime(updatedBefore)
+	})
+}
+
+func (s *InMemoryGraph) logRemoveLink(id uuid.UUID) error {
+	return s.appendWAL(func(enc *encoder) {
+		enc.writeUint8(opRemoveLink)
+		enc.writeUUID(id)
+	})
+}
+
+// appendWAL encodes a record and appends it to the WAL. It is a no-op if
+// the graph is not backed by a WAL. The caller must hold the write lock so
+// that the order of the records in the log matches the order in which the
+// mutations are applied.
+func (s *InMemoryGraph) appendWAL(encodeFn func(*encoder)) error {
+	if s.wal == nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := newEncoder(&buf)
+	encodeFn(enc)
+	if err := enc.flush(); err != nil {
+		return err
+	}
+
+	_, err := s.wal.Append(buf.Bytes())
+	return err
+}
+
+// replayRecord applies a mutation that was previously recorded in the WAL.
+func (s *InMemoryGraph) replayRecord(seq uint64, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dec := newDecoder(bytes.NewReader(payload))
+	switch op := dec.readUint8(); op {
+	case opUpsertLink:
+		link := &graph.Link{
+			ID:          dec.readUUID(),
+			URL:         dec.readString(),
+			RetrievedAt: dec.readTime(),
+		}
+		if dec.err == nil {
+			s.applyUpsertLink(link)
+		}
+	case opUpsertLinkWithMetadata:
+		link := dec.readLink()
+		if dec.err == nil {
+			s.applyUpsertLink(link)
+		}
+	case opUpsertEdge:
+		edge := &graph.Edge{
+			ID:        dec.readUUID(),
+			Src:       dec.readUUID(),
+			Dst:       dec.readUUID(),
+			UpdatedAt: dec.readTime(),
+			LinkCount: 1,
+		}
+		if dec.err == nil {
+			s.applyUpsertEdge(edge)
+		}
+	case opUpsertEdgeWithAttributes:
+		edge := dec.readEdge()
+		if dec.err == nil {
+			s.applyUpsertEdge(edge)
+		}
+	case opRemoveStaleEdges:
+		fromID, updatedBefore := dec.readUUID(), dec.readTime()
+		if dec.err == nil {
+			s.applyRemoveStaleEdges(fromID, updatedBefore)
+		}
+	case opRemoveLink:
+		id := dec.readUUID()
+		if dec.err == nil {
+			s.applyRemoveLink(id)
+		}
+	default:
+		if dec.err == nil {
+			dec.err = xerrors.Errorf("unknown record type %d", op)
+		}
+	}
+
+	if dec.err != nil {
+		return xerrors.Errorf("replay record %d: %w", seq, dec.err)
+	}
+	return nil
+}
+
+func snapshotPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%020d%s", snapshotPrefix, seq, snapshotExt))
+}
+
+// listSnapshots returns the WAL sequence numbers of the snapshots in dir in
+// ascending order.
+func listSnapshots(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// ReadDir returns entries sorted by name and snapshot names embed a
+	// zero-padded sequence number so they are already in the right order.
+	var seqs []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), snapshotExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	return seqs, nil
+}
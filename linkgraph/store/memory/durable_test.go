@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory/wal"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(DurableInMemoryGraphTestSuite))
+
+type DurableInMemoryGraphTestSuite struct {
+	graphtest.SuiteBase
+	dir string
+	g   *InMemoryGraph
+}
+
+func (s *DurableInMemoryGraphTestSuite) SetUpTest(c *gc.C) {
+	s.dir = c.MkDir()
+	s.g = s.open(c)
+	s.SetGraph(s.g)
+}
+
+func (s *DurableInMemoryGraphTestSuite) TearDownTest(c *gc.C) {
+	c.Assert(s.g.Close(), gc.IsNil)
+}
+
+func (s *DurableInMemoryGraphTestSuite) open(c *gc.C) *InMemoryGraph {
+	g, err := NewDurableInMemoryGraph(s.dir, wal.Options{SyncPolicy: wal.SyncNever})
+	c.Assert(err, gc.IsNil)
+	return g
+}
+
+// TestReplayAfterRestart verifies that all mutations are replayed from the
+// WAL when the graph is re-opened.
+func (s *DurableInMemoryGraphTestSuite) TestReplayAfterRestart(c *gc.C) {
+	links := s.populate(c)
+
+	c.Assert(s.g.Close(), gc.IsNil)
+	s.g = s.open(c)
+	s.assertPopulated(c, links)
+}
+
+// TestReplayAfterCheckpoint verifies that mutations are restored from a
+// combination of a checkpoint and the WAL records that follow it.
+func (s *DurableInMemoryGraphTestSuite) TestReplayAfterCheckpoint(c *gc.C) {
+	links := s.populate(c)
+	c.Assert(s.g.Checkpoint(), gc.IsNil)
+
+	extra := &graph.Link{URL: "https://example.com/extra"}
+	c.Assert(s.g.UpsertLink(extra), gc.IsNil)
+
+	c.Assert(s.g.Close(), gc.IsNil)
+	s.g = s.open(c)
+	s.assertPopulated(c, links)
+
+	_, err := s.g.FindLink(extra.ID)
+	c.Assert(err, gc.IsNil)
+}
+
+// TestReplayLinkMetadata verifies that the crawl metadata of links is
+// replayed from the WAL when the graph is re-opened.
+func (s *DurableInMemoryGraphTestSuite) TestReplayLinkMetadata(c *gc.C) {
+	link := &graph.Link{
+		URL:         "https://example.com",
+		RetrievedAt: time.Now().Truncate(time.Second).UTC(),
+		StatusCode:  500,
+		ContentType: "text/html",
+		ContentHash: "abc",
+		FetchErrors: 2,
+		LastError:   "internal server error",
+	}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+
+	c.Assert(s.g.Close(), gc.IsNil)
+	s.g = s.open(c)
+
+	got, err := s.g.FindLink(link.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(got, gc.DeepEquals, link)
+}
+
+// TestReplayRemoveLink verifies that link removals are replayed from the
+// WAL when the graph is re-opened.
+func (s *DurableInMemoryGraphTestSuite) TestReplayRemoveLink(c *gc.C) {
+	src := &graph.Link{URL: "https://example.com/src"}
+	dst := &graph.Link{URL: "https://example.com/dst"}
+	c.Assert(s.g.UpsertLinks([]*graph.Link{src, dst}), gc.IsNil)
+	c.Assert(s.g.UpsertEdge(&graph.Edge{Src: src.ID, Dst: dst.ID}), gc.IsNil)
+	c.Assert(s.g.RemoveLink(dst.ID), gc.IsNil)
+
+	c.Assert(s.g.Close(), gc.IsNil)
+	s.g = s.open(c)
+
+	_, err := s.g.FindLink(dst.ID)
+	c.Assert(xerrors.Is(err, graph.ErrNotFound), gc.Equals, true)
+	_, err = s.g.FindLink(src.ID)
+	c.Assert(err, gc.IsNil)
+
+	it, err := s.g.Edges(uuid.Nil, uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff"), time.Now())
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(it.Close(), gc.IsNil)
+}
+
+// TestCheckpointNonDurableGraph verifies that checkpointing is rejected for
+// graphs that are not backed by a WAL.
+func (s *DurableInMemoryGraphTestSuite) TestCheckpointNonDurableGraph(c *gc.C) {
+	c.Assert(NewInMemoryGraph().Checkpoint(), gc.ErrorMatches, ".*"+ErrNotDurable.Error())
+}
+
+// TestRestoreDurableGraph verifies that restoring a snapshot into a graph
+// that is backed by a WAL is rejected and leaves the graph untouched.
+func (s *DurableInMemoryGraphTestSuite) TestRestoreDurableGraph(c *gc.C) {
+	links := s.populate(c)
+
+	var buf bytes.Buffer
+	c.Assert(NewInMemoryGraph().Snapshot(&buf), gc.IsNil)
+	err := s.g.Restore(&buf)
+	c.Assert(xerrors.Is(err, ErrRestoreDurable), gc.Equals, true, gc.Commentf("%v", err))
+	s.assertPopulated(c, links)
+}
+
+// TestEventSeqAfterRestart verifies that event cursors match the WAL
+// sequence numbers and continue where they left off after the graph is
+// re-opened.
+func (s *DurableInMemoryGraphTestSuite) TestEventSeqAfterRestart(c *gc.C) {
+	s.populate(c)
+	lastSeq := s.g.wal.LastSeq()
+	cursor, err := s.g.LastEventCursor(context.TODO())
+	c.Assert(err, gc.IsNil)
+	c.Assert(cursor, gc.Equals, seqCursor(lastSeq))
+
+	c.Assert(s.g.Close(), gc.IsNil)
+	s.g = s.open(c)
+
+	reopenedCursor, err := s.g.LastEventCursor(context.TODO())
+	c.Assert(err, gc.IsNil)
+	c.Assert(reopenedCursor, gc.Equals, cursor)
+
+	// Replayed mutations are not available to watchers.
+	_, err = s.g.Watch(context.TODO(), seqCursor(lastSeq-1))
+	c.Assert(xerrors.Is(err, graph.ErrEventsUnavailable), gc.Equals, true, gc.Commentf("%v", err))
+
+	link := &graph.Link{URL: "https://example.com/extra"}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+
+	it, err := s.g.Watch(context.TODO(), cursor)
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Event().Cursor, gc.Equals, seqCursor(lastSeq+1))
+	c.Assert(it.Event().Link.ID, gc.Equals, link.ID)
+	c.Assert(it.Close(), gc.IsNil)
+}
+
+// populate creates a star of links around links[0], with half of the edges
+// subsequently removed as stale.
+func (s *DurableInMemoryGraphTestSuite) populate(c *gc.C) []*graph.Link {
+	links := make([]*graph.Link, 10)
+	for i := range links {
+		links[i] = &graph.Link{URL: fmt.Sprint(i), RetrievedAt: time.Now()}
+		c.Assert(s.g.UpsertLink(links[i]), gc.IsNil)
+	}
+
+	var removeBefore time.Time
+	for i := 1; i < len(links); i++ {
+		if i == len(links)/2 {
+			removeBefore = time.Now()
+			time.Sleep(time.Millisecond)
+		}
+		c.Assert(s.g.UpsertEdge(&graph.Edge{Src: links[0].ID, Dst: links[i].ID}), gc.IsNil)
+	}
+	c.Assert(s.g.RemoveStaleEdges(links[0].ID, removeBefore), gc.IsNil)
+	return links
+}
+
+func (s *DurableInMemoryGraphTestSuite) assertPopulated(c *gc.C, links []*graph.Link) {
+	for _, link := range links {
+		got, err := s.g.FindLink(link.ID)
+		c.Assert(err, gc.IsNil)
+		c.Assert(got.URL, gc.Equals, link.URL)
+		c.Assert(got.RetrievedAt.Equal(link.RetrievedAt), gc.Equals, true)
+	}
+
+	it, err := s.g.Edges(uuid.Nil, uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff"), time.Now())
+	c.Assert(err, gc.IsNil)
+	dsts := make(map[uuid.UUID]bool)
+	for it.Next() {
+		dsts[it.Edge().Dst] = true
+	}
+	c.Assert(it.Close(), gc.IsNil)
+
+	c.Assert(dsts, gc.HasLen, len(links)/2)
+	for i := len(links) / 2; i < len(links); i++ {
+		c.Assert(dsts[links[i].ID], gc.Equals, true)
+	}
+}
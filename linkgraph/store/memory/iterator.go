@@ -1,79 +1,185 @@
 package memory
 
-import "github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+import (
+	"context"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+)
 
 // linkIterator is a graph.LinkIterator implementation for the in-memory graph.
+// Links are fetched in pages so that the graph's read lock is never held
+// while the caller is processing results.
 type linkIterator struct {
-	s *InMemoryGraph
+	ctx context.Context
+
+	// fetchPage returns copies of the next page of links and a flag
+	// indicating whether more pages are available.
+	fetchPage func() ([]*graph.Link, bool)
+	more      bool
 
-	links    []*graph.Link
-	curIndex int
+	page        []*graph.Link
+	pageIdx     int
+	latchedLink *graph.Link
+	lastErr     error
 }
 
 // Next implements graph.LinkIterator.
 func (i *linkIterator) Next() bool {
-	if i.curIndex >= len(i.links) {
+	if i.lastErr != nil {
 		return false
 	}
-	i.curIndex++
+	if i.lastErr = i.ctx.Err(); i.lastErr != nil {
+		return false
+	}
+
+	for i.pageIdx >= len(i.page) {
+		if !i.more {
+			return false
+		}
+		i.page, i.more = i.fetchPage()
+		i.pageIdx = 0
+	}
+
+	i.latchedLink = i.page[i.pageIdx]
+	i.pageIdx++
 	return true
 }
 
 // Error implements graph.LinkIterator.
 func (i *linkIterator) Error() error {
-	return nil
+	return i.lastErr
 }
 
 // Close implements graph.LinkIterator.
 func (i *linkIterator) Close() error {
+	i.page, i.more = nil, false
 	return nil
 }
 
 // Link implements graph.LinkIterator.
 func (i *linkIterator) Link() *graph.Link {
-	// The link pointer contents may be overwritten by a graph update; to
-	// avoid data-races we acquire the read lock first and clone the link
-	i.s.mu.RLock()
-	link := new(graph.Link)
-	*link = *i.links[i.curIndex-1]
-	i.s.mu.RUnlock()
-	return link
+	return i.latchedLink
 }
 
 // edgeIterator is a graph.EdgeIterator implementation for the in-memory graph.
+// Edges are fetched in pages so that the graph's read lock is never held
+// while the caller is processing results.
 type edgeIterator struct {
-	s *InMemoryGraph
+	ctx context.Context
 
-	edges    []*graph.Edge
-	curIndex int
+	// fetchPage returns copies of the next page of edges and a flag
+	// indicating whether more pages are available.
+	fetchPage func() ([]*graph.Edge, bool)
+	more      bool
+
+	page        []*graph.Edge
+	pageIdx     int
+	latchedEdge *graph.Edge
+	lastErr     error
 }
 
-// Next implements graph.LinkIterator.
+// Next implements graph.EdgeIterator.
 func (i *edgeIterator) Next() bool {
-	if i.curIndex >= len(i.edges) {
+	if i.lastErr != nil {
 		return false
 	}
-	i.curIndex++
+	if i.lastErr = i.ctx.Err(); i.lastErr != nil {
+		return false
+	}
+
+	for i.pageIdx >= len(i.page) {
+		if !i.more {
+			return false
+		}
+		i.page, i.more = i.fetchPage()
+		i.pageIdx = 0
+	}
+
+	i.latchedEdge = i.page[i.pageIdx]
+	i.pageIdx++
 	return true
 }
 
-// Error implements graph.LinkIterator.
+// Error implements graph.EdgeIterator.
 func (i *edgeIterator) Error() error {
-	return nil
+	return i.lastErr
 }
 
-// Close implements graph.LinkIterator.
+// Close implements graph.EdgeIterator.
 func (i *edgeIterator) Close() error {
+	i.page, i.more = nil, false
 	return nil
 }
 
-// Link implements graph.LinkIterator.
+// Edge implements graph.EdgeIterator.
 func (i *edgeIterator) Edge() *graph.Edge {
-	// The edge pointer contents may be overwritten by a graph update; to
-	// avoid data-races we acquire the read lock first and clone the edge
-	i.s.mu.RLock()
-	edge := new(graph.Edge)
-	*edge = *i.edges[i.curIndex-1]
-	i.s.mu.RUnlock()
-	return edge
+	return i.latchedEdge
+}
+
+// eventIterator is a graph.EventIterator implementation for the in-memory
+// graph. Once the retained events are exhausted, Next blocks until the next
+// event is published or the context is cancelled.
+type eventIterator struct {
+	ctx context.Context
+
+	// fetchPage returns copies of the next page of events following
+	// afterSeq and a channel that is closed when a new event is
+	// published.
+	fetchPage func(afterSeq uint64) ([]*graph.Event, <-chan struct{}, error)
+	lastSeq   uint64
+
+	page         []*graph.Event
+	pageIdx      int
+	latchedEvent *graph.Event
+	lastErr      error
+	closed       bool
+}
+
+// Next implements graph.EventIterator.
+func (i *eventIterator) Next() bool {
+	if i.lastErr != nil || i.closed {
+		return false
+	}
+
+	for i.pageIdx >= len(i.page) {
+		if i.lastErr = i.ctx.Err(); i.lastErr != nil {
+			return false
+		}
+
+		var updatedCh <-chan struct{}
+		i.page, updatedCh, i.lastErr = i.fetchPage(i.lastSeq)
+		if i.lastErr != nil {
+			return false
+		}
+		i.pageIdx = 0
+
+		if len(i.page) == 0 {
+			select {
+			case <-i.ctx.Done():
+			case <-updatedCh:
+			}
+		}
+	}
+
+	// Pages hold consecutive events following lastSeq.
+	i.latchedEvent = i.page[i.pageIdx]
+	i.lastSeq++
+	i.pageIdx++
+	return true
+}
+
+// Error implements graph.EventIterator.
+func (i *eventIterator) Error() error {
+	return i.lastErr
+}
+
+// Close implements graph.EventIterator.
+func (i *eventIterator) Close() error {
+	i.page, i.closed = nil, true
+	return nil
+}
+
+// Event implements graph.EventIterator.
+func (i *eventIterator) Event() *graph.Event {
+	return i.latchedEvent
 }
@@ -1,11 +1,16 @@
 package memory
 
 import (
+	"bytes"
+	"context"
 	"sync"
 	"time"
 
 	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory/wal"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
 
+	"github.com/google/btree"
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 )
@@ -15,6 +20,19 @@ var _ graph.Graph = (*InMemoryGraph)(nil)
 
 type edgeList []uuid.UUID
 
+// hostLinkMap maps canonical host names to the links whose URLs refer to
+// them.
+type hostLinkMap map[string]map[uuid.UUID]*graph.Link
+
+const (
+	// linkIndexDegree is the degree of the B-tree that orders links by ID.
+	linkIndexDegree = 32
+
+	// pageSize is the maximum number of items that iterators visit while
+	// holding the read lock.
+	pageSize = 100
+)
+
 // InMemoryGraph implements an in-memory link graph that can be concurrently accessed by multiple clients.
 type InMemoryGraph struct {
 	mu sync.RWMutex
@@ -22,54 +40,191 @@ type InMemoryGraph struct {
 	links map[uuid.UUID]*graph.Link
 	edges map[uuid.UUID]*graph.Edge
 
+	// linkIndex orders links by ID so that partition range scans do not
+	// need to visit every link in the graph.
+	linkIndex *btree.BTreeG[*graph.Link]
+
 	linkURLIndex map[string]*graph.Link
 	linkEdgeMap  map[uuid.UUID]edgeList
+
+	// linkHostMap indexes links by the host that their URL refers to.
+	linkHostMap hostLinkMap
+
+	// inboundEdgeMap is the reverse of linkEdgeMap; it maps link IDs to
+	// the IDs of the edges that point to them.
+	inboundEdgeMap map[uuid.UUID]edgeList
+
+	// canon converts link URLs into their canonical form before they are
+	// used for deduplication.
+	canon *urlcanon.Canonicalizer
+
+	// When set, new links are assigned the ID returned by
+	// graph.LinkIDFromURL instead of a random ID.
+	deterministicIDs bool
+
+	// events retains the most recent mutations for watchers.
+	events *eventLog
+
+	// When set, all mutations are appended to the WAL before being applied.
+	wal *wal.Log
+	dir string
 }
 
 // NewInMemoryGraph creates a new in-memory link graph.
 func NewInMemoryGraph() *InMemoryGraph {
 	return &InMemoryGraph{
-		links:        make(map[uuid.UUID]*graph.Link),
-		edges:        make(map[uuid.UUID]*graph.Edge),
-		linkURLIndex: make(map[string]*graph.Link),
-		linkEdgeMap:  make(map[uuid.UUID]edgeList),
+		links:          make(map[uuid.UUID]*graph.Link),
+		edges:          make(map[uuid.UUID]*graph.Edge),
+		linkIndex:      newLinkIndex(),
+		linkURLIndex:   make(map[string]*graph.Link),
+		linkEdgeMap:    make(map[uuid.UUID]edgeList),
+		linkHostMap:    make(hostLinkMap),
+		inboundEdgeMap: make(map[uuid.UUID]edgeList),
+		canon:          urlcanon.Default,
+		events:         newEventLog(),
 	}
 }
 
+// SetCanonicalizer configures the canonicalizer that is applied to link URLs
+// by UpsertLink and UpsertLinks. Passing a nil canonicalizer disables URL
+// canonicalization. It must be called before the graph is used.
+func (s *InMemoryGraph) SetCanonicalizer(canon *urlcanon.Canonicalizer) {
+	s.canon = canon
+}
+
+// SetDeterministicIDs configures whether new links are assigned an ID that
+// is derived from their canonical URL (see graph.LinkIDFromURL) instead of a
+// random ID. It must be called before the graph is used.
+func (s *InMemoryGraph) SetDeterministicIDs(enabled bool) {
+	s.deterministicIDs = enabled
+}
+
+// DeterministicIDs returns true if new links are assigned an ID that is
+// derived from their canonical URL.
+func (s *InMemoryGraph) DeterministicIDs() bool {
+	return s.deterministicIDs
+}
+
 // UpsertLink creates a new link or updates an existing link.
 func (s *InMemoryGraph) UpsertLink(link *graph.Link) error {
+	return s.UpsertLinkContext(context.Background(), link)
+}
+
+// UpsertLinkContext implements graph.Graph.
+func (s *InMemoryGraph) UpsertLinkContext(ctx context.Context, link *graph.Link) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("upsert link: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.upsertLink(link); err != nil {
+		return xerrors.Errorf("upsert link: %w", err)
+	}
+	return nil
+}
+
+// UpsertLinks creates or updates a batch of links.
+func (s *InMemoryGraph) UpsertLinks(links []*graph.Link) error {
+	return s.UpsertLinksContext(context.Background(), links)
+}
+
+// UpsertLinksContext implements graph.Graph.
+func (s *InMemoryGraph) UpsertLinksContext(ctx context.Context, links []*graph.Link) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("upsert links: %w", err)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	errs := make([]error, len(links))
+	for i, link := range links {
+		if err := s.upsertLink(link); err != nil {
+			errs[i] = xerrors.Errorf("upsert links: %w", err)
+		}
+	}
+	return graph.NewBatchError(errs)
+}
+
+// upsertLink logs and applies an upsert for link and updates its ID, URL
+// and FirstSeenAt fields. The caller must hold the write lock.
+func (s *InMemoryGraph) upsertLink(link *graph.Link) error {
+	canonicalURL, err := s.canon.Canonicalize(link.URL)
+	if err != nil {
+		return err
+	}
+
 	// Check if a link with the same URL already exists. If so, convert
 	// this into an update and point the link ID to the existing link.
-	if existing := s.linkURLIndex[link.URL]; existing != nil {
-		link.ID = existing.ID
-		origTs := existing.RetrievedAt
-		*existing = *link
-		if origTs.After(existing.RetrievedAt) {
-			existing.RetrievedAt = origTs
+	stored := *link
+	stored.URL = canonicalURL
+	if existing := s.linkURLIndex[stored.URL]; existing != nil {
+		// Unless the upserted link is more recent, the stored link
+		// (including its crawl metadata) is retained.
+		if !stored.RetrievedAt.After(existing.RetrievedAt) {
+			stored = *existing
+		}
+		stored.ID = existing.ID
+		stored.FirstSeenAt = existing.FirstSeenAt
+		if !link.FirstSeenAt.IsZero() && link.FirstSeenAt.Before(existing.FirstSeenAt) {
+			stored.FirstSeenAt = link.FirstSeenAt
+		}
+	} else {
+		// Assign new ID
+		if s.deterministicIDs {
+			stored.ID = graph.LinkIDFromURL(stored.URL)
+		} else {
+			for {
+				stored.ID = uuid.New()
+				if s.links[stored.ID] == nil {
+					break
+				}
+			}
+		}
+		if stored.FirstSeenAt.IsZero() {
+			stored.FirstSeenAt = time.Now().UTC()
 		}
-		return nil
 	}
 
-	// Assign new ID and insert link
-	for {
-		link.ID = uuid.New()
-		if s.links[link.ID] == nil {
-			break
-		}
+	if err := s.logUpsertLink(&stored); err != nil {
+		return err
+	}
+
+	s.applyUpsertLink(&stored)
+	s.events.append(&graph.Event{Type: graph.EventLinkUpserted, Link: &stored})
+	link.ID, link.URL, link.FirstSeenAt = stored.ID, stored.URL, stored.FirstSeenAt
+	return nil
+}
+
+// applyUpsertLink inserts link or overwrites the existing link with the
+// same ID. The caller must hold the write lock.
+func (s *InMemoryGraph) applyUpsertLink(link *graph.Link) {
+	if existing := s.links[link.ID]; existing != nil {
+		*existing = *link
+		return
 	}
 
 	lCopy := new(graph.Link)
 	*lCopy = *link
 	s.linkURLIndex[lCopy.URL] = lCopy
 	s.links[lCopy.ID] = lCopy
-	return nil
+	s.linkIndex.ReplaceOrInsert(lCopy)
+	s.linkHostMap.add(lCopy)
 }
 
 // FindLink looks up a link by its ID.
 func (s *InMemoryGraph) FindLink(id uuid.UUID) (*graph.Link, error) {
+	return s.FindLinkContext(context.Background(), id)
+}
+
+// FindLinkContext implements graph.Graph.
+func (s *InMemoryGraph) FindLinkContext(ctx context.Context, id uuid.UUID) (*graph.Link, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("find link: %w", err)
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -83,97 +238,385 @@ func (s *InMemoryGraph) FindLink(id uuid.UUID) (*graph.Link, error) {
 	return lCopy, nil
 }
 
-// Links returns an iterator for the set of links whose IDs belong to the
-// [fromID, toID) range and were retrieved before the provided timestamp.
-func (s *InMemoryGraph) Links(fromID, toID uuid.UUID, retrievedBefore time.Time) (graph.LinkIterator, error) {
-	from, to := fromID.String(), toID.String()
+// FindLinkByURL looks up a link by its URL.
+func (s *InMemoryGraph) FindLinkByURL(url string) (*graph.Link, error) {
+	return s.FindLinkByURLContext(context.Background(), url)
+}
+
+// FindLinkByURLContext implements graph.Graph.
+func (s *InMemoryGraph) FindLinkByURLContext(ctx context.Context, url string) (*graph.Link, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("find link by URL: %w", err)
+	}
+
+	canonicalURL, err := s.canon.Canonicalize(url)
+	if err != nil {
+		return nil, xerrors.Errorf("find link by URL: %w", err)
+	}
 
 	s.mu.RLock()
-	var list []*graph.Link
-	for linkID, link := range s.links {
-		if id := linkID.String(); id >= from && id < to && link.RetrievedAt.Before(retrievedBefore) {
-			list = append(list, link)
+	defer s.mu.RUnlock()
+
+	link := s.linkURLIndex[canonicalURL]
+	if link == nil {
+		return nil, xerrors.Errorf("find link by URL: %w", graph.ErrNotFound)
+	}
+
+	lCopy := new(graph.Link)
+	*lCopy = *link
+	return lCopy, nil
+}
+
+// RemoveLink deletes the link with the specified ID together with all
+// edges that originate from or point to it.
+func (s *InMemoryGraph) RemoveLink(id uuid.UUID) error {
+	return s.RemoveLinkContext(context.Background(), id)
+}
+
+// RemoveLinkContext implements graph.Graph.
+func (s *InMemoryGraph) RemoveLinkContext(ctx context.Context, id uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("remove link: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.links[id] == nil {
+		return xerrors.Errorf("remove link: %w", graph.ErrNotFound)
+	}
+
+	if err := s.logRemoveLink(id); err != nil {
+		return xerrors.Errorf("remove link: %w", err)
+	}
+
+	s.applyRemoveLink(id)
+	s.events.append(&graph.Event{Type: graph.EventLinkRemoved, LinkID: id})
+	return nil
+}
+
+// applyRemoveLink deletes the link with the specified ID, its URL index
+// entry and any edges that originate from or point to it. The caller must
+// hold the write lock.
+func (s *InMemoryGraph) applyRemoveLink(id uuid.UUID) {
+	link := s.links[id]
+	if link == nil {
+		return
+	}
+
+	// Drop outgoing edges
+	for _, edgeID := range s.linkEdgeMap[id] {
+		s.inboundEdgeMap[s.edges[edgeID].Dst] = s.inboundEdgeMap[s.edges[edgeID].Dst].without(edgeID)
+		delete(s.edges, edgeID)
+	}
+	delete(s.linkEdgeMap, id)
+
+	// Drop incoming edges
+	for _, edgeID := range s.inboundEdgeMap[id] {
+		s.linkEdgeMap[s.edges[edgeID].Src] = s.linkEdgeMap[s.edges[edgeID].Src].without(edgeID)
+		delete(s.edges, edgeID)
+	}
+	delete(s.inboundEdgeMap, id)
+
+	delete(s.linkURLIndex, link.URL)
+	delete(s.links, id)
+	s.linkIndex.Delete(link)
+	s.linkHostMap.remove(link)
+}
+
+// Links returns an iterator for the set of links whose IDs belong to the
+// [fromID, toID) range, were retrieved before the provided timestamp and
+// match any of the provided filters.
+func (s *InMemoryGraph) Links(fromID, toID uuid.UUID, retrievedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	return s.LinksContext(context.Background(), fromID, toID, retrievedBefore, filters...)
+}
+
+// LinksContext implements graph.Graph.
+func (s *InMemoryGraph) LinksContext(ctx context.Context, fromID, toID uuid.UUID, retrievedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("links: %w", err)
+	}
+
+	nextID := fromID
+	fetchPage := func() ([]*graph.Link, bool) {
+		var (
+			page    []*graph.Link
+			visited int
+			more    bool
+		)
+
+		s.mu.RLock()
+		s.linkIndex.AscendRange(&graph.Link{ID: nextID}, &graph.Link{ID: toID}, func(link *graph.Link) bool {
+			if visited == pageSize {
+				nextID, more = link.ID, true
+				return false
+			}
+			visited++
+
+			if link.RetrievedAt.Before(retrievedBefore) && graph.MatchesAny(link, filters) {
+				lCopy := new(graph.Link)
+				*lCopy = *link
+				page = append(page, lCopy)
+			}
+			return true
+		})
+		s.mu.RUnlock()
+		return page, more
+	}
+
+	return &linkIterator{ctx: ctx, fetchPage: fetchPage, more: true}, nil
+}
+
+// LinksByHost returns an iterator for the set of links whose URL refers to
+// the specified host.
+func (s *InMemoryGraph) LinksByHost(host string) (graph.LinkIterator, error) {
+	return s.LinksByHostContext(context.Background(), host)
+}
+
+// LinksByHostContext implements graph.Graph.
+func (s *InMemoryGraph) LinksByHostContext(ctx context.Context, host string) (graph.LinkIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("links by host: %w", err)
+	}
+
+	hostname, err := urlcanon.CanonicalHostname(host)
+	if err != nil {
+		return nil, xerrors.Errorf("links by host: %w", err)
+	}
+
+	// The links of a host are not ordered so they are fetched as a single
+	// page.
+	fetchPage := func() ([]*graph.Link, bool) {
+		var page []*graph.Link
+
+		s.mu.RLock()
+		for _, link := range s.linkHostMap[hostname] {
+			lCopy := new(graph.Link)
+			*lCopy = *link
+			page = append(page, lCopy)
 		}
+		s.mu.RUnlock()
+		return page, false
 	}
-	s.mu.RUnlock()
 
-	return &linkIterator{s: s, links: list}, nil
+	return &linkIterator{ctx: ctx, fetchPage: fetchPage, more: true}, nil
 }
 
 // UpsertEdge creates a new edge or updates an existing edge.
 func (s *InMemoryGraph) UpsertEdge(edge *graph.Edge) error {
+	return s.UpsertEdgeContext(context.Background(), edge)
+}
+
+// UpsertEdgeContext implements graph.Graph.
+func (s *InMemoryGraph) UpsertEdgeContext(ctx context.Context, edge *graph.Edge) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("upsert edge: %w", err)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.upsertEdge(edge); err != nil {
+		return xerrors.Errorf("upsert edge: %w", err)
+	}
+	return nil
+}
+
+// UpsertEdges creates or updates a batch of edges.
+func (s *InMemoryGraph) UpsertEdges(edges []*graph.Edge) error {
+	return s.UpsertEdgesContext(context.Background(), edges)
+}
+
+// UpsertEdgesContext implements graph.Graph.
+func (s *InMemoryGraph) UpsertEdgesContext(ctx context.Context, edges []*graph.Edge) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("upsert edges: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs := make([]error, len(edges))
+	for i, edge := range edges {
+		if err := s.upsertEdge(edge); err != nil {
+			errs[i] = xerrors.Errorf("upsert edges: %w", err)
+		}
+	}
+	return graph.NewBatchError(errs)
+}
+
+// upsertEdge logs and applies an upsert for edge and updates its ID and
+// timestamp. The caller must hold the write lock.
+func (s *InMemoryGraph) upsertEdge(edge *graph.Edge) error {
 	_, srcExists := s.links[edge.Src]
 	_, dstExists := s.links[edge.Dst]
 	if !srcExists || !dstExists {
-		return xerrors.Errorf("upsert edge: %w", graph.ErrUnknownEdgeLinks)
+		return graph.ErrUnknownEdgeLinks
 	}
 
 	// Scan edge list from source
+	stored := *edge
+	stored.ID = uuid.Nil
 	for _, edgeID := range s.linkEdgeMap[edge.Src] {
 		existingEdge := s.edges[edgeID]
 		if existingEdge.Src == edge.Src && existingEdge.Dst == edge.Dst {
-			existingEdge.UpdatedAt = time.Now()
-			*edge = *existingEdge
-			return nil
+			stored.ID = existingEdge.ID
+			break
 		}
 	}
 
-	// Insert new edge
-	for {
-		edge.ID = uuid.New()
-		if s.edges[edge.ID] == nil {
-			break
+	// Assign new ID if this is a new edge
+	for stored.ID == uuid.Nil {
+		stored.ID = uuid.New()
+		if s.edges[stored.ID] != nil {
+			stored.ID = uuid.Nil
 		}
 	}
+	stored.UpdatedAt = time.Now()
+	if stored.LinkCount < 1 {
+		stored.LinkCount = 1
+	}
+
+	if err := s.logUpsertEdge(&stored); err != nil {
+		return err
+	}
+
+	s.applyUpsertEdge(&stored)
+	s.events.append(&graph.Event{Type: graph.EventEdgeUpserted, Edge: &stored})
+	*edge = stored
+	return nil
+}
+
+// applyUpsertEdge inserts edge or refreshes the timestamp and attributes of
+// the existing edge with the same ID. The caller must hold the write lock.
+func (s *InMemoryGraph) applyUpsertEdge(edge *graph.Edge) {
+	if existing := s.edges[edge.ID]; existing != nil {
+		*existing = *edge
+		return
+	}
 
-	edge.UpdatedAt = time.Now()
 	eCopy := new(graph.Edge)
 	*eCopy = *edge
 	s.edges[eCopy.ID] = eCopy
 
 	// Append the edge ID to the list of edges originating from the
-	// edge's source link.
+	// edge's source link and the list of edges pointing to the edge's
+	// destination link.
 	s.linkEdgeMap[edge.Src] = append(s.linkEdgeMap[edge.Src], eCopy.ID)
-	return nil
+	s.inboundEdgeMap[edge.Dst] = append(s.inboundEdgeMap[edge.Dst], eCopy.ID)
 }
 
 // Edges returns an iterator for the set of edges whose source vertex IDs
 // belong to the [fromID, toID) range and were updated before the provided
 // timestamp.
 func (s *InMemoryGraph) Edges(fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
-	from, to := fromID.String(), toID.String()
+	return s.EdgesContext(context.Background(), fromID, toID, updatedBefore)
+}
 
-	s.mu.RLock()
-	var list []*graph.Edge
-	for linkID := range s.links {
-		if id := linkID.String(); id < from || id >= to {
-			continue
-		}
+// EdgesContext implements graph.Graph.
+func (s *InMemoryGraph) EdgesContext(ctx context.Context, fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("edges: %w", err)
+	}
+
+	// Edges are paged by source link so that a page never ends half-way
+	// through the edge list of a link.
+	nextSrcID := fromID
+	fetchPage := func() ([]*graph.Edge, bool) {
+		var (
+			page    []*graph.Edge
+			visited int
+			more    bool
+		)
+
+		s.mu.RLock()
+		s.linkIndex.AscendRange(&graph.Link{ID: nextSrcID}, &graph.Link{ID: toID}, func(link *graph.Link) bool {
+			if visited >= pageSize {
+				nextSrcID, more = link.ID, true
+				return false
+			}
+
+			for _, edgeID := range s.linkEdgeMap[link.ID] {
+				visited++
+				if edge := s.edges[edgeID]; edge.UpdatedAt.Before(updatedBefore) {
+					eCopy := new(graph.Edge)
+					*eCopy = *edge
+					page = append(page, eCopy)
+				}
+			}
+			return true
+		})
+		s.mu.RUnlock()
+		return page, more
+	}
+
+	return &edgeIterator{ctx: ctx, fetchPage: fetchPage, more: true}, nil
+}
+
+// InboundEdges returns an iterator for the set of edges that point to the
+// specified link ID and were updated before the provided timestamp.
+func (s *InMemoryGraph) InboundEdges(dstID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	return s.InboundEdgesContext(context.Background(), dstID, updatedBefore)
+}
+
+// InboundEdgesContext implements graph.Graph.
+func (s *InMemoryGraph) InboundEdgesContext(ctx context.Context, dstID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("inbound edges: %w", err)
+	}
 
-		for _, edgeID := range s.linkEdgeMap[linkID] {
+	// The inbound edge list of a link is not ordered so it is fetched as
+	// a single page.
+	fetchPage := func() ([]*graph.Edge, bool) {
+		var page []*graph.Edge
+
+		s.mu.RLock()
+		for _, edgeID := range s.inboundEdgeMap[dstID] {
 			if edge := s.edges[edgeID]; edge.UpdatedAt.Before(updatedBefore) {
-				list = append(list, edge)
+				eCopy := new(graph.Edge)
+				*eCopy = *edge
+				page = append(page, eCopy)
 			}
 		}
+		s.mu.RUnlock()
+		return page, false
 	}
-	s.mu.RUnlock()
 
-	return &edgeIterator{s: s, edges: list}, nil
+	return &edgeIterator{ctx: ctx, fetchPage: fetchPage, more: true}, nil
 }
 
 // RemoveStaleEdges removes any edge that originates from the specified link ID
 // and was updated before the specified timestamp.
 func (s *InMemoryGraph) RemoveStaleEdges(fromID uuid.UUID, updatedBefore time.Time) error {
+	return s.RemoveStaleEdgesContext(context.Background(), fromID, updatedBefore)
+}
+
+// RemoveStaleEdgesContext implements graph.Graph.
+func (s *InMemoryGraph) RemoveStaleEdgesContext(ctx context.Context, fromID uuid.UUID, updatedBefore time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("remove stale edges: %w", err)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.logRemoveStaleEdges(fromID, updatedBefore); err != nil {
+		return xerrors.Errorf("remove stale edges: %w", err)
+	}
+
+	s.applyRemoveStaleEdges(fromID, updatedBefore)
+	s.events.append(&graph.Event{Type: graph.EventStaleEdgesRemoved, LinkID: fromID, UpdatedBefore: updatedBefore})
+	return nil
+}
+
+// applyRemoveStaleEdges removes the stale edges originating from fromID.
+// The caller must hold the write lock.
+func (s *InMemoryGraph) applyRemoveStaleEdges(fromID uuid.UUID, updatedBefore time.Time) {
 	var newEdgeList edgeList
 	for _, edgeID := range s.linkEdgeMap[fromID] {
 		edge := s.edges[edgeID]
 		if edge.UpdatedAt.Before(updatedBefore) {
+			s.inboundEdgeMap[edge.Dst] = s.inboundEdgeMap[edge.Dst].without(edgeID)
 			delete(s.edges, edgeID)
 			continue
 		}
@@ -183,5 +626,41 @@ func (s *InMemoryGraph) RemoveStaleEdges(fromID uuid.UUID, updatedBefore time.Ti
 
 	// Replace edge list or origin link with the filtered edge list
 	s.linkEdgeMap[fromID] = newEdgeList
-	return nil
+}
+
+// without returns a copy of the edge list with edgeID removed or nil if
+// the resulting list is empty.
+func (l edgeList) without(edgeID uuid.UUID) edgeList {
+	var out edgeList
+	for _, id := range l {
+		if id != edgeID {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// add indexes link under the host that its URL refers to.
+func (m hostLinkMap) add(link *graph.Link) {
+	hostname := urlcanon.Hostname(link.URL)
+	if m[hostname] == nil {
+		m[hostname] = make(map[uuid.UUID]*graph.Link)
+	}
+	m[hostname][link.ID] = link
+}
+
+// remove drops link from the index.
+func (m hostLinkMap) remove(link *graph.Link) {
+	hostname := urlcanon.Hostname(link.URL)
+	delete(m[hostname], link.ID)
+	if len(m[hostname]) == 0 {
+		delete(m, hostname)
+	}
+}
+
+// newLinkIndex returns an empty B-tree that orders links by their ID.
+func newLinkIndex() *btree.BTreeG[*graph.Link] {
+	return btree.NewG(linkIndexDegree, func(a, b *graph.Link) bool {
+		return bytes.Compare(a.ID[:], b.ID[:]) < 0
+	})
 }
@@ -1,9 +1,16 @@
 package memory
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
 	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
 	gc "gopkg.in/check.v1"
 )
 
@@ -13,8 +20,145 @@ func Test(t *testing.T) { gc.TestingT(t) }
 
 type InMemoryGraphTestSuite struct {
 	graphtest.SuiteBase
+	g *InMemoryGraph
 }
 
 func (s *InMemoryGraphTestSuite) SetUpTest(c *gc.C) {
-	s.SetGraph(NewInMemoryGraph())
+	s.g = NewInMemoryGraph()
+	s.SetGraph(s.g)
+}
+
+// TestIteratorsReturnResultsInIDOrder verifies that links are returned in
+// ID order and edges are returned in source link ID order across multiple
+// iterator pages.
+func (s *InMemoryGraphTestSuite) TestIteratorsReturnResultsInIDOrder(c *gc.C) {
+	numLinks := 3*pageSize + 7
+	links := make([]*graph.Link, numLinks)
+	for i := range links {
+		links[i] = &graph.Link{URL: fmt.Sprint(i)}
+	}
+	c.Assert(s.g.UpsertLinks(links), gc.IsNil)
+	for i := 1; i < numLinks; i++ {
+		c.Assert(s.g.UpsertEdge(&graph.Edge{Src: links[i].ID, Dst: links[0].ID}), gc.IsNil)
+	}
+
+	linkIt, err := s.g.Links(uuid.Nil, maxUUID, time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	var linkIDs []uuid.UUID
+	for linkIt.Next() {
+		linkIDs = append(linkIDs, linkIt.Link().ID)
+	}
+	c.Assert(linkIt.Error(), gc.IsNil)
+	c.Assert(linkIt.Close(), gc.IsNil)
+	c.Assert(linkIDs, gc.HasLen, numLinks)
+	assertAscending(c, linkIDs)
+
+	edgeIt, err := s.g.Edges(uuid.Nil, maxUUID, time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+	var srcIDs []uuid.UUID
+	for edgeIt.Next() {
+		srcIDs = append(srcIDs, edgeIt.Edge().Src)
+	}
+	c.Assert(edgeIt.Error(), gc.IsNil)
+	c.Assert(edgeIt.Close(), gc.IsNil)
+	c.Assert(srcIDs, gc.HasLen, numLinks-1)
+	assertAscending(c, srcIDs)
+}
+
+// TestIteratorsAllowMutationsBetweenPages verifies that iterators do not
+// hold on to the graph lock so the graph can be mutated while an iterator
+// is being consumed.
+func (s *InMemoryGraphTestSuite) TestIteratorsAllowMutationsBetweenPages(c *gc.C) {
+	for i := 0; i < 2*pageSize; i++ {
+		c.Assert(s.g.UpsertLink(&graph.Link{URL: fmt.Sprint(i)}), gc.IsNil)
+	}
+
+	it, err := s.g.Links(uuid.Nil, maxUUID, time.Now().Add(time.Minute))
+	c.Assert(err, gc.IsNil)
+
+	var seen int
+	for it.Next() {
+		seen++
+		if seen == 1 {
+			c.Assert(s.g.RemoveLink(it.Link().ID), gc.IsNil)
+			c.Assert(s.g.UpsertLink(&graph.Link{URL: "https://example.com"}), gc.IsNil)
+		}
+	}
+	c.Assert(it.Error(), gc.IsNil)
+	c.Assert(it.Close(), gc.IsNil)
+
+	// The new link may or may not be visited depending on where its ID
+	// falls relative to the iterator position.
+	c.Assert(seen >= 2*pageSize, gc.Equals, true, gc.Commentf("visited %d links", seen))
+	c.Assert(seen <= 2*pageSize+1, gc.Equals, true, gc.Commentf("visited %d links", seen))
+}
+
+// TestDeterministicIDs verifies that new links are assigned the ID derived
+// from their canonical URL when deterministic IDs are enabled.
+func (s *InMemoryGraphTestSuite) TestDeterministicIDs(c *gc.C) {
+	s.g.SetDeterministicIDs(true)
+
+	link := &graph.Link{URL: "HTTP://Example.com:80/a#frag"}
+	c.Assert(s.g.UpsertLink(link), gc.IsNil)
+	c.Assert(link.ID, gc.Equals, graph.LinkIDFromURL("http://example.com/a"))
+
+	links := []*graph.Link{{URL: "http://example.com/b"}, {URL: "http://example.com/a"}}
+	c.Assert(s.g.UpsertLinks(links), gc.IsNil)
+	c.Assert(links[0].ID, gc.Equals, graph.LinkIDFromURL("http://example.com/b"))
+	c.Assert(links[1].ID, gc.Equals, link.ID)
+
+	// Links stored before deterministic IDs were enabled keep their ID.
+	s.g.SetDeterministicIDs(false)
+	random := &graph.Link{URL: "http://example.com/c"}
+	c.Assert(s.g.UpsertLink(random), gc.IsNil)
+	s.g.SetDeterministicIDs(true)
+	again := &graph.Link{URL: random.URL}
+	c.Assert(s.g.UpsertLink(again), gc.IsNil)
+	c.Assert(again.ID, gc.Equals, random.ID)
+}
+
+// TestWatchEventRetention verifies that watchers which fall behind the
+// retained events are rejected.
+func (s *InMemoryGraphTestSuite) TestWatchEventRetention(c *gc.C) {
+	s.g.SetEventRetention(3)
+	for i := 0; i < 10; i++ {
+		c.Assert(s.g.UpsertLink(&graph.Link{URL: fmt.Sprint(i)}), gc.IsNil)
+	}
+
+	_, err := s.g.Watch(context.TODO(), "")
+	c.Assert(xerrors.Is(err, graph.ErrEventsUnavailable), gc.Equals, true, gc.Commentf("%v", err))
+
+	it, err := s.g.Watch(context.TODO(), seqCursor(7))
+	c.Assert(err, gc.IsNil)
+	for _, exp := range []uint64{8, 9, 10} {
+		c.Assert(it.Next(), gc.Equals, true)
+		c.Assert(it.Event().Cursor, gc.Equals, seqCursor(exp))
+		c.Assert(it.Event().Link.URL, gc.Equals, fmt.Sprint(exp-1))
+	}
+	c.Assert(it.Close(), gc.IsNil)
+
+	// Events handed out to watchers must not alias the retained events.
+	it, err = s.g.Watch(context.TODO(), seqCursor(9))
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	it.Event().Link.URL = "mutated"
+	c.Assert(it.Close(), gc.IsNil)
+
+	it, err = s.g.Watch(context.TODO(), seqCursor(9))
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Event().Link.URL, gc.Equals, "9")
+	c.Assert(it.Close(), gc.IsNil)
+
+	// Watching from a sequence number that has not been reached yet fails.
+	_, err = s.g.Watch(context.TODO(), seqCursor(11))
+	c.Assert(xerrors.Is(err, graph.ErrEventsUnavailable), gc.Equals, true, gc.Commentf("%v", err))
+}
+
+var maxUUID = uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff")
+
+func assertAscending(c *gc.C, ids []uuid.UUID) {
+	for i := 1; i < len(ids); i++ {
+		c.Assert(bytes.Compare(ids[i-1][:], ids[i][:]) <= 0, gc.Equals, true, gc.Commentf("IDs at positions %d and %d are out of order", i-1, i))
+	}
 }
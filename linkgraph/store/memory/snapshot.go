@@ -0,0 +1,307 @@
+package memory
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/google/btree"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+const (
+	// snapshotMagic identifies a stream as an InMemoryGraph snapshot.
+	snapshotMagic = "LRUG"
+
+	// snapshotVersion is the version of the snapshot format emitted by
+	// Snapshot. It must be bumped whenever the encoding changes.
+	snapshotVersion uint16 = 3
+
+	// snapshotVersionNoMetadata is the version of snapshots that were
+	// created before links carried crawl metadata. Such snapshots can
+	// still be restored.
+	snapshotVersionNoMetadata uint16 = 1
+
+	// snapshotVersionNoEdgeAttributes is the version of snapshots that
+	// were created before edges carried attributes. Such snapshots can
+	// still be restored.
+	snapshotVersionNoEdgeAttributes uint16 = 2
+)
+
+var (
+	// ErrInvalidSnapshot is returned by Restore when the snapshot stream is
+	// malformed or its checksum does not match its contents.
+	ErrInvalidSnapshot = xerrors.New("invalid snapshot")
+
+	// ErrUnsupportedSnapshotVersion is returned by Restore when the snapshot
+	// was produced by an incompatible version of the encoder.
+	ErrUnsupportedSnapshotVersion = xerrors.New("unsupported snapshot version")
+
+	// ErrRestoreDurable is returned by Restore when the graph is backed by a
+	// write-ahead log. The restored contents would not be recorded in the
+	// log and would be lost when the graph is re-opened.
+	ErrRestoreDurable = xerrors.New("cannot restore a snapshot into a graph that is backed by a write-ahead log")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// graphState holds a point-in-time copy of the InMemoryGraph contents.
+type graphState struct {
+	links        []graph.Link
+	edges        []graph.Edge
+	linkURLIndex map[string]uuid.UUID
+	linkEdgeMap  map[uuid.UUID]edgeList
+
+	// walSeq is the sequence number of the last WAL record reflected in
+	// the copied state. It is zero if the graph is not backed by a WAL.
+	walSeq uint64
+}
+
+// Snapshot writes a consistent copy of the graph contents to w.
+//
+// The graph contents are copied while holding the read lock; the (much
+// slower) encoding step runs after the lock has been released so that
+// writers are only stalled while the copy is being made.
+//
+// The snapshot is laid out as follows (all integers are big-endian):
+//   - the "LRUG" magic and a uint16 format version
+//   - the link, edge, URL index and link-edge map sections, each prefixed
+//     with a uint64 entry count
+//   - a CRC-32 (Castagnoli) checksum of all preceding bytes
+func (s *InMemoryGraph) Snapshot(w io.Writer) error {
+	if err := writeSnapshot(w, s.copyState()); err != nil {
+		return xerrors.Errorf("snapshot: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the graph contents with the contents of a snapshot that
+// was previously created via a call to Snapshot. The existing contents are
+// left untouched if the snapshot cannot be decoded or fails validation.
+//
+// Restore is not supported for graphs that are backed by a write-ahead log
+// and fails with ErrRestoreDurable; such graphs are restored from their data
+// directory when they are opened. As the restored contents are not reported
+// as individual events, the retained events are dropped and the event
+// sequence number is advanced so that watchers fail with
+// graph.ErrEventsUnavailable and can rescan the graph.
+func (s *InMemoryGraph) Restore(r io.Reader) error {
+	if s.wal != nil {
+		return xerrors.Errorf("restore: %w", ErrRestoreDurable)
+	}
+
+	restored, err := readSnapshot(r)
+	if err != nil {
+		return xerrors.Errorf("restore: %w", err)
+	}
+
+	s.mu.Lock()
+	s.links = restored.links
+	s.edges = restored.edges
+	s.linkIndex = restored.linkIndex
+	s.linkURLIndex = restored.linkURLIndex
+	s.linkHostMap = restored.linkHostMap
+	s.linkEdgeMap = restored.linkEdgeMap
+	s.inboundEdgeMap = restored.inboundEdgeMap
+	s.events.reset(s.events.lastSeq + 1)
+	s.mu.Unlock()
+	return nil
+}
+
+// copyState returns a deep copy of the graph contents.
+func (s *InMemoryGraph) copyState() graphState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := graphState{
+		links:        make([]graph.Link, 0, len(s.links)),
+		edges:        make([]graph.Edge, 0, len(s.edges)),
+		linkURLIndex: make(map[string]uuid.UUID, len(s.linkURLIndex)),
+		linkEdgeMap:  make(map[uuid.UUID]edgeList, len(s.linkEdgeMap)),
+	}
+	for _, link := range s.links {
+		state.links = append(state.links, *link)
+	}
+	for _, edge := range s.edges {
+		state.edges = append(state.edges, *edge)
+	}
+	for url, link := range s.linkURLIndex {
+		state.linkURLIndex[url] = link.ID
+	}
+	for linkID, edgeIDs := range s.linkEdgeMap {
+		state.linkEdgeMap[linkID] = append(edgeList(nil), edgeIDs...)
+	}
+
+	// WAL appends happen while holding the write lock so the log cannot
+	// advance while we are holding the read lock.
+	if s.wal != nil {
+		state.walSeq = s.wal.LastSeq()
+	}
+	return state
+}
+
+func writeSnapshot(w io.Writer, state graphState) error {
+	crc := crc32.New(crcTable)
+	enc := newEncoder(io.MultiWriter(w, crc))
+	enc.writeBytes([]byte(snapshotMagic))
+	enc.writeUint16(snapshotVersion)
+
+	enc.writeUint64(uint64(len(state.links)))
+	for i := range state.links {
+		enc.writeLink(&state.links[i])
+	}
+
+	enc.writeUint64(uint64(len(state.edges)))
+	for i := range state.edges {
+		enc.writeEdge(&state.edges[i])
+	}
+
+	enc.writeUint64(uint64(len(state.linkURLIndex)))
+	for url, linkID := range state.linkURLIndex {
+		enc.writeString(url)
+		enc.writeUUID(linkID)
+	}
+
+	enc.writeUint64(uint64(len(state.linkEdgeMap)))
+	for linkID, edgeIDs := range state.linkEdgeMap {
+		enc.writeUUID(linkID)
+		enc.writeUint64(uint64(len(edgeIDs)))
+		for _, edgeID := range edgeIDs {
+			enc.writeUUID(edgeID)
+		}
+	}
+
+	if err := enc.flush(); err != nil {
+		return err
+	}
+
+	// All preceding bytes have been flushed through the checksum so its
+	// current value covers the entire snapshot body.
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	_, err := w.Write(sum[:])
+	return err
+}
+
+// restoredState holds the graph contents decoded from a snapshot.
+type restoredState struct {
+	links        map[uuid.UUID]*graph.Link
+	edges        map[uuid.UUID]*graph.Edge
+	linkURLIndex map[string]*graph.Link
+	linkEdgeMap  map[uuid.UUID]edgeList
+
+	// linkIndex, linkHostMap and inboundEdgeMap are not stored in
+	// snapshots as they can be derived from links and linkEdgeMap.
+	linkIndex      *btree.BTreeG[*graph.Link]
+	linkHostMap    hostLinkMap
+	inboundEdgeMap map[uuid.UUID]edgeList
+}
+
+func readSnapshot(r io.Reader) (*restoredState, error) {
+	crc := crc32.New(crcTable)
+	dec := newDecoder(io.TeeReader(bufio.NewReader(r), crc))
+
+	if magic := dec.readBytes(len(snapshotMagic)); dec.err == nil && string(magic) != snapshotMagic {
+		return nil, xerrors.Errorf("bad magic: %w", ErrInvalidSnapshot)
+	}
+	version := dec.readUint16()
+	if dec.err == nil && version != snapshotVersion && version != snapshotVersionNoMetadata && version != snapshotVersionNoEdgeAttributes {
+		return nil, xerrors.Errorf("snapshot version %d: %w", version, ErrUnsupportedSnapshotVersion)
+	}
+
+	state := &restoredState{
+		links:          make(map[uuid.UUID]*graph.Link),
+		edges:          make(map[uuid.UUID]*graph.Edge),
+		linkIndex:      newLinkIndex(),
+		linkHostMap:    make(hostLinkMap),
+		linkURLIndex:   make(map[string]*graph.Link),
+		linkEdgeMap:    make(map[uuid.UUID]edgeList),
+		inboundEdgeMap: make(map[uuid.UUID]edgeList),
+	}
+
+	for n := dec.readUint64(); dec.err == nil && n > 0; n-- {
+		var link *graph.Link
+		if version == snapshotVersionNoMetadata {
+			link = &graph.Link{
+				ID:          dec.readUUID(),
+				URL:         dec.readString(),
+				RetrievedAt: dec.readTime(),
+			}
+		} else {
+			link = dec.readLink()
+		}
+		state.links[link.ID] = link
+		state.linkIndex.ReplaceOrInsert(link)
+		state.linkHostMap.add(link)
+	}
+
+	for n := dec.readUint64(); dec.err == nil && n > 0; n-- {
+		var edge *graph.Edge
+		if version == snapshotVersionNoMetadata || version == snapshotVersionNoEdgeAttributes {
+			edge = &graph.Edge{
+				ID:        dec.readUUID(),
+				Src:       dec.readUUID(),
+				Dst:       dec.readUUID(),
+				UpdatedAt: dec.readTime(),
+				LinkCount: 1,
+			}
+		} else {
+			edge = dec.readEdge()
+		}
+		state.edges[edge.ID] = edge
+	}
+
+	for n := dec.readUint64(); dec.err == nil && n > 0; n-- {
+		url, linkID := dec.readString(), dec.readUUID()
+		if link := state.links[linkID]; link != nil && dec.err == nil {
+			state.linkURLIndex[url] = link
+		} else if dec.err == nil {
+			dec.err = xerrors.Errorf("URL index references unknown link %s", linkID)
+		}
+	}
+
+	for n := dec.readUint64(); dec.err == nil && n > 0; n-- {
+		linkID := dec.readUUID()
+		numEdges := dec.readUint64()
+		if dec.err == nil && numEdges > uint64(len(state.edges)) {
+			dec.err = xerrors.Errorf("edge list for link %s exceeds edge count", linkID)
+		}
+
+		var list edgeList
+		for ; dec.err == nil && numEdges > 0; numEdges-- {
+			edgeID := dec.readUUID()
+			if state.edges[edgeID] == nil && dec.err == nil {
+				dec.err = xerrors.Errorf("edge list for link %s references unknown edge %s", linkID, edgeID)
+			}
+			list = append(list, edgeID)
+		}
+		state.linkEdgeMap[linkID] = list
+	}
+
+	for _, list := range state.linkEdgeMap {
+		for _, edgeID := range list {
+			if edge := state.edges[edgeID]; edge != nil {
+				state.inboundEdgeMap[edge.Dst] = append(state.inboundEdgeMap[edge.Dst], edgeID)
+			}
+		}
+	}
+
+	if dec.err != nil {
+		return nil, xerrors.Errorf("%v: %w", dec.err, ErrInvalidSnapshot)
+	}
+
+	// Verify that the checksum trailer matches the bytes read so far.
+	expSum := crc.Sum32()
+	var sum [4]byte
+	if dec.readFull(sum[:]); dec.err != nil {
+		return nil, xerrors.Errorf("%v: %w", dec.err, ErrInvalidSnapshot)
+	}
+	if binary.BigEndian.Uint32(sum[:]) != expSum {
+		return nil, xerrors.Errorf("checksum mismatch: %w", ErrInvalidSnapshot)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,214 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(SnapshotTestSuite))
+
+type SnapshotTestSuite struct{}
+
+// TestSnapshotRoundTrip verifies that a restored graph contains the same
+// links and edges as the graph the snapshot was taken from.
+func (s *SnapshotTestSuite) TestSnapshotRoundTrip(c *gc.C) {
+	g := NewInMemoryGraph()
+	links := make([]*graph.Link, 10)
+	for i := range links {
+		links[i] = &graph.Link{
+			URL:         fmt.Sprintf("https://example.com/%d", i),
+			RetrievedAt: time.Now().Add(-time.Duration(i) * time.Hour),
+			StatusCode:  200 + i,
+			ContentType: "text/html",
+			ContentHash: fmt.Sprint(i),
+			FetchErrors: i,
+			LastError:   fmt.Sprintf("error %d", i),
+		}
+		c.Assert(g.UpsertLink(links[i]), gc.IsNil)
+	}
+	for i := 1; i < len(links); i++ {
+		c.Assert(g.UpsertEdge(&graph.Edge{Src: links[0].ID, Dst: links[i].ID, AnchorText: fmt.Sprint(i), UGC: true, LinkCount: i}), gc.IsNil)
+	}
+
+	var buf bytes.Buffer
+	c.Assert(g.Snapshot(&buf), gc.IsNil)
+
+	restored := NewInMemoryGraph()
+	c.Assert(restored.Restore(&buf), gc.IsNil)
+
+	for _, link := range links {
+		got, err := restored.FindLink(link.ID)
+		c.Assert(err, gc.IsNil)
+		c.Assert(got.URL, gc.Equals, link.URL)
+		c.Assert(got.RetrievedAt.Equal(link.RetrievedAt), gc.Equals, true)
+		c.Assert(got.FirstSeenAt.Equal(link.FirstSeenAt), gc.Equals, true)
+		c.Assert(got.StatusCode, gc.Equals, link.StatusCode)
+		c.Assert(got.ContentType, gc.Equals, link.ContentType)
+		c.Assert(got.ContentHash, gc.Equals, link.ContentHash)
+		c.Assert(got.FetchErrors, gc.Equals, link.FetchErrors)
+		c.Assert(got.LastError, gc.Equals, link.LastError)
+	}
+
+	// The URL index must be restored so upserts resolve to existing links.
+	dup := &graph.Link{URL: links[3].URL}
+	c.Assert(restored.UpsertLink(dup), gc.IsNil)
+	c.Assert(dup.ID, gc.Equals, links[3].ID)
+
+	// The host index is rebuilt from the restored links.
+	hostIt, err := restored.LinksByHost("example.com")
+	c.Assert(err, gc.IsNil)
+	var numHostLinks int
+	for hostIt.Next() {
+		numHostLinks++
+	}
+	c.Assert(hostIt.Close(), gc.IsNil)
+	c.Assert(numHostLinks, gc.Equals, len(links))
+
+	// The inbound edge index is rebuilt from the link-edge map.
+	it, err := restored.InboundEdges(links[5].ID, time.Now())
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Edge().Src, gc.Equals, links[0].ID)
+	c.Assert(it.Edge().AnchorText, gc.Equals, "5")
+	c.Assert(it.Edge().UGC, gc.Equals, true)
+	c.Assert(it.Edge().LinkCount, gc.Equals, 5)
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(it.Close(), gc.IsNil)
+
+	// The link-edge map must be restored so stale edges can be removed.
+	c.Assert(restored.RemoveStaleEdges(links[0].ID, time.Now()), gc.IsNil)
+	it, err = restored.Edges(uuid.Nil, uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff"), time.Now())
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(it.Close(), gc.IsNil)
+}
+
+// TestRestoreCorruptedSnapshot verifies that checksum mismatches are
+// detected and that the graph contents are left untouched.
+func (s *SnapshotTestSuite) TestRestoreCorruptedSnapshot(c *gc.C) {
+	g := NewInMemoryGraph()
+	c.Assert(g.UpsertLink(&graph.Link{URL: "https://example.com"}), gc.IsNil)
+
+	var buf bytes.Buffer
+	c.Assert(g.Snapshot(&buf), gc.IsNil)
+	data := buf.Bytes()
+	data[len(data)-5] ^= 0xff
+
+	existing := &graph.Link{URL: "https://example.org"}
+	target := NewInMemoryGraph()
+	c.Assert(target.UpsertLink(existing), gc.IsNil)
+
+	err := target.Restore(bytes.NewReader(data))
+	c.Assert(xerrors.Is(err, ErrInvalidSnapshot), gc.Equals, true)
+
+	_, err = target.FindLink(existing.ID)
+	c.Assert(err, gc.IsNil)
+}
+
+// TestRestoreResetsEvents verifies that watchers of a graph fail with
+// graph.ErrEventsUnavailable once a snapshot has been restored.
+func (s *SnapshotTestSuite) TestRestoreResetsEvents(c *gc.C) {
+	src := NewInMemoryGraph()
+	c.Assert(src.UpsertLink(&graph.Link{URL: "https://example.com"}), gc.IsNil)
+	var buf bytes.Buffer
+	c.Assert(src.Snapshot(&buf), gc.IsNil)
+
+	target := NewInMemoryGraph()
+	c.Assert(target.UpsertLink(&graph.Link{URL: "https://example.org"}), gc.IsNil)
+	lastCursor, err := target.LastEventCursor(context.TODO())
+	c.Assert(err, gc.IsNil)
+
+	ctx, cancelFn := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancelFn()
+	it, err := target.Watch(ctx, lastCursor)
+	c.Assert(err, gc.IsNil)
+
+	time.AfterFunc(50*time.Millisecond, func() { c.Check(target.Restore(bytes.NewReader(buf.Bytes())), gc.IsNil) })
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(xerrors.Is(it.Error(), graph.ErrEventsUnavailable), gc.Equals, true, gc.Commentf("%v", it.Error()))
+	c.Assert(it.Close(), gc.IsNil)
+
+	_, err = target.Watch(context.TODO(), lastCursor)
+	c.Assert(xerrors.Is(err, graph.ErrEventsUnavailable), gc.Equals, true, gc.Commentf("%v", err))
+
+	// Watchers that start after the restore receive new events.
+	restoredCursor, err := target.LastEventCursor(context.TODO())
+	c.Assert(err, gc.IsNil)
+	it, err = target.Watch(ctx, restoredCursor)
+	c.Assert(err, gc.IsNil)
+	c.Assert(target.UpsertLink(&graph.Link{URL: "https://example.net"}), gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true, gc.Commentf("%v", it.Error()))
+	c.Assert(it.Event().Cursor > restoredCursor, gc.Equals, true)
+	c.Assert(it.Close(), gc.IsNil)
+}
+
+// TestRestoreTruncatedSnapshot verifies that truncated snapshots are
+// rejected.
+func (s *SnapshotTestSuite) TestRestoreTruncatedSnapshot(c *gc.C) {
+	g := NewInMemoryGraph()
+	c.Assert(g.UpsertLink(&graph.Link{URL: "https://example.com"}), gc.IsNil)
+
+	var buf bytes.Buffer
+	c.Assert(g.Snapshot(&buf), gc.IsNil)
+
+	err := NewInMemoryGraph().Restore(bytes.NewReader(buf.Bytes()[:buf.Len()/2]))
+	c.Assert(xerrors.Is(err, ErrInvalidSnapshot), gc.Equals, true)
+}
+
+// TestRestoreUnsupportedVersion verifies that snapshots with an unknown
+// format version are rejected.
+func (s *SnapshotTestSuite) TestRestoreUnsupportedVersion(c *gc.C) {
+	var buf bytes.Buffer
+	c.Assert(NewInMemoryGraph().Snapshot(&buf), gc.IsNil)
+	data := buf.Bytes()
+	data[len(snapshotMagic)+1]++
+
+	err := NewInMemoryGraph().Restore(bytes.NewReader(data))
+	c.Assert(xerrors.Is(err, ErrUnsupportedSnapshotVersion), gc.Equals, true)
+}
+
+// TestRestoreSnapshotWithoutMetadata verifies that snapshots created before
+// links carried crawl metadata can still be restored.
+func (s *SnapshotTestSuite) TestRestoreSnapshotWithoutMetadata(c *gc.C) {
+	link := graph.Link{
+		ID:          uuid.New(),
+		URL:         "https://example.com",
+		RetrievedAt: time.Now().Truncate(time.Second).UTC(),
+	}
+
+	var buf bytes.Buffer
+	crc := crc32.New(crcTable)
+	enc := newEncoder(io.MultiWriter(&buf, crc))
+	enc.writeBytes([]byte(snapshotMagic))
+	enc.writeUint16(snapshotVersionNoMetadata)
+	enc.writeUint64(1)
+	enc.writeUUID(link.ID)
+	enc.writeString(link.URL)
+	enc.writeTime(link.RetrievedAt)
+	enc.writeUint64(0) // edges
+	enc.writeUint64(1)
+	enc.writeString(link.URL)
+	enc.writeUUID(link.ID)
+	enc.writeUint64(0) // link-edge map
+	c.Assert(enc.flush(), gc.IsNil)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	buf.Write(sum[:])
+
+	g := NewInMemoryGraph()
+	c.Assert(g.Restore(&buf), gc.IsNil)
+
+	got, err := g.FindLink(link.ID)
+	c.Assert(err, gc.IsNil)
+	c.Assert(got, gc.DeepEquals, &link)
+}
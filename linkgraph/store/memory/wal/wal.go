@@ -0,0 +1,503 @@
+// Package wal implements a segmented, append-only write-ahead log.
+//
+// Each record in the log is assigned a monotonically increasing sequence
+// number. Records are framed as:
+//
+//	[length uint32][crc32 uint32][seq uint64][payload]
+//
+// where length is the size of the payload and the CRC-32 (Castagnoli)
+// checksum covers both the sequence number and the payload. The log is split
+// into segment files named after the sequence number of their first record
+// so that segments that are no longer needed can be removed in their
+// entirety.
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	segmentExt = ".wal"
+
+	// headerSize is the size of the fixed-length part of a record frame.
+	headerSize = 4 + 4 + 8
+
+	// maxRecordSize guards against allocating huge buffers when reading a
+	// corrupted length field.
+	maxRecordSize = 64 << 20
+)
+
+var (
+	// ErrCorrupt is returned when a sealed (i.e. non-active) segment contains
+	// a torn or corrupted record.
+	ErrCorrupt = xerrors.New("wal: corrupted segment")
+
+	// ErrClosed is returned when attempting to use a closed log.
+	ErrClosed = xerrors.New("wal: log is closed")
+
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// SyncPolicy controls when appended records are flushed to stable storage.
+type SyncPolicy uint8
+
+const (
+	// SyncAlways fsyncs the active segment after every append.
+	SyncAlways SyncPolicy = iota
+
+	// SyncInterval fsyncs the active segment periodically from a background
+	// goroutine. Records appended since the last sync may be lost if the
+	// host crashes.
+	SyncInterval
+
+	// SyncNever leaves flushing to the operating system.
+	SyncNever
+)
+
+// Options configures a Log.
+type Options struct {
+	// SyncPolicy controls when appended records are fsynced.
+	SyncPolicy SyncPolicy
+
+	// SyncInterval is the fsync period used by the SyncInterval policy.
+	// Defaults to one second.
+	SyncInterval time.Duration
+
+	// MaxSegmentSize is the size in bytes after which the active segment is
+	// sealed and a new one is started. Defaults to 64 MiB.
+	MaxSegmentSize int64
+}
+
+func (o *Options) applyDefaults() {
+	if o.SyncInterval <= 0 {
+		o.SyncInterval = time.Second
+	}
+	if o.MaxSegmentSize <= 0 {
+		o.MaxSegmentSize = 64 << 20
+	}
+}
+
+// segmentFile is the subset of *os.File used for the active segment.
+type segmentFile interface {
+	io.Writer
+	io.Seeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+// Log is a segmented write-ahead log that can be safely appended to by
+// multiple goroutines.
+type Log struct {
+	mu   sync.Mutex
+	dir  string
+	opts Options
+
+	// firstSeqs holds the sequence number of the first record in each
+	// segment, sorted in ascending order. The last entry refers to the
+	// active segment.
+	firstSeqs  []uint64
+	active     segmentFile
+	activeSize int64
+	nextSeq    uint64
+	dirty      bool
+	closed     bool
+
+	// failed is set if a failed append could not be rolled back. The
+	// active segment may then end with a partial record, so all further
+	// appends are rejected.
+	failed error
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// Open opens the log stored in dir, creating the directory if required. Any
+// torn record at the tail of the most recent segment, as left behind by a
+// crash in the middle of an append, is truncated.
+func Open(dir string, opts Options) (*Log, error) {
+	opts.applyDefaults()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, xerrors.Errorf("wal: open: %w", err)
+	}
+
+	firstSeqs, err := listSegments(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("wal: open: %w", err)
+	}
+
+	l := &Log{
+		dir:     dir,
+		opts:    opts,
+		nextSeq: 1,
+	}
+
+	if len(firstSeqs) == 0 {
+		if err = l.startSegment(); err != nil {
+			return nil, xerrors.Errorf("wal: open: %w", err)
+		}
+	} else {
+		l.firstSeqs = firstSeqs
+		if err = l.openActiveSegment(); err != nil {
+			return nil, xerrors.Errorf("wal: open: %w", err)
+		}
+	}
+
+	if opts.SyncPolicy == SyncInterval {
+		l.stopCh = make(chan struct{})
+		l.doneCh = make(chan struct{})
+		go l.syncLoop()
+	}
+
+	return l, nil
+}
+
+// Append writes a record with the provided payload to the log and returns
+// the sequence number that was assigned to it. If the record cannot be
+// written (or, with the SyncAlways policy, synced), it is removed from the
+// log again so that it is not replayed.
+func (l *Log) Append(payload []byte) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return 0, ErrClosed
+	} else if l.failed != nil {
+		return 0, xerrors.Errorf("wal: append: log unusable after failed rollback: %w", l.failed)
+	}
+
+	frameSize := int64(headerSize + len(payload))
+	if l.activeSize > 0 && l.activeSize+frameSize > l.opts.MaxSegmentSize {
+		if err := l.sealActiveSegment(); err != nil {
+			return 0, xerrors.Errorf("wal: append: %w", err)
+		}
+		if err := l.startSegment(); err != nil {
+			return 0, xerrors.Errorf("wal: append: %w", err)
+		}
+	}
+
+	seq := l.nextSeq
+	if _, err := l.active.Write(encodeFrame(seq, payload)); err != nil {
+		return 0, xerrors.Errorf("wal: append: %w", l.rollback(err))
+	}
+
+	if l.opts.SyncPolicy == SyncAlways {
+		if err := l.active.Sync(); err != nil {
+			return 0, xerrors.Errorf("wal: append: %w", l.rollback(err))
+		}
+	} else {
+		l.dirty = true
+	}
+
+	l.activeSize += frameSize
+	l.nextSeq++
+	return seq, nil
+}
+
+// rollback discards any part of a record frame that was written to the
+// active segment by a failed append and returns cause. If the segment
+// cannot be truncated, the log is marked as failed.
+func (l *Log) rollback(cause error) error {
+	err := l.active.Truncate(l.activeSize)
+	if err == nil {
+		_, err = l.active.Seek(l.activeSize, io.SeekStart)
+	}
+	if err != nil {
+		l.failed = err
+		return xerrors.Errorf("%v; rollback failed: %w", cause, err)
+	}
+	return cause
+}
+
+// LastSeq returns the sequence number of the most recently appended record
+// or zero if the log is empty.
+func (l *Log) LastSeq() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.nextSeq - 1
+}
+
+// Replay invokes fn, in sequence order, for each record whose sequence
+// number is greater than or equal to fromSeq. Replay stops and returns the
+// first error reported by fn.
+func (l *Log) Replay(fromSeq uint64, fn func(seq uint64, payload []byte) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	for i, firstSeq := range l.firstSeqs {
+		// Skip segments whose records all precede fromSeq.
+		if i+1 < len(l.firstSeqs) && l.firstSeqs[i+1] <= fromSeq {
+			continue
+		}
+
+		f, err := os.Open(l.segmentPath(firstSeq))
+		if err != nil {
+			return xerrors.Errorf("wal: replay: %w", err)
+		}
+
+		err = scanSegment(f, func(seq uint64, payload []byte) error {
+			if seq < fromSeq {
+				return nil
+			}
+			return fn(seq, payload)
+		})
+		_ = f.Close()
+		if err != nil {
+			return xerrors.Errorf("wal: replay segment %d: %w", firstSeq, err)
+		}
+	}
+
+	return nil
+}
+
+// TruncateBefore removes all segments that only contain records whose
+// sequence number is less than seq. The active segment is never removed.
+func (l *Log) TruncateBefore(seq uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	var removed int
+	for i := 0; i+1 < len(l.firstSeqs) && l.firstSeqs[i+1] <= seq; i++ {
+		if err := os.Remove(l.segmentPath(l.firstSeqs[i])); err != nil {
+			return xerrors.Errorf("wal: truncate: %w", err)
+		}
+		removed++
+	}
+	l.firstSeqs = l.firstSeqs[removed:]
+	return nil
+}
+
+// Sync flushes the active segment to stable storage.
+func (l *Log) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+	return l.syncLocked()
+}
+
+// Close syncs and closes the log.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	err := l.sealActiveSegment()
+	l.mu.Unlock()
+
+	if l.stopCh != nil {
+		close(l.stopCh)
+		<-l.doneCh
+	}
+
+	if err != nil {
+		return xerrors.Errorf("wal: close: %w", err)
+	}
+	return nil
+}
+
+func (l *Log) syncLoop() {
+	defer close(l.doneCh)
+
+	ticker := time.NewTicker(l.opts.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stopCh:
+			return
+		case <-ticker.C:
+			l.mu.Lock()
+			if !l.closed {
+				// There is no caller to report the error to; the next
+				// tick or an explicit Sync call will retry.
+				_ = l.syncLocked()
+			}
+			l.mu.Unlock()
+		}
+	}
+}
+
+func (l *Log) syncLocked() error {
+	if !l.dirty {
+		return nil
+	}
+	if err := l.active.Sync(); err != nil {
+		return err
+	}
+	l.dirty = false
+	return nil
+}
+
+func (l *Log) sealActiveSegment() error {
+	l.dirty = true
+	if err := l.syncLocked(); err != nil {
+		return err
+	}
+	return l.active.Close()
+}
+
+// startSegment creates a new, empty active segment.
+func (l *Log) startSegment() error {
+	f, err := os.OpenFile(l.segmentPath(l.nextSeq), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err = syncDir(l.dir); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	l.firstSeqs = append(l.firstSeqs, l.nextSeq)
+	l.active = f
+	l.activeSize = 0
+	return nil
+}
+
+// openActiveSegment scans the most recent segment, truncates any torn
+// record at its tail and opens it for appending.
+func (l *Log) openActiveSegment() error {
+	firstSeq := l.firstSeqs[len(l.firstSeqs)-1]
+	f, err := os.OpenFile(l.segmentPath(firstSeq), os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+
+	var (
+		validSize int64
+		lastSeq   = firstSeq - 1
+	)
+	err = scanSegment(f, func(seq uint64, payload []byte) error {
+		validSize += int64(headerSize + len(payload))
+		lastSeq = seq
+		return nil
+	})
+	if err != nil && !xerrors.Is(err, ErrCorrupt) {
+		_ = f.Close()
+		return err
+	}
+
+	if err = f.Truncate(validSize); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err = f.Seek(validSize, io.SeekStart); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	l.active = f
+	l.activeSize = validSize
+	l.nextSeq = lastSeq + 1
+	return nil
+}
+
+func (l *Log) segmentPath(firstSeq uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", firstSeq, segmentExt))
+}
+
+// listSegments returns the first sequence numbers of the segments in dir in
+// ascending order.
+func listSegments(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var firstSeqs []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		firstSeqs = append(firstSeqs, seq)
+	}
+
+	sort.Slice(firstSeqs, func(i, j int) bool { return firstSeqs[i] < firstSeqs[j] })
+	return firstSeqs, nil
+}
+
+func encodeFrame(seq uint64, payload []byte) []byte {
+	frame := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint64(frame[8:16], seq)
+	copy(frame[headerSize:], payload)
+	binary.BigEndian.PutUint32(frame[4:8], crc32.Checksum(frame[8:], crcTable))
+	return frame
+}
+
+// scanSegment invokes fn for each record in r. It returns an error wrapping
+// ErrCorrupt if a torn or corrupted record is encountered.
+func scanSegment(r io.Reader, fn func(seq uint64, payload []byte) error) error {
+	br := bufio.NewReader(r)
+	var header [headerSize]byte
+	for {
+		if _, err := io.ReadFull(br, header[:]); err == io.EOF {
+			return nil
+		} else if err == io.ErrUnexpectedEOF {
+			return xerrors.Errorf("torn record header: %w", ErrCorrupt)
+		} else if err != nil {
+			return err
+		}
+
+		size := binary.BigEndian.Uint32(header[0:4])
+		if size > maxRecordSize {
+			return xerrors.Errorf("record size %d exceeds limit: %w", size, ErrCorrupt)
+		}
+
+		body := make([]byte, 8+size)
+		copy(body, header[8:16])
+		if _, err := io.ReadFull(br, body[8:]); err == io.EOF || err == io.ErrUnexpectedEOF {
+			return xerrors.Errorf("torn record payload: %w", ErrCorrupt)
+		} else if err != nil {
+			return err
+		}
+
+		if crc32.Checksum(body, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+			return xerrors.Errorf("checksum mismatch: %w", ErrCorrupt)
+		}
+
+		if err := fn(binary.BigEndian.Uint64(header[8:16]), body[8:]); err != nil {
+			return err
+		}
+	}
+}
+
+// syncDir fsyncs a directory so that newly created entries survive a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = d.Close() }()
+	return d.Sync()
+}
@@ -0,0 +1,214 @@
+package wal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(WALTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type WALTestSuite struct{}
+
+// TestAppendAndReplay verifies that records survive re-opening the log and
+// are replayed in sequence order.
+func (s *WALTestSuite) TestAppendAndReplay(c *gc.C) {
+	dir := c.MkDir()
+	log, err := Open(dir, Options{SyncPolicy: SyncAlways})
+	c.Assert(err, gc.IsNil)
+	for i := 1; i <= 10; i++ {
+		seq, err := log.Append([]byte(fmt.Sprint(i)))
+		c.Assert(err, gc.IsNil)
+		c.Assert(seq, gc.Equals, uint64(i))
+	}
+	c.Assert(log.Close(), gc.IsNil)
+
+	log, err = Open(dir, Options{SyncPolicy: SyncNever})
+	c.Assert(err, gc.IsNil)
+	defer func() { c.Assert(log.Close(), gc.IsNil) }()
+	c.Assert(log.LastSeq(), gc.Equals, uint64(10))
+
+	c.Assert(replayAll(c, log, 4), gc.DeepEquals, []string{"4", "5", "6", "7", "8", "9", "10"})
+
+	seq, err := log.Append([]byte("11"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(seq, gc.Equals, uint64(11))
+}
+
+// TestTornTail verifies that a partially written record at the end of the
+// active segment is discarded when the log is re-opened.
+func (s *WALTestSuite) TestTornTail(c *gc.C) {
+	dir := c.MkDir()
+	log, err := Open(dir, Options{SyncPolicy: SyncInterval})
+	c.Assert(err, gc.IsNil)
+	for i := 1; i <= 3; i++ {
+		_, err = log.Append([]byte(fmt.Sprint(i)))
+		c.Assert(err, gc.IsNil)
+	}
+	c.Assert(log.Close(), gc.IsNil)
+
+	// Simulate a crash in the middle of appending the third record.
+	segPath := filepath.Join(dir, fmt.Sprintf("%020d%s", 1, segmentExt))
+	info, err := os.Stat(segPath)
+	c.Assert(err, gc.IsNil)
+	c.Assert(os.Truncate(segPath, info.Size()-1), gc.IsNil)
+
+	log, err = Open(dir, Options{})
+	c.Assert(err, gc.IsNil)
+	defer func() { c.Assert(log.Close(), gc.IsNil) }()
+	c.Assert(log.LastSeq(), gc.Equals, uint64(2))
+
+	seq, err := log.Append([]byte("3'"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(seq, gc.Equals, uint64(3))
+	c.Assert(replayAll(c, log, 1), gc.DeepEquals, []string{"1", "2", "3'"})
+}
+
+// TestSegmentRotationAndTruncation verifies that segments are rotated once
+// they exceed the configured size and can be removed once obsolete.
+func (s *WALTestSuite) TestSegmentRotationAndTruncation(c *gc.C) {
+	dir := c.MkDir()
+	log, err := Open(dir, Options{SyncPolicy: SyncNever, MaxSegmentSize: 2 * (headerSize + 2)})
+	c.Assert(err, gc.IsNil)
+	defer func() { c.Assert(log.Close(), gc.IsNil) }()
+
+	for i := 10; i < 20; i++ {
+		_, err = log.Append([]byte(fmt.Sprint(i)))
+		c.Assert(err, gc.IsNil)
+	}
+
+	segments, err := listSegments(dir)
+	c.Assert(err, gc.IsNil)
+	c.Assert(segments, gc.DeepEquals, []uint64{1, 3, 5, 7, 9})
+
+	c.Assert(log.TruncateBefore(6), gc.IsNil)
+	segments, err = listSegments(dir)
+	c.Assert(err, gc.IsNil)
+	c.Assert(segments, gc.DeepEquals, []uint64{5, 7, 9})
+	c.Assert(replayAll(c, log, 1), gc.DeepEquals, []string{"14", "15", "16", "17", "18", "19"})
+}
+
+// TestCorruptSealedSegment verifies that corruption in a sealed segment is
+// reported during replay.
+func (s *WALTestSuite) TestCorruptSealedSegment(c *gc.C) {
+	dir := c.MkDir()
+	log, err := Open(dir, Options{SyncPolicy: SyncNever, MaxSegmentSize: headerSize + 1})
+	c.Assert(err, gc.IsNil)
+	for i := 0; i < 3; i++ {
+		_, err = log.Append([]byte(fmt.Sprint(i)))
+		c.Assert(err, gc.IsNil)
+	}
+	c.Assert(log.Close(), gc.IsNil)
+
+	segPath := filepath.Join(dir, fmt.Sprintf("%020d%s", 1, segmentExt))
+	data, err := os.ReadFile(segPath)
+	c.Assert(err, gc.IsNil)
+	data[len(data)-1] ^= 0xff
+	c.Assert(os.WriteFile(segPath, data, 0644), gc.IsNil)
+
+	log, err = Open(dir, Options{})
+	c.Assert(err, gc.IsNil)
+	defer func() { c.Assert(log.Close(), gc.IsNil) }()
+
+	err = log.Replay(1, func(uint64, []byte) error { return nil })
+	c.Assert(xerrors.Is(err, ErrCorrupt), gc.Equals, true)
+}
+
+// TestFailedAppend verifies that records whose write or sync fails are
+// rolled back and do not prevent subsequent records from being replayed.
+func (s *WALTestSuite) TestFailedAppend(c *gc.C) {
+	dir := c.MkDir()
+	log, err := Open(dir, Options{SyncPolicy: SyncAlways})
+	c.Assert(err, gc.IsNil)
+	_, err = log.Append([]byte("1"))
+	c.Assert(err, gc.IsNil)
+
+	f := &faultyFile{segmentFile: log.active}
+	log.active = f
+
+	// A torn write leaves part of the frame in the segment.
+	f.failWrite = true
+	_, err = log.Append([]byte("torn"))
+	c.Assert(err, gc.ErrorMatches, ".*write failed")
+
+	// A failed sync leaves the complete frame in the segment.
+	f.failWrite, f.failSync = false, true
+	_, err = log.Append([]byte("unsynced"))
+	c.Assert(err, gc.ErrorMatches, ".*sync failed")
+
+	f.failSync = false
+	seq, err := log.Append([]byte("2"))
+	c.Assert(err, gc.IsNil)
+	c.Assert(seq, gc.Equals, uint64(2))
+	c.Assert(log.Close(), gc.IsNil)
+
+	log, err = Open(dir, Options{})
+	c.Assert(err, gc.IsNil)
+	defer func() { c.Assert(log.Close(), gc.IsNil) }()
+	c.Assert(log.LastSeq(), gc.Equals, uint64(2))
+	c.Assert(replayAll(c, log, 1), gc.DeepEquals, []string{"1", "2"})
+}
+
+// TestFailedRollback verifies that the log rejects appends once a failed
+// append could not be rolled back.
+func (s *WALTestSuite) TestFailedRollback(c *gc.C) {
+	log, err := Open(c.MkDir(), Options{SyncPolicy: SyncNever})
+	c.Assert(err, gc.IsNil)
+	defer func() { c.Assert(log.Close(), gc.IsNil) }()
+
+	f := &faultyFile{segmentFile: log.active, failWrite: true, failTruncate: true}
+	log.active = f
+	_, err = log.Append([]byte("torn"))
+	c.Assert(err, gc.ErrorMatches, ".*rollback failed: truncate failed")
+
+	f.failWrite, f.failTruncate = false, false
+	_, err = log.Append([]byte("1"))
+	c.Assert(err, gc.ErrorMatches, ".*log unusable after failed rollback.*")
+}
+
+// faultyFile wraps the active segment of a log and injects write, sync and
+// truncate errors. Failed writes persist half of the provided data.
+type faultyFile struct {
+	segmentFile
+	failWrite    bool
+	failSync     bool
+	failTruncate bool
+}
+
+func (f *faultyFile) Write(p []byte) (int, error) {
+	if f.failWrite {
+		n, _ := f.segmentFile.Write(p[:len(p)/2])
+		return n, xerrors.New("write failed")
+	}
+	return f.segmentFile.Write(p)
+}
+
+func (f *faultyFile) Sync() error {
+	if f.failSync {
+		return xerrors.New("sync failed")
+	}
+	return f.segmentFile.Sync()
+}
+
+func (f *faultyFile) Truncate(size int64) error {
+	if f.failTruncate {
+		return xerrors.New("truncate failed")
+	}
+	return f.segmentFile.Truncate(size)
+}
+
+func replayAll(c *gc.C, log *Log, fromSeq uint64) []string {
+	var got []string
+	err := log.Replay(fromSeq, func(_ uint64, payload []byte) error {
+		got = append(got, string(payload))
+		return nil
+	})
+	c.Assert(err, gc.IsNil)
+	return got
+}
@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"golang.org/x/xerrors"
+)
+
+// Compile-time check for ensuring InMemoryGraph implements Watcher.
+var _ graph.Watcher = (*InMemoryGraph)(nil)
+
+// defaultEventRetention is the default number of events that are retained
+// for watchers.
+const defaultEventRetention = 1024
+
+// seqCursor returns the cursor of the event with the specified sequence
+// number. Sequence numbers are zero-padded so that cursors compare in
+// sequence order.
+func seqCursor(seq uint64) graph.EventCursor {
+	return graph.EventCursor(fmt.Sprintf("%020d", seq))
+}
+
+// cursorSeq returns the sequence number encoded in cursor. The empty cursor
+// maps to sequence number zero.
+func cursorSeq(cursor graph.EventCursor) (uint64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	seq, err := strconv.ParseUint(string(cursor), 10, 64)
+	if err != nil || seqCursor(seq) != cursor {
+		return 0, xerrors.Errorf("%q: %w", cursor, graph.ErrInvalidEventCursor)
+	}
+	return seq, nil
+}
+
+// eventLog retains the most recent graph events for watchers. Events are
+// assigned consecutive sequence numbers so the retained events always form
+// a contiguous range ending at lastSeq.
+type eventLog struct {
+	lastSeq   uint64
+	events    []*graph.Event
+	retention int
+
+	// updatedCh is closed and replaced whenever an event is appended.
+	updatedCh chan struct{}
+}
+
+func newEventLog() *eventLog {
+	return &eventLog{
+		retention: defaultEventRetention,
+		updatedCh: make(chan struct{}),
+	}
+}
+
+// reset drops all retained events, continues numbering events after
+// lastSeq and wakes up any blocked watchers.
+func (l *eventLog) reset(lastSeq uint64) {
+	l.lastSeq = lastSeq
+	l.events = nil
+
+	close(l.updatedCh)
+	l.updatedCh = make(chan struct{})
+}
+
+// append assigns the next sequence number to ev, adds it to the log and
+// wakes up any blocked watchers.
+func (l *eventLog) append(ev *graph.Event) {
+	l.lastSeq++
+	ev.Cursor = seqCursor(l.lastSeq)
+	l.events = append(l.events, ev)
+
+	// Trimming is amortized by only compacting the log once it has grown
+	// to twice its retention.
+	if len(l.events) >= 2*l.retention {
+		l.events = append([]*graph.Event(nil), l.events[len(l.events)-l.retention:]...)
+	}
+
+	close(l.updatedCh)
+	l.updatedCh = make(chan struct{})
+}
+
+// after returns copies of up to max events that follow afterSeq and a
+// channel that is closed when the next event is appended.
+func (l *eventLog) after(afterSeq uint64, max int) ([]*graph.Event, <-chan struct{}, error) {
+	if afterSeq > l.lastSeq || afterSeq < l.lastSeq-uint64(len(l.events)) {
+		return nil, nil, graph.ErrEventsUnavailable
+	}
+
+	pending := l.events[len(l.events)-int(l.lastSeq-afterSeq):]
+	if len(pending) > max {
+		pending = pending[:max]
+	}
+
+	page := make([]*graph.Event, len(pending))
+	for i, ev := range pending {
+		page[i] = copyEvent(ev)
+	}
+	return page, l.updatedCh, nil
+}
+
+// copyEvent returns a deep copy of ev.
+func copyEvent(ev *graph.Event) *graph.Event {
+	evCopy := new(graph.Event)
+	*evCopy = *ev
+	if ev.Link != nil {
+		evCopy.Link = new(graph.Link)
+		*evCopy.Link = *ev.Link
+	}
+	if ev.Edge != nil {
+		evCopy.Edge = new(graph.Edge)
+		*evCopy.Edge = *ev.Edge
+	}
+	return evCopy
+}
+
+// SetEventRetention configures the number of recent events that the graph
+// retains for watchers. Watchers that fall further behind receive
+// graph.ErrEventsUnavailable. It must be called before the graph is used.
+func (s *InMemoryGraph) SetEventRetention(n int) {
+	if n < 1 {
+		n = 1
+	}
+	s.events.retention = n
+}
+
+// Watch implements graph.Watcher. Only the most recent events are retained
+// (see SetEventRetention). Events are identified by consecutive sequence
+// numbers which, for durable graphs, match the sequence numbers of their WAL
+// records; events that were replayed from the WAL on startup are not
+// available to watchers.
+func (s *InMemoryGraph) Watch(ctx context.Context, after graph.EventCursor) (graph.EventIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("watch: %w", err)
+	}
+
+	afterSeq, err := cursorSeq(after)
+	if err != nil {
+		return nil, xerrors.Errorf("watch: %w", err)
+	}
+
+	s.mu.RLock()
+	_, _, err = s.events.after(afterSeq, 0)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, xerrors.Errorf("watch: %w", err)
+	}
+
+	fetchPage := func(afterSeq uint64) ([]*graph.Event, <-chan struct{}, error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return s.events.after(afterSeq, pageSize)
+	}
+
+	return &eventIterator{ctx: ctx, fetchPage: fetchPage, lastSeq: afterSeq}, nil
+}
+
+// LastEventCursor implements graph.Watcher.
+func (s *InMemoryGraph) LastEventCursor(ctx context.Context) (graph.EventCursor, error) {
+	if err := ctx.Err(); err != nil {
+		return "", xerrors.Errorf("last event cursor: %w", err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return seqCursor(s.events.lastSeq), nil
+}
@@ -0,0 +1,153 @@
+// Package rewrite converts the URLs of links that were stored before URL
+// canonicalization was introduced into their canonical form.
+package rewrite
+
+import (
+	"context"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/partition"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/relink"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// Config encapsulates the settings for a rewrite run.
+type Config struct {
+	// The canonicalizer to apply to link URLs. If not specified,
+	// urlcanon.Default is used.
+	Canonicalizer *urlcanon.Canonicalizer
+
+	// The number of partitions to split the link ID space into. Links are
+	// scanned and rewritten one partition at a time which bounds the
+	// amount of memory required for the run. Defaults to 1.
+	NumPartitions int
+
+	// When set, links are only scanned and counted; the graph is not
+	// modified.
+	DryRun bool
+}
+
+// Stats summarizes the outcome of a rewrite run.
+type Stats struct {
+	// The number of links that were scanned.
+	Scanned int
+
+	// The number of links whose URL was not in canonical form. Unless the
+	// run is a dry-run, each of these links has been replaced by its
+	// canonical counterpart.
+	Rewritten int
+
+	// The number of links whose URL could not be canonicalized. These
+	// links are left untouched.
+	Invalid int
+}
+
+// Run scans all links in g and replaces each link whose URL is not in
+// canonical form with the link for its canonical URL. The canonical link is
+// created if it does not exist yet; otherwise, the two links are merged. The
+// edges that originate from or point to the replaced link are moved over to
+// the canonical link before the replaced link is removed.
+//
+// The graph should be configured to apply the same canonicalization rules to
+// upserted links as the ones specified in cfg.
+func Run(ctx context.Context, g graph.Graph, cfg Config) (Stats, error) {
+	var stats Stats
+	if cfg.Canonicalizer == nil {
+		cfg.Canonicalizer = urlcanon.Default
+	}
+	if cfg.NumPartitions <= 0 {
+		cfg.NumPartitions = 1
+	}
+
+	r, err := partition.NewFullRange(cfg.NumPartitions)
+	if err != nil {
+		return stats, xerrors.Errorf("rewrite: %w", err)
+	}
+
+	for p := 0; p < r.NumPartitions(); p++ {
+		from, to, err := r.PartitionExtents(p)
+		if err != nil {
+			return stats, xerrors.Errorf("rewrite: %w", err)
+		}
+
+		links, err := nonCanonicalLinks(ctx, g, cfg.Canonicalizer, from, to, &stats)
+		if err != nil {
+			return stats, xerrors.Errorf("rewrite: %w", err)
+		}
+
+		for _, link := range links {
+			if !cfg.DryRun {
+				if err = rewriteLink(ctx, g, link); err != nil {
+					return stats, xerrors.Errorf("rewrite: %w", err)
+				}
+			}
+			stats.Rewritten++
+		}
+	}
+
+	return stats, nil
+}
+
+// nonCanonicalLinks returns the links in the [from, to) range whose URL is
+// not in canonical form. The URL of each returned link is set to its
+// canonical form.
+func nonCanonicalLinks(ctx context.Context, g graph.Graph, canon *urlcanon.Canonicalizer, from, to uuid.UUID, stats *Stats) ([]*graph.Link, error) {
+	it, err := g.LinksContext(ctx, from, to, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = it.Close() }()
+
+	var links []*graph.Link
+	for it.Next() {
+		stats.Scanned++
+
+		link := it.Link()
+		canonicalURL, err := canon.Canonicalize(link.URL)
+		if err != nil {
+			stats.Invalid++
+			continue
+		} else if canonicalURL == link.URL {
+			continue
+		}
+
+		rewritten := *link
+		rewritten.URL = canonicalURL
+		links = append(links, &rewritten)
+	}
+	if err = it.Error(); err != nil {
+		return nil, err
+	}
+
+	return links, it.Close()
+}
+
+// rewriteLink upserts the canonical version of link, moves all edges of the
+// link with the original ID over to it and removes the original link.
+func rewriteLink(ctx context.Context, g graph.Graph, link *graph.Link) error {
+	origID := link.ID
+	canonical := *link
+	canonical.ID = uuid.Nil
+	if err := g.UpsertLinkContext(ctx, &canonical); err != nil {
+		return err
+	}
+
+	edges, err := relink.LinkEdges(ctx, g, origID)
+	if err != nil {
+		return err
+	}
+
+	if len(edges) != 0 {
+		if err = g.UpsertEdgesContext(ctx, relink.Remap(edges, origID, canonical.ID)); err != nil {
+			return err
+		}
+	}
+
+	if err = g.RemoveLinkContext(ctx, origID); err != nil && !xerrors.Is(err, graph.ErrNotFound) {
+		return err
+	}
+	return nil
+}
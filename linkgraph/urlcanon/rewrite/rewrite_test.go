@@ -0,0 +1,151 @@
+package rewrite
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(RewriteTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type RewriteTestSuite struct {
+	g *memory.InMemoryGraph
+
+	// Links populated by SetUpTest, keyed by their (legacy) URL.
+	links map[string]*graph.Link
+}
+
+// SetUpTest populates a graph with links that were stored without any URL
+// canonicalization.
+func (s *RewriteTestSuite) SetUpTest(c *gc.C) {
+	s.g = memory.NewInMemoryGraph()
+	s.g.SetCanonicalizer(nil)
+
+	s.links = make(map[string]*graph.Link)
+	for _, u := range []string{
+		"http://example.com/",
+		"HTTP://Example.com:80/#top",
+		"http://example.com/about",
+		"http://example.com/About#team",
+		"http://other.example/",
+		"http://example.com/%zz",
+	} {
+		link := &graph.Link{URL: u, RetrievedAt: time.Now().Add(-time.Hour).Truncate(time.Second).UTC()}
+		c.Assert(s.g.UpsertLink(link), gc.IsNil)
+		s.links[u] = link
+	}
+
+	// Move the retrieval time of one of the duplicates forward so that we
+	// can check that the most recent timestamp survives the merge.
+	s.links["HTTP://Example.com:80/#top"].RetrievedAt = time.Now().Truncate(time.Second).UTC()
+	c.Assert(s.g.UpsertLink(s.links["HTTP://Example.com:80/#top"]), gc.IsNil)
+
+	// Attach crawl metadata to a link whose canonical counterpart does not
+	// exist yet so that we can check that it is carried over.
+	team := s.links["http://example.com/About#team"]
+	team.FirstSeenAt = time.Now().Add(-24 * time.Hour).Truncate(time.Second).UTC()
+	team.RetrievedAt = time.Now().Add(-time.Minute).Truncate(time.Second).UTC()
+	team.StatusCode = 404
+	team.ContentType = "text/html"
+	team.ContentHash = "deadbeef"
+	team.FetchErrors = 2
+	team.LastError = "not found"
+	c.Assert(s.g.UpsertLink(team), gc.IsNil)
+
+	for _, e := range [][2]string{
+		{"HTTP://Example.com:80/#top", "http://example.com/About#team"},
+		{"HTTP://Example.com:80/#top", "HTTP://Example.com:80/#top"},
+		{"http://example.com/", "http://other.example/"},
+		{"http://other.example/", "HTTP://Example.com:80/#top"},
+	} {
+		edge := &graph.Edge{Src: s.links[e[0]].ID, Dst: s.links[e[1]].ID, AnchorText: e[1], NoFollow: true}
+		c.Assert(s.g.UpsertEdge(edge), gc.IsNil)
+	}
+
+	s.g.SetCanonicalizer(urlcanon.Default)
+}
+
+// TestRewrite verifies that links with non-canonical URLs are merged into
+// their canonical counterparts together with their edges.
+func (s *RewriteTestSuite) TestRewrite(c *gc.C) {
+	stats, err := Run(context.TODO(), s.g, Config{NumPartitions: 3})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats.Rewritten, gc.Equals, 2)
+	c.Assert(stats.Invalid, gc.Equals, 1)
+
+	// The newly created "http://example.com/About" link is scanned a second
+	// time if its random ID falls into a partition that is processed later.
+	c.Assert(stats.Scanned == 6 || stats.Scanned == 7, gc.Equals, true, gc.Commentf("scanned %d links", stats.Scanned))
+
+	links := graphtest.AllLinks(c, s.g)
+	var urls []string
+	for u := range links {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	c.Assert(urls, gc.DeepEquals, []string{
+		"http://example.com/",
+		"http://example.com/%zz",
+		"http://example.com/About",
+		"http://example.com/about",
+		"http://other.example/",
+	})
+
+	// The canonical root link must retain its ID and inherit the more
+	// recent retrieval timestamp of the duplicate.
+	root := links["http://example.com/"]
+	c.Assert(root.ID, gc.Equals, s.links["http://example.com/"].ID)
+	c.Assert(root.RetrievedAt, gc.Equals, s.links["HTTP://Example.com:80/#top"].RetrievedAt)
+
+	// Links without a canonical counterpart must retain their crawl
+	// metadata when they are moved to their canonical URL.
+	team := s.links["http://example.com/About#team"]
+	about := links["http://example.com/About"]
+	c.Assert(about.ID, gc.Not(gc.Equals), team.ID)
+	c.Assert(about.FirstSeenAt, gc.Equals, team.FirstSeenAt)
+	c.Assert(about.RetrievedAt, gc.Equals, team.RetrievedAt)
+	c.Assert(about.StatusCode, gc.Equals, 404)
+	c.Assert(about.ContentType, gc.Equals, "text/html")
+	c.Assert(about.ContentHash, gc.Equals, "deadbeef")
+	c.Assert(about.FetchErrors, gc.Equals, 2)
+	c.Assert(about.LastError, gc.Equals, "not found")
+
+	expEdges := []string{
+		"http://example.com/ -> http://example.com/",
+		"http://example.com/ -> http://example.com/About",
+		"http://example.com/ -> http://other.example/",
+		"http://other.example/ -> http://example.com/",
+	}
+	c.Assert(graphtest.AllEdges(c, s.g), gc.DeepEquals, expEdges)
+
+	// Moved edges must retain their attributes.
+	it, err := s.g.InboundEdges(links["http://example.com/About"].ID, time.Now())
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Edge().AnchorText, gc.Equals, "http://example.com/About#team")
+	c.Assert(it.Edge().NoFollow, gc.Equals, true)
+	c.Assert(it.Close(), gc.IsNil)
+
+	// Running the rewrite again must be a no-op.
+	stats, err = Run(context.TODO(), s.g, Config{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Scanned: 5, Invalid: 1})
+}
+
+// TestDryRun verifies that a dry-run reports the links that would be
+// rewritten without modifying the graph.
+func (s *RewriteTestSuite) TestDryRun(c *gc.C) {
+	stats, err := Run(context.TODO(), s.g, Config{DryRun: true})
+	c.Assert(err, gc.IsNil)
+	c.Assert(stats, gc.DeepEquals, Stats{Scanned: 6, Rewritten: 2, Invalid: 1})
+	c.Assert(graphtest.AllLinks(c, s.g), gc.HasLen, 6)
+}
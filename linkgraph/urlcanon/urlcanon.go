@@ -0,0 +1,326 @@
+// Package urlcanon converts URLs into a canonical form so that URLs which
+// point to the same resource but are spelled differently map to the same
+// link in the graph.
+package urlcanon
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/xerrors"
+)
+
+// ErrInvalidURL is returned when a URL cannot be parsed.
+var ErrInvalidURL = xerrors.New("invalid URL")
+
+var (
+	// Default is a Canonicalizer that applies all normalisation rules
+	// except for the removal of tracking parameters.
+	Default = New(Options{})
+
+	// defaultPorts maps URL schemes to the ports that they use when no port
+	// is explicitly specified.
+	defaultPorts = map[string]string{
+		"http":  "80",
+		"https": "443",
+		"ws":    "80",
+		"wss":   "443",
+		"ftp":   "21",
+	}
+
+	// DefaultTrackingParams lists the query parameters that are removed
+	// when tracking-parameter removal is enabled and no custom list is
+	// provided. Entries ending in '*' match any parameter with that prefix.
+	DefaultTrackingParams = []string{
+		"utm_*",
+		"gclid",
+		"dclid",
+		"fbclid",
+		"msclkid",
+		"yclid",
+		"igshid",
+		"mc_cid",
+		"mc_eid",
+		"_ga",
+	}
+
+	// hostProfile converts internationalised host names into their ASCII
+	// (punycode) form. Unlike idna.Lookup, it does not reject host names
+	// that contain characters such as underscores which are commonly seen
+	// in the wild.
+	hostProfile = idna.New(
+		idna.MapForLookup(),
+		idna.Transitional(false),
+		idna.StrictDomainName(false),
+	)
+)
+
+// Options configures a Canonicalizer.
+type Options struct {
+	// StripTrackingParams enables the removal of tracking query parameters.
+	StripTrackingParams bool
+
+	// TrackingParams overrides the list of parameters that are considered
+	// to be tracking parameters. If empty, DefaultTrackingParams is used.
+	TrackingParams []string
+}
+
+// Canonicalizer converts URLs into their canonical form. The following
+// normalisation rules are applied:
+//
+//   - scheme and host are converted to lowercase.
+//   - internationalised host names are converted to punycode.
+//   - the port is removed if it is the default port for the scheme.
+//   - an empty path is replaced by "/" for URLs with a host.
+//   - percent-encoded unreserved characters are decoded and the hex digits
+//     of the remaining escape sequences are converted to uppercase.
+//   - the fragment is removed.
+//   - (optional) tracking query parameters are removed.
+//
+// A Canonicalizer is safe for concurrent use.
+type Canonicalizer struct {
+	stripTracking    bool
+	trackingParams   map[string]struct{}
+	trackingPrefixes []string
+}
+
+// New returns a Canonicalizer configured with opts.
+func New(opts Options) *Canonicalizer {
+	c := &Canonicalizer{
+		stripTracking:  opts.StripTrackingParams,
+		trackingParams: make(map[string]struct{}),
+	}
+
+	params := opts.TrackingParams
+	if len(params) == 0 {
+		params = DefaultTrackingParams
+	}
+	for _, param := range params {
+		param = strings.ToLower(param)
+		if strings.HasSuffix(param, "*") {
+			c.trackingPrefixes = append(c.trackingPrefixes, strings.TrimSuffix(param, "*"))
+			continue
+		}
+		c.trackingParams[param] = struct{}{}
+	}
+
+	return c
+}
+
+// Canonicalize returns the canonical form of rawURL using the Default
+// canonicalizer.
+func Canonicalize(rawURL string) (string, error) {
+	return Default.Canonicalize(rawURL)
+}
+
+// Canonicalize returns the canonical form of rawURL. Applying Canonicalize
+// to its own output always yields the same URL. A nil Canonicalizer returns
+// rawURL unchanged.
+func (c *Canonicalizer) Canonicalize(rawURL string) (string, error) {
+	if c == nil {
+		return rawURL, nil
+	}
+
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", xerrors.Errorf("canonicalize %q: %w", rawURL, ErrInvalidURL)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Host, err = canonicalHost(u.Scheme, u.Host); err != nil {
+		return "", xerrors.Errorf("canonicalize %q: %w", rawURL, err)
+	}
+
+	if u.Opaque == "" {
+		escapedPath := normalizeEscapes(u.EscapedPath())
+		if escapedPath == "" && u.Host != "" {
+			escapedPath = "/"
+		}
+		if u.Path, err = url.PathUnescape(escapedPath); err != nil {
+			return "", xerrors.Errorf("canonicalize %q: %w", rawURL, ErrInvalidURL)
+		}
+		u.RawPath = escapedPath
+	}
+
+	u.RawQuery = normalizeEscapes(u.RawQuery)
+	if c.stripTracking {
+		u.RawQuery = c.stripTrackingParams(u.RawQuery)
+	}
+	u.ForceQuery = false
+	u.Fragment, u.RawFragment = "", ""
+
+	return u.String(), nil
+}
+
+// canonicalHost lowercases host, converts it to punycode and removes the port
+// if it is the default one for scheme.
+func canonicalHost(scheme, host string) (string, error) {
+	if host == "" {
+		return "", nil
+	}
+
+	hostname, port := host, ""
+	if i := strings.LastIndexByte(host, ':'); i != -1 && !strings.HasSuffix(host, "]") {
+		hostname, port = host[:i], host[i+1:]
+	}
+	if port == defaultPorts[scheme] {
+		port = ""
+	}
+
+	if strings.HasPrefix(hostname, "[") {
+		// IPv6 literals only need to be lowercased.
+		hostname = strings.ToLower(hostname)
+	} else if net.ParseIP(hostname) == nil {
+		var err error
+		if hostname, err = hostProfile.ToASCII(strings.ToLower(hostname)); err != nil {
+			return "", ErrInvalidURL
+		}
+		hostname = strings.TrimSuffix(hostname, ".")
+	}
+
+	if port == "" {
+		return hostname, nil
+	}
+	return hostname + ":" + port, nil
+}
+
+// Hostname returns the canonical host name (see CanonicalHostname) of the
+// host that rawURL refers to. An empty string is returned if rawURL cannot
+// be parsed or does not contain a host.
+func Hostname(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+
+	hostname, err := CanonicalHostname(u.Host)
+	if err != nil {
+		return strings.ToLower(u.Hostname())
+	}
+	return hostname
+}
+
+// CanonicalHostname converts host into the form used by canonical URLs. Any
+// port is removed, the host name is lowercased and converted to punycode and
+// IPv6 literals are stripped of their enclosing brackets.
+func CanonicalHostname(host string) (string, error) {
+	hostname := strings.TrimSpace(host)
+	if strings.HasPrefix(hostname, "[") {
+		end := strings.IndexByte(hostname, ']')
+		if end == -1 {
+			return "", ErrInvalidURL
+		}
+		return strings.ToLower(hostname[1:end]), nil
+	}
+
+	// Bare IPv6 literals contain more than one colon.
+	if i := strings.IndexByte(hostname, ':'); i != -1 && strings.Count(hostname, ":") == 1 {
+		hostname = hostname[:i]
+	}
+	if net.ParseIP(hostname) != nil {
+		return strings.ToLower(hostname), nil
+	}
+
+	hostname, err := hostProfile.ToASCII(strings.ToLower(hostname))
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+	return strings.TrimSuffix(hostname, "."), nil
+}
+
+// stripTrackingParams removes tracking parameters from the raw query string
+// while retaining the order and encoding of the remaining parameters.
+func (c *Canonicalizer) stripTrackingParams(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	params := strings.Split(rawQuery, "&")
+	kept := params[:0]
+	for _, param := range params {
+		if param == "" {
+			continue
+		}
+
+		key := param
+		if i := strings.IndexByte(param, '='); i != -1 {
+			key = param[:i]
+		}
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+
+		if !c.isTrackingParam(strings.ToLower(key)) {
+			kept = append(kept, param)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+func (c *Canonicalizer) isTrackingParam(key string) bool {
+	if _, found := c.trackingParams[key]; found {
+		return true
+	}
+	for _, prefix := range c.trackingPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeEscapes decodes percent-encoded unreserved characters and converts
+// the hex digits of all other escape sequences to uppercase as described in
+// RFC 3986, section 6.2.2. Malformed escape sequences are left untouched.
+func normalizeEscapes(s string) string {
+	if strings.IndexByte(s, '%') == -1 {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		if v := unhex(s[i+1])<<4 | unhex(s[i+2]); isUnreserved(v) {
+			b.WriteByte(v)
+		} else {
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+// isUnreserved returns true if c belongs to the unreserved character set
+// defined by RFC 3986, section 2.3.
+func isUnreserved(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	case c == '-', c == '.', c == '_', c == '~':
+		return true
+	}
+	return false
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
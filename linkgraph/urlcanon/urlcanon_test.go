@@ -0,0 +1,131 @@
+package urlcanon
+
+import (
+	"testing"
+
+	"golang.org/x/xerrors"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(CanonicalizerTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type CanonicalizerTestSuite struct{}
+
+// TestCanonicalize verifies the normalisation rules applied by the default
+// canonicalizer.
+func (s *CanonicalizerTestSuite) TestCanonicalize(c *gc.C) {
+	specs := []struct {
+		descr string
+		in    string
+		exp   string
+	}{
+		{descr: "already canonical", in: "https://example.com/a?b=c", exp: "https://example.com/a?b=c"},
+		{descr: "scheme and host case", in: "HTTP://Example.COM/Path", exp: "http://example.com/Path"},
+		{descr: "default http port", in: "http://example.com:80/a", exp: "http://example.com/a"},
+		{descr: "default https port", in: "https://example.com:443/a", exp: "https://example.com/a"},
+		{descr: "non-default port", in: "http://example.com:8080/a", exp: "http://example.com:8080/a"},
+		{descr: "port of another scheme", in: "https://example.com:80/a", exp: "https://example.com:80/a"},
+		{descr: "empty port", in: "http://example.com:/a", exp: "http://example.com/a"},
+		{descr: "fragment", in: "http://example.com/a#frag", exp: "http://example.com/a"},
+		{descr: "empty path", in: "http://example.com", exp: "http://example.com/"},
+		{descr: "empty query", in: "http://example.com/a?", exp: "http://example.com/a"},
+		{descr: "all combined", in: "HTTP://Example.com:80/a#frag", exp: "http://example.com/a"},
+		{descr: "unreserved escapes", in: "http://example.com/%7Euser/%61bc", exp: "http://example.com/~user/abc"},
+		{descr: "reserved escape case", in: "http://example.com/a%2fb%3a?q=%3d%7e", exp: "http://example.com/a%2Fb%3A?q=%3D~"},
+		{descr: "non-ASCII escapes", in: "http://example.com/caf%c3%a9", exp: "http://example.com/caf%C3%A9"},
+		{descr: "IDN host", in: "http://Bücher.example/", exp: "http://xn--bcher-kva.example/"},
+		{descr: "IDN host with port", in: "https://bücher.example:443/a", exp: "https://xn--bcher-kva.example/a"},
+		{descr: "punycode host", in: "http://xn--bcher-kva.example/", exp: "http://xn--bcher-kva.example/"},
+		{descr: "trailing dot in host", in: "http://example.com./a", exp: "http://example.com/a"},
+		{descr: "IPv4 host", in: "http://127.0.0.1:80/a", exp: "http://127.0.0.1/a"},
+		{descr: "IPv6 host", in: "http://[::FFFF:1]:80/a", exp: "http://[::ffff:1]/a"},
+		{descr: "underscore in host", in: "http://my_host.example.com/", exp: "http://my_host.example.com/"},
+		{descr: "tracking params kept by default", in: "http://example.com/?utm_source=x&a=1", exp: "http://example.com/?utm_source=x&a=1"},
+		{descr: "surrounding whitespace", in: "  http://example.com/a \n", exp: "http://example.com/a"},
+		{descr: "relative URL", in: "foo", exp: "foo"},
+	}
+
+	for specIndex, spec := range specs {
+		c.Logf("[spec %d] %s", specIndex, spec.descr)
+		got, err := Canonicalize(spec.in)
+		c.Assert(err, gc.IsNil)
+		c.Assert(got, gc.Equals, spec.exp)
+
+		// Canonicalization must be idempotent.
+		again, err := Canonicalize(got)
+		c.Assert(err, gc.IsNil)
+		c.Assert(again, gc.Equals, got)
+	}
+}
+
+// TestStripTrackingParams verifies that tracking parameters are only
+// removed when requested.
+func (s *CanonicalizerTestSuite) TestStripTrackingParams(c *gc.C) {
+	canon := New(Options{StripTrackingParams: true})
+	specs := []struct {
+		in  string
+		exp string
+	}{
+		{in: "http://example.com/?utm_source=x&utm_medium=y", exp: "http://example.com/"},
+		{in: "http://example.com/?a=1&UTM_Campaign=z&b=2", exp: "http://example.com/?a=1&b=2"},
+		{in: "http://example.com/?fbclid=abc&q=go&gclid=def", exp: "http://example.com/?q=go"},
+		{in: "http://example.com/?utm%5Fsource=x&q=1", exp: "http://example.com/?q=1"},
+		{in: "http://example.com/?q=utm_source", exp: "http://example.com/?q=utm_source"},
+		{in: "http://example.com/?a=1&&b=2", exp: "http://example.com/?a=1&b=2"},
+	}
+
+	for specIndex, spec := range specs {
+		got, err := canon.Canonicalize(spec.in)
+		c.Assert(err, gc.IsNil)
+		c.Assert(got, gc.Equals, spec.exp, gc.Commentf("spec %d", specIndex))
+	}
+
+	// Custom parameter lists replace the defaults.
+	canon = New(Options{StripTrackingParams: true, TrackingParams: []string{"ref", "src_*"}})
+	got, err := canon.Canonicalize("http://example.com/?ref=a&src_id=b&utm_source=c")
+	c.Assert(err, gc.IsNil)
+	c.Assert(got, gc.Equals, "http://example.com/?utm_source=c")
+}
+
+// TestHostname verifies the extraction of canonical host names from URLs.
+func (s *CanonicalizerTestSuite) TestHostname(c *gc.C) {
+	specs := []struct {
+		in  string
+		exp string
+	}{
+		{in: "https://example.com/a", exp: "example.com"},
+		{in: "HTTP://WWW.Example.com:8080/a", exp: "www.example.com"},
+		{in: "http://bücher.example/", exp: "xn--bcher-kva.example"},
+		{in: "http://example.com./", exp: "example.com"},
+		{in: "http://[::FFFF:1]:80/a", exp: "::ffff:1"},
+		{in: "http://127.0.0.1:80/a", exp: "127.0.0.1"},
+		{in: "/relative/path", exp: ""},
+		{in: "http://[::1/", exp: ""},
+	}
+
+	for specIndex, spec := range specs {
+		c.Assert(Hostname(spec.in), gc.Equals, spec.exp, gc.Commentf("spec %d", specIndex))
+	}
+
+	for in, exp := range map[string]string{
+		"Example.COM":    "example.com",
+		"example.com:80": "example.com",
+		"Bücher.example": "xn--bcher-kva.example",
+		"[::1]:443":      "::1",
+		"::1":            "::1",
+	} {
+		got, err := CanonicalHostname(in)
+		c.Assert(err, gc.IsNil)
+		c.Assert(got, gc.Equals, exp, gc.Commentf("host %q", in))
+	}
+}
+
+// TestInvalidURL verifies that URLs which cannot be parsed are rejected.
+func (s *CanonicalizerTestSuite) TestInvalidURL(c *gc.C) {
+	for _, in := range []string{"http://example.com/%zz", "http://[::1/", "http://exa mple.com/"} {
+		_, err := Canonicalize(in)
+		c.Assert(xerrors.Is(err, ErrInvalidURL), gc.Equals, true, gc.Commentf("URL %q: %v", in, err))
+	}
+}
@@ -0,0 +1,397 @@
+package linkgraphapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraphapi/proto"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxBatchSize caps the number of links or edges sent with a single batch
+// upsert RPC so that requests stay well below the gRPC message size limit.
+const maxBatchSize = 500
+
+// Compile-time check for ensuring LinkGraphClient implements Graph.
+var _ graph.Graph = (*LinkGraphClient)(nil)
+
+// LinkGraphClient provides an API compatible with the graph.Graph interface
+// for accessing a link graph instance exposed by a remote gRPC server.
+type LinkGraphClient struct {
+	ctx context.Context
+	cli proto.LinkGraphClient
+}
+
+// NewLinkGraphClient returns a new client instance that implements a subset
+// of the graph.Graph interface by delegating methods to a link graph instance
+// exposed by a remote gRPC sever. RPCs issued by the methods without a
+// Context suffix are bound to ctx.
+func NewLinkGraphClient(ctx context.Context, rpcClient proto.LinkGraphClient) *LinkGraphClient {
+	return &LinkGraphClient{ctx: ctx, cli: rpcClient}
+}
+
+// UpsertLink creates a new link or updates an existing link.
+func (c *LinkGraphClient) UpsertLink(link *graph.Link) error {
+	return c.UpsertLinkContext(c.ctx, link)
+}
+
+// UpsertLinkContext implements graph.Graph.
+func (c *LinkGraphClient) UpsertLinkContext(ctx context.Context, link *graph.Link) error {
+	res, err := c.cli.UpsertLink(ctx, upsertLinkRequest(link))
+	if err != nil {
+		return xerrors.Errorf("upsert link: %w", unmapError(err))
+	}
+
+	if err = applyUpsertedLink(link, res); err != nil {
+		return xerrors.Errorf("upsert link: %w", err)
+	}
+	return nil
+}
+
+// UpsertLinks creates or updates a batch of links. The links are sent to the
+// server in chunks of up to maxBatchSize links per RPC.
+func (c *LinkGraphClient) UpsertLinks(links []*graph.Link) error {
+	return c.UpsertLinksContext(c.ctx, links)
+}
+
+// UpsertLinksContext implements graph.Graph.
+func (c *LinkGraphClient) UpsertLinksContext(ctx context.Context, links []*graph.Link) error {
+	errs := make([]error, len(links))
+	for start := 0; start < len(links); start += maxBatchSize {
+		batch := links[start:minInt(start+maxBatchSize, len(links))]
+		req := &proto.UpsertLinksRequest{Links: make([]*proto.Link, len(batch))}
+		for i, link := range batch {
+			req.Links[i] = upsertLinkRequest(link)
+		}
+
+		res, err := c.cli.UpsertLinks(ctx, req)
+		if err != nil {
+			return xerrors.Errorf("upsert links: %w", unmapError(err))
+		} else if len(res.Results) != len(batch) {
+			return xerrors.Errorf("upsert links: server returned %d results for %d links", len(res.Results), len(batch))
+		}
+
+		for i, result := range res.Results {
+			if result.Error != nil {
+				errs[start+i] = xerrors.Errorf("upsert link: %w", unmapBatchItemError(result.Error))
+			} else if err = applyUpsertedLink(batch[i], result.Link); err != nil {
+				errs[start+i] = xerrors.Errorf("upsert link: %w", err)
+			}
+		}
+	}
+	return graph.NewBatchError(errs)
+}
+
+// upsertLinkRequest returns the request for upserting link. New links are
+// sent without an ID.
+func upsertLinkRequest(link *graph.Link) *proto.Link {
+	req := linkToProto(link)
+	if link.ID == uuid.Nil {
+		req.Uuid = nil
+	}
+	return req
+}
+
+// applyUpsertedLink copies the fields assigned by the server to an upserted
+// link back to link.
+func applyUpsertedLink(link *graph.Link, res *proto.Link) error {
+	if res == nil {
+		return xerrors.New("missing link in server response")
+	}
+
+	id, err := uuid.FromBytes(res.Uuid)
+	if err != nil {
+		return err
+	}
+	link.ID = id
+	link.URL = res.Url
+	link.RetrievedAt = res.RetrievedAt.AsTime()
+	link.FirstSeenAt = res.FirstSeenAt.AsTime()
+	return nil
+}
+
+// FindLink looks up a link by its ID.
+func (c *LinkGraphClient) FindLink(id uuid.UUID) (*graph.Link, error) {
+	return c.FindLinkContext(c.ctx, id)
+}
+
+// FindLinkContext implements graph.Graph.
+func (c *LinkGraphClient) FindLinkContext(ctx context.Context, id uuid.UUID) (*graph.Link, error) {
+	res, err := c.cli.FindLink(ctx, &proto.FindLinkRequest{Uuid: id[:]})
+	if err != nil {
+		return nil, xerrors.Errorf("find link: %w", unmapError(err))
+	}
+
+	link, err := linkFromProto(res)
+	if err != nil {
+		return nil, xerrors.Errorf("find link: %w", err)
+	}
+	return link, nil
+}
+
+// FindLinkByURL looks up a link by its URL.
+func (c *LinkGraphClient) FindLinkByURL(url string) (*graph.Link, error) {
+	return c.FindLinkByURLContext(c.ctx, url)
+}
+
+// FindLinkByURLContext implements graph.Graph.
+func (c *LinkGraphClient) FindLinkByURLContext(ctx context.Context, url string) (*graph.Link, error) {
+	res, err := c.cli.FindLinkByURL(ctx, &proto.FindLinkByURLRequest{Url: url})
+	if err != nil {
+		return nil, xerrors.Errorf("find link by URL: %w", unmapError(err))
+	}
+
+	link, err := linkFromProto(res)
+	if err != nil {
+		return nil, xerrors.Errorf("find link by URL: %w", err)
+	}
+	return link, nil
+}
+
+// RemoveLink deletes the link with the specified ID together with all
+// edges that originate from or point to it.
+func (c *LinkGraphClient) RemoveLink(id uuid.UUID) error {
+	return c.RemoveLinkContext(c.ctx, id)
+}
+
+// RemoveLinkContext implements graph.Graph.
+func (c *LinkGraphClient) RemoveLinkContext(ctx context.Context, id uuid.UUID) error {
+	if _, err := c.cli.RemoveLink(ctx, &proto.RemoveLinkRequest{Uuid: id[:]}); err != nil {
+		return xerrors.Errorf("remove link: %w", unmapError(err))
+	}
+	return nil
+}
+
+// Links returns an iterator for the set of links whose IDs belong to the
+// [fromID, toID) range, were retrieved before the provided timestamp and
+// match any of the provided filters.
+func (c *LinkGraphClient) Links(fromID, toID uuid.UUID, retrievedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	return c.LinksContext(c.ctx, fromID, toID, retrievedBefore, filters...)
+}
+
+// LinksContext implements graph.Graph.
+func (c *LinkGraphClient) LinksContext(ctx context.Context, fromID, toID uuid.UUID, retrievedBefore time.Time, filters ...graph.LinkFilter) (graph.LinkIterator, error) {
+	req := &proto.Range{
+		FromUuid: fromID[:],
+		ToUuid:   toID[:],
+		Filter:   timestamppb.New(retrievedBefore),
+	}
+	for _, f := range filters {
+		req.LinkFilters = append(req.LinkFilters, linkFilterToProto(f))
+	}
+
+	streamCtx, cancelFn := context.WithCancel(ctx)
+	stream, err := c.cli.Links(streamCtx, req)
+	if err != nil {
+		cancelFn()
+		return nil, xerrors.Errorf("links: %w", unmapError(err))
+	}
+
+	return &linkIterator{ctx: ctx, stream: stream, cancelFn: cancelFn}, nil
+}
+
+// LinksByHost returns an iterator for the set of links whose URL refers to
+// the specified host.
+func (c *LinkGraphClient) LinksByHost(host string) (graph.LinkIterator, error) {
+	return c.LinksByHostContext(c.ctx, host)
+}
+
+// LinksByHostContext implements graph.Graph.
+func (c *LinkGraphClient) LinksByHostContext(ctx context.Context, host string) (graph.LinkIterator, error) {
+	streamCtx, cancelFn := context.WithCancel(ctx)
+	stream, err := c.cli.LinksByHost(streamCtx, &proto.LinksByHostQuery{Host: host})
+	if err != nil {
+		cancelFn()
+		return nil, xerrors.Errorf("links by host: %w", unmapError(err))
+	}
+
+	return &linkIterator{ctx: ctx, stream: stream, cancelFn: cancelFn}, nil
+}
+
+// UpsertEdge creates a new edge or updates an existing edge.
+func (c *LinkGraphClient) UpsertEdge(edge *graph.Edge) error {
+	return c.UpsertEdgeContext(c.ctx, edge)
+}
+
+// UpsertEdgeContext implements graph.Graph.
+func (c *LinkGraphClient) UpsertEdgeContext(ctx context.Context, edge *graph.Edge) error {
+	res, err := c.cli.UpsertEdge(ctx, upsertEdgeRequest(edge))
+	if err != nil {
+		return xerrors.Errorf("upsert edge: %w", unmapError(err))
+	}
+
+	if err = applyUpsertedEdge(edge, res); err != nil {
+		return xerrors.Errorf("upsert edge: %w", err)
+	}
+	return nil
+}
+
+// UpsertEdges creates or updates a batch of edges. The edges are sent to the
+// server in chunks of up to maxBatchSize edges per RPC.
+func (c *LinkGraphClient) UpsertEdges(edges []*graph.Edge) error {
+	return c.UpsertEdgesContext(c.ctx, edges)
+}
+
+// UpsertEdgesContext implements graph.Graph.
+func (c *LinkGraphClient) UpsertEdgesContext(ctx context.Context, edges []*graph.Edge) error {
+	errs := make([]error, len(edges))
+	for start := 0; start < len(edges); start += maxBatchSize {
+		batch := edges[start:minInt(start+maxBatchSize, len(edges))]
+		req := &proto.UpsertEdgesRequest{Edges: make([]*proto.Edge, len(batch))}
+		for i, edge := range batch {
+			req.Edges[i] = upsertEdgeRequest(edge)
+		}
+
+		res, err := c.cli.UpsertEdges(ctx, req)
+		if err != nil {
+			return xerrors.Errorf("upsert edges: %w", unmapError(err))
+		} else if len(res.Results) != len(batch) {
+			return xerrors.Errorf("upsert edges: server returned %d results for %d edges", len(res.Results), len(batch))
+		}
+
+		for i, result := range res.Results {
+			if result.Error != nil {
+				errs[start+i] = xerrors.Errorf("upsert edge: %w", unmapBatchItemError(result.Error))
+			} else if err = applyUpsertedEdge(batch[i], result.Edge); err != nil {
+				errs[start+i] = xerrors.Errorf("upsert edge: %w", err)
+			}
+		}
+	}
+	return graph.NewBatchError(errs)
+}
+
+// upsertEdgeRequest returns the request for upserting edge. New edges are
+// sent without an ID.
+func upsertEdgeRequest(edge *graph.Edge) *proto.Edge {
+	req := edgeToProto(edge)
+	if edge.ID == uuid.Nil {
+		req.Uuid = nil
+	}
+	return req
+}
+
+// applyUpsertedEdge copies the fields assigned by the server to an upserted
+// edge back to edge.
+func applyUpsertedEdge(edge *graph.Edge, res *proto.Edge) error {
+	if res == nil {
+		return xerrors.New("missing edge in server response")
+	}
+
+	id, err := uuid.FromBytes(res.Uuid)
+	if err != nil {
+		return err
+	}
+	edge.ID = id
+	edge.UpdatedAt = res.UpdatedAt.AsTime()
+	edge.LinkCount = int(res.LinkCount)
+	return nil
+}
+
+// Edges returns an iterator for the set of edges whose source vertex IDs
+// belong to the [fromID, toID) range and were updated before the provided
+// timestamp.
+func (c *LinkGraphClient) Edges(fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	return c.EdgesContext(c.ctx, fromID, toID, updatedBefore)
+}
+
+// EdgesContext implements graph.Graph.
+func (c *LinkGraphClient) EdgesContext(ctx context.Context, fromID, toID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	req := &proto.Range{
+		FromUuid: fromID[:],
+		ToUuid:   toID[:],
+		Filter:   timestamppb.New(updatedBefore),
+	}
+
+	streamCtx, cancelFn := context.WithCancel(ctx)
+	stream, err := c.cli.Edges(streamCtx, req)
+	if err != nil {
+		cancelFn()
+		return nil, xerrors.Errorf("edges: %w", unmapError(err))
+	}
+
+	return &edgeIterator{ctx: ctx, stream: stream, cancelFn: cancelFn}, nil
+}
+
+// InboundEdges returns an iterator for the set of edges that point to the
+// specified link ID and were updated before the provided timestamp.
+func (c *LinkGraphClient) InboundEdges(dstID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	return c.InboundEdgesContext(c.ctx, dstID, updatedBefore)
+}
+
+// InboundEdgesContext implements graph.Graph.
+func (c *LinkGraphClient) InboundEdgesContext(ctx context.Context, dstID uuid.UUID, updatedBefore time.Time) (graph.EdgeIterator, error) {
+	req := &proto.InboundEdgesQuery{
+		DstUuid:       dstID[:],
+		UpdatedBefore: timestamppb.New(updatedBefore),
+	}
+
+	streamCtx, cancelFn := context.WithCancel(ctx)
+	stream, err := c.cli.InboundEdges(streamCtx, req)
+	if err != nil {
+		cancelFn()
+		return nil, xerrors.Errorf("inbound edges: %w", unmapError(err))
+	}
+
+	return &edgeIterator{ctx: ctx, stream: stream, cancelFn: cancelFn}, nil
+}
+
+// RemoveStaleEdges removes any edge that originates from the specified link ID
+// and was updated before the specified timestamp.
+func (c *LinkGraphClient) RemoveStaleEdges(fromID uuid.UUID, updatedBefore time.Time) error {
+	return c.RemoveStaleEdgesContext(c.ctx, fromID, updatedBefore)
+}
+
+// RemoveStaleEdgesContext implements graph.Graph.
+func (c *LinkGraphClient) RemoveStaleEdgesContext(ctx context.Context, fromID uuid.UUID, updatedBefore time.Time) error {
+	_, err := c.cli.RemoveStaleEdges(ctx, &proto.RemoveStaleEdgesQuery{
+		FromUuid:      fromID[:],
+		UpdatedBefore: timestamppb.New(updatedBefore),
+	})
+	if err != nil {
+		return xerrors.Errorf("remove stale edges: %w", unmapError(err))
+	}
+	return nil
+}
+
+// unmapBatchItemError converts the error reported for a single item of a
+// batch RPC into the error that the corresponding single-item RPC would have
+// returned.
+func unmapBatchItemError(e *proto.BatchItemError) error {
+	return unmapError(status.Error(codes.Code(e.Code), e.Message))
+}
+
+// unmapError converts gRPC status errors returned by the server back into
+// the errors defined by the graph and urlcanon packages.
+func unmapError(err error) error {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.NotFound:
+		return graph.ErrNotFound
+	case codes.FailedPrecondition:
+		return graph.ErrUnknownEdgeLinks
+	case codes.InvalidArgument:
+		if st.Message() == urlcanon.ErrInvalidURL.Error() {
+			return urlcanon.ErrInvalidURL
+		}
+		return err
+	case codes.Canceled:
+		return context.Canceled
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	default:
+		return err
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,117 @@
+package linkgraphapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph/graphtest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/store/memory"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraphapi/proto"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(LinkGraphClientTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+// LinkGraphClientTestSuite runs the graph conformance tests against a
+// LinkGraphClient that talks to an in-memory graph over a loopback gRPC
+// connection.
+type LinkGraphClientTestSuite struct {
+	graphtest.SuiteBase
+
+	srv  *grpc.Server
+	conn *grpc.ClientConn
+}
+
+func (s *LinkGraphClientTestSuite) SetUpTest(c *gc.C) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, gc.IsNil)
+
+	s.srv = grpc.NewServer()
+	proto.RegisterLinkGraphServer(s.srv, NewLinkGraphServer(memory.NewInMemoryGraph()))
+	go func() { _ = s.srv.Serve(lis) }()
+
+	s.conn, err = grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	c.Assert(err, gc.IsNil)
+	s.SetGraph(NewLinkGraphClient(context.Background(), proto.NewLinkGraphClient(s.conn)))
+}
+
+func (s *LinkGraphClientTestSuite) TearDownTest(c *gc.C) {
+	c.Assert(s.conn.Close(), gc.IsNil)
+	s.srv.Stop()
+}
+
+// TestInvalidURL verifies that invalid link URLs are reported to clients as
+// urlcanon.ErrInvalidURL.
+func (s *LinkGraphClientTestSuite) TestInvalidURL(c *gc.C) {
+	cli := NewLinkGraphClient(context.Background(), proto.NewLinkGraphClient(s.conn))
+
+	err := cli.UpsertLink(&graph.Link{URL: "http://exa mple.com/"})
+	c.Assert(xerrors.Is(err, urlcanon.ErrInvalidURL), gc.Equals, true, gc.Commentf("%v", err))
+}
+
+// TestMalformedUUID verifies that malformed UUIDs are passed through as
+// InvalidArgument errors rather than being reported as invalid URLs.
+func (s *LinkGraphClientTestSuite) TestMalformedUUID(c *gc.C) {
+	_, err := proto.NewLinkGraphClient(s.conn).FindLink(context.Background(), &proto.FindLinkRequest{Uuid: []byte{1, 2, 3}})
+	c.Assert(status.Code(err), gc.Equals, codes.InvalidArgument)
+
+	err = unmapError(err)
+	c.Assert(xerrors.Is(err, urlcanon.ErrInvalidURL), gc.Equals, false)
+	c.Assert(status.Code(err), gc.Equals, codes.InvalidArgument)
+}
+
+// TestBatchUpserts verifies that batch upserts are sent with a single RPC and
+// that errors for individual items are mapped back to the errors returned by
+// the single-item upserts.
+func (s *LinkGraphClientTestSuite) TestBatchUpserts(c *gc.C) {
+	var rpcs []string
+	countRPCs := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		rpcs = append(rpcs, method)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	conn, err := grpc.Dial(s.conn.Target(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(countRPCs))
+	c.Assert(err, gc.IsNil)
+	defer func() { c.Assert(conn.Close(), gc.IsNil) }()
+	cli := NewLinkGraphClient(context.Background(), proto.NewLinkGraphClient(conn))
+
+	links := []*graph.Link{
+		{URL: "https://example.com/a"},
+		{URL: "http://exa mple.com/"},
+		{URL: "https://example.com/b"},
+	}
+	err = cli.UpsertLinks(links)
+	var batchErr *graph.BatchError
+	c.Assert(xerrors.As(err, &batchErr), gc.Equals, true, gc.Commentf("%v", err))
+	c.Assert(batchErr.Errors[0], gc.IsNil)
+	c.Assert(xerrors.Is(batchErr.Errors[1], urlcanon.ErrInvalidURL), gc.Equals, true, gc.Commentf("%v", batchErr.Errors[1]))
+	c.Assert(batchErr.Errors[2], gc.IsNil)
+	c.Assert(links[0].ID, gc.Not(gc.Equals), uuid.Nil)
+	c.Assert(links[2].ID, gc.Not(gc.Equals), uuid.Nil)
+
+	edges := []*graph.Edge{
+		{Src: links[0].ID, Dst: links[2].ID},
+		{Src: links[0].ID, Dst: uuid.New()},
+	}
+	err = cli.UpsertEdges(edges)
+	c.Assert(xerrors.As(err, &batchErr), gc.Equals, true, gc.Commentf("%v", err))
+	c.Assert(batchErr.Errors[0], gc.IsNil)
+	c.Assert(xerrors.Is(batchErr.Errors[1], graph.ErrUnknownEdgeLinks), gc.Equals, true, gc.Commentf("%v", batchErr.Errors[1]))
+	c.Assert(edges[0].ID, gc.Not(gc.Equals), uuid.Nil)
+	c.Assert(edges[0].UpdatedAt.IsZero(), gc.Equals, false)
+
+	c.Assert(rpcs, gc.DeepEquals, []string{
+		"/proto.LinkGraph/UpsertLinks",
+		"/proto.LinkGraph/UpsertEdges",
+	})
+}
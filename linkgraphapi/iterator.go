@@ -0,0 +1,130 @@
+package linkgraphapi
+
+import (
+	"context"
+	"io"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraphapi/proto"
+	"golang.org/x/xerrors"
+)
+
+// linkStream is implemented by the client streams of the RPCs that return
+// a stream of links.
+type linkStream interface {
+	Recv() (*proto.Link, error)
+}
+
+// linkIterator is a graph.LinkIterator implementation that consumes the
+// link stream returned by the remote server.
+type linkIterator struct {
+	ctx      context.Context
+	stream   linkStream
+	cancelFn context.CancelFunc
+
+	lastErr     error
+	latchedLink *graph.Link
+}
+
+// Next implements graph.LinkIterator.
+func (it *linkIterator) Next() bool {
+	if it.lastErr != nil {
+		return false
+	} else if err := it.ctx.Err(); err != nil {
+		it.lastErr = xerrors.Errorf("link iterator: %w", err)
+		it.cancelFn()
+		return false
+	}
+
+	res, err := it.stream.Recv()
+	if err != nil {
+		if err != io.EOF {
+			it.lastErr = xerrors.Errorf("link iterator: %w", unmapError(err))
+		}
+		it.cancelFn()
+		return false
+	}
+
+	if it.latchedLink, err = linkFromProto(res); err != nil {
+		it.lastErr = xerrors.Errorf("link iterator: %w", err)
+		it.cancelFn()
+		return false
+	}
+	return true
+}
+
+// Error implements graph.LinkIterator.
+func (it *linkIterator) Error() error {
+	return it.lastErr
+}
+
+// Close implements graph.LinkIterator.
+func (it *linkIterator) Close() error {
+	it.cancelFn()
+	return nil
+}
+
+// Link implements graph.LinkIterator.
+func (it *linkIterator) Link() *graph.Link {
+	return it.latchedLink
+}
+
+// edgeStream is implemented by the client streams of the RPCs that return
+// a stream of edges.
+type edgeStream interface {
+	Recv() (*proto.Edge, error)
+}
+
+// edgeIterator is a graph.EdgeIterator implementation that consumes the
+// edge stream returned by the remote server.
+type edgeIterator struct {
+	ctx      context.Context
+	stream   edgeStream
+	cancelFn context.CancelFunc
+
+	lastErr     error
+	latchedEdge *graph.Edge
+}
+
+// Next implements graph.EdgeIterator.
+func (it *edgeIterator) Next() bool {
+	if it.lastErr != nil {
+		return false
+	} else if err := it.ctx.Err(); err != nil {
+		it.lastErr = xerrors.Errorf("edge iterator: %w", err)
+		it.cancelFn()
+		return false
+	}
+
+	res, err := it.stream.Recv()
+	if err != nil {
+		if err != io.EOF {
+			it.lastErr = xerrors.Errorf("edge iterator: %w", unmapError(err))
+		}
+		it.cancelFn()
+		return false
+	}
+
+	if it.latchedEdge, err = edgeFromProto(res); err != nil {
+		it.lastErr = xerrors.Errorf("edge iterator: %w", err)
+		it.cancelFn()
+		return false
+	}
+	return true
+}
+
+// Error implements graph.EdgeIterator.
+func (it *edgeIterator) Error() error {
+	return it.lastErr
+}
+
+// Close implements graph.EdgeIterator.
+func (it *edgeIterator) Close() error {
+	it.cancelFn()
+	return nil
+}
+
+// Edge implements graph.EdgeIterator.
+func (it *edgeIterator) Edge() *graph.Edge {
+	return it.latchedEdge
+}
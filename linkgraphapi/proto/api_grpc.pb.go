@@ -0,0 +1,645 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.12
+// source: api.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// LinkGraphClient is the client API for LinkGraph service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LinkGraphClient interface {
+	// UpsertLink inserts or updates a link.
+	UpsertLink(ctx context.Context, in *Link, opts ...grpc.CallOption) (*Link, error)
+	// UpsertLinks inserts or updates a batch of links. Failures that only
+	// affect individual links are reported in the response.
+	UpsertLinks(ctx context.Context, in *UpsertLinksRequest, opts ...grpc.CallOption) (*UpsertLinksResponse, error)
+	// FindLink looks up a link by its ID.
+	FindLink(ctx context.Context, in *FindLinkRequest, opts ...grpc.CallOption) (*Link, error)
+	// FindLinkByURL looks up a link by its URL.
+	FindLinkByURL(ctx context.Context, in *FindLinkByURLRequest, opts ...grpc.CallOption) (*Link, error)
+	// RemoveLink deletes a link and all edges that originate from or point
+	// to it.
+	RemoveLink(ctx context.Context, in *RemoveLinkRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// UpsertEdge inserts or updates an edge.
+	UpsertEdge(ctx context.Context, in *Edge, opts ...grpc.CallOption) (*Edge, error)
+	// UpsertEdges inserts or updates a batch of edges. Failures that only
+	// affect individual edges are reported in the response.
+	UpsertEdges(ctx context.Context, in *UpsertEdgesRequest, opts ...grpc.CallOption) (*UpsertEdgesResponse, error)
+	// Links streams the set of links in the specified ID range.
+	Links(ctx context.Context, in *Range, opts ...grpc.CallOption) (LinkGraph_LinksClient, error)
+	// LinksByHost streams the set of links whose URL refers to the specified
+	// host.
+	LinksByHost(ctx context.Context, in *LinksByHostQuery, opts ...grpc.CallOption) (LinkGraph_LinksByHostClient, error)
+	// Edges streams the set of edges in the specified ID range.
+	Edges(ctx context.Context, in *Range, opts ...grpc.CallOption) (LinkGraph_EdgesClient, error)
+	// InboundEdges streams the set of edges that point to the specified link.
+	InboundEdges(ctx context.Context, in *InboundEdgesQuery, opts ...grpc.CallOption) (LinkGraph_InboundEdgesClient, error)
+	// RemoveStaleEdges removes any edge that originates from the specified
+	// link ID and was updated before the specified timestamp.
+	RemoveStaleEdges(ctx context.Context, in *RemoveStaleEdgesQuery, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type linkGraphClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLinkGraphClient(cc grpc.ClientConnInterface) LinkGraphClient {
+	return &linkGraphClient{cc}
+}
+
+func (c *linkGraphClient) UpsertLink(ctx context.Context, in *Link, opts ...grpc.CallOption) (*Link, error) {
+	out := new(Link)
+	err := c.cc.Invoke(ctx, "/proto.LinkGraph/UpsertLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linkGraphClient) UpsertLinks(ctx context.Context, in *UpsertLinksRequest, opts ...grpc.CallOption) (*UpsertLinksResponse, error) {
+	out := new(UpsertLinksResponse)
+	err := c.cc.Invoke(ctx, "/proto.LinkGraph/UpsertLinks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linkGraphClient) FindLink(ctx context.Context, in *FindLinkRequest, opts ...grpc.CallOption) (*Link, error) {
+	out := new(Link)
+	err := c.cc.Invoke(ctx, "/proto.LinkGraph/FindLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linkGraphClient) FindLinkByURL(ctx context.Context, in *FindLinkByURLRequest, opts ...grpc.CallOption) (*Link, error) {
+	out := new(Link)
+	err := c.cc.Invoke(ctx, "/proto.LinkGraph/FindLinkByURL", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linkGraphClient) RemoveLink(ctx context.Context, in *RemoveLinkRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.LinkGraph/RemoveLink", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linkGraphClient) UpsertEdge(ctx context.Context, in *Edge, opts ...grpc.CallOption) (*Edge, error) {
+	out := new(Edge)
+	err := c.cc.Invoke(ctx, "/proto.LinkGraph/UpsertEdge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linkGraphClient) UpsertEdges(ctx context.Context, in *UpsertEdgesRequest, opts ...grpc.CallOption) (*UpsertEdgesResponse, error) {
+	out := new(UpsertEdgesResponse)
+	err := c.cc.Invoke(ctx, "/proto.LinkGraph/UpsertEdges", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *linkGraphClient) Links(ctx context.Context, in *Range, opts ...grpc.CallOption) (LinkGraph_LinksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LinkGraph_ServiceDesc.Streams[0], "/proto.LinkGraph/Links", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &linkGraphLinksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LinkGraph_LinksClient interface {
+	Recv() (*Link, error)
+	grpc.ClientStream
+}
+
+type linkGraphLinksClient struct {
+	grpc.ClientStream
+}
+
+func (x *linkGraphLinksClient) Recv() (*Link, error) {
+	m := new(Link)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *linkGraphClient) LinksByHost(ctx context.Context, in *LinksByHostQuery, opts ...grpc.CallOption) (LinkGraph_LinksByHostClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LinkGraph_ServiceDesc.Streams[1], "/proto.LinkGraph/LinksByHost", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &linkGraphLinksByHostClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LinkGraph_LinksByHostClient interface {
+	Recv() (*Link, error)
+	grpc.ClientStream
+}
+
+type linkGraphLinksByHostClient struct {
+	grpc.ClientStream
+}
+
+func (x *linkGraphLinksByHostClient) Recv() (*Link, error) {
+	m := new(Link)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *linkGraphClient) Edges(ctx context.Context, in *Range, opts ...grpc.CallOption) (LinkGraph_EdgesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LinkGraph_ServiceDesc.Streams[2], "/proto.LinkGraph/Edges", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &linkGraphEdgesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LinkGraph_EdgesClient interface {
+	Recv() (*Edge, error)
+	grpc.ClientStream
+}
+
+type linkGraphEdgesClient struct {
+	grpc.ClientStream
+}
+
+func (x *linkGraphEdgesClient) Recv() (*Edge, error) {
+	m := new(Edge)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *linkGraphClient) InboundEdges(ctx context.Context, in *InboundEdgesQuery, opts ...grpc.CallOption) (LinkGraph_InboundEdgesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LinkGraph_ServiceDesc.Streams[3], "/proto.LinkGraph/InboundEdges", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &linkGraphInboundEdgesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LinkGraph_InboundEdgesClient interface {
+	Recv() (*Edge, error)
+	grpc.ClientStream
+}
+
+type linkGraphInboundEdgesClient struct {
+	grpc.ClientStream
+}
+
+func (x *linkGraphInboundEdgesClient) Recv() (*Edge, error) {
+	m := new(Edge)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *linkGraphClient) RemoveStaleEdges(ctx context.Context, in *RemoveStaleEdgesQuery, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto.LinkGraph/RemoveStaleEdges", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LinkGraphServer is the server API for LinkGraph service.
+// All implementations must embed UnimplementedLinkGraphServer
+// for forward compatibility
+type LinkGraphServer interface {
+	// UpsertLink inserts or updates a link.
+	UpsertLink(context.Context, *Link) (*Link, error)
+	// UpsertLinks inserts or updates a batch of links. Failures that only
+	// affect individual links are reported in the response.
+	UpsertLinks(context.Context, *UpsertLinksRequest) (*UpsertLinksResponse, error)
+	// FindLink looks up a link by its ID.
+	FindLink(context.Context, *FindLinkRequest) (*Link, error)
+	// FindLinkByURL looks up a link by its URL.
+	FindLinkByURL(context.Context, *FindLinkByURLRequest) (*Link, error)
+	// RemoveLink deletes a link and all edges that originate from or point
+	// to it.
+	RemoveLink(context.Context, *RemoveLinkRequest) (*emptypb.Empty, error)
+	// UpsertEdge inserts or updates an edge.
+	UpsertEdge(context.Context, *Edge) (*Edge, error)
+	// UpsertEdges inserts or updates a batch of edges. Failures that only
+	// affect individual edges are reported in the response.
+	UpsertEdges(context.Context, *UpsertEdgesRequest) (*UpsertEdgesResponse, error)
+	// Links streams the set of links in the specified ID range.
+	Links(*Range, LinkGraph_LinksServer) error
+	// LinksByHost streams the set of links whose URL refers to the specified
+	// host.
+	LinksByHost(*LinksByHostQuery, LinkGraph_LinksByHostServer) error
+	// Edges streams the set of edges in the specified ID range.
+	Edges(*Range, LinkGraph_EdgesServer) error
+	// InboundEdges streams the set of edges that point to the specified link.
+	InboundEdges(*InboundEdgesQuery, LinkGraph_InboundEdgesServer) error
+	// RemoveStaleEdges removes any edge that originates from the specified
+	// link ID and was updated before the specified timestamp.
+	RemoveStaleEdges(context.Context, *RemoveStaleEdgesQuery) (*emptypb.Empty, error)
+	mustEmbedUnimplementedLinkGraphServer()
+}
+
+// UnimplementedLinkGraphServer must be embedded to have forward compatible implementations.
+type UnimplementedLinkGraphServer struct {
+}
+
+func (UnimplementedLinkGraphServer) UpsertLink(context.Context, *Link) (*Link, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertLink not implemented")
+}
+func (UnimplementedLinkGraphServer) UpsertLinks(context.Context, *UpsertLinksRequest) (*UpsertLinksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertLinks not implemented")
+}
+func (UnimplementedLinkGraphServer) FindLink(context.Context, *FindLinkRequest) (*Link, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindLink not implemented")
+}
+func (UnimplementedLinkGraphServer) FindLinkByURL(context.Context, *FindLinkByURLRequest) (*Link, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindLinkByURL not implemented")
+}
+func (UnimplementedLinkGraphServer) RemoveLink(context.Context, *RemoveLinkRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveLink not implemented")
+}
+func (UnimplementedLinkGraphServer) UpsertEdge(context.Context, *Edge) (*Edge, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertEdge not implemented")
+}
+func (UnimplementedLinkGraphServer) UpsertEdges(context.Context, *UpsertEdgesRequest) (*UpsertEdgesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertEdges not implemented")
+}
+func (UnimplementedLinkGraphServer) Links(*Range, LinkGraph_LinksServer) error {
+	return status.Errorf(codes.Unimplemented, "method Links not implemented")
+}
+func (UnimplementedLinkGraphServer) LinksByHost(*LinksByHostQuery, LinkGraph_LinksByHostServer) error {
+	return status.Errorf(codes.Unimplemented, "method LinksByHost not implemented")
+}
+func (UnimplementedLinkGraphServer) Edges(*Range, LinkGraph_EdgesServer) error {
+	return status.Errorf(codes.Unimplemented, "method Edges not implemented")
+}
+func (UnimplementedLinkGraphServer) InboundEdges(*InboundEdgesQuery, LinkGraph_InboundEdgesServer) error {
+	return status.Errorf(codes.Unimplemented, "method InboundEdges not implemented")
+}
+func (UnimplementedLinkGraphServer) RemoveStaleEdges(context.Context, *RemoveStaleEdgesQuery) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveStaleEdges not implemented")
+}
+func (UnimplementedLinkGraphServer) mustEmbedUnimplementedLinkGraphServer() {}
+
+// UnsafeLinkGraphServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LinkGraphServer will
+// result in compilation errors.
+type UnsafeLinkGraphServer interface {
+	mustEmbedUnimplementedLinkGraphServer()
+}
+
+func RegisterLinkGraphServer(s grpc.ServiceRegistrar, srv LinkGraphServer) {
+	s.RegisterService(&LinkGraph_ServiceDesc, srv)
+}
+
+func _LinkGraph_UpsertLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Link)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinkGraphServer).UpsertLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LinkGraph/UpsertLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinkGraphServer).UpsertLink(ctx, req.(*Link))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LinkGraph_UpsertLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertLinksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinkGraphServer).UpsertLinks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LinkGraph/UpsertLinks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinkGraphServer).UpsertLinks(ctx, req.(*UpsertLinksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LinkGraph_FindLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinkGraphServer).FindLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LinkGraph/FindLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinkGraphServer).FindLink(ctx, req.(*FindLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LinkGraph_FindLinkByURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindLinkByURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinkGraphServer).FindLinkByURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LinkGraph/FindLinkByURL",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinkGraphServer).FindLinkByURL(ctx, req.(*FindLinkByURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LinkGraph_RemoveLink_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveLinkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinkGraphServer).RemoveLink(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LinkGraph/RemoveLink",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinkGraphServer).RemoveLink(ctx, req.(*RemoveLinkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LinkGraph_UpsertEdge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Edge)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinkGraphServer).UpsertEdge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LinkGraph/UpsertEdge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinkGraphServer).UpsertEdge(ctx, req.(*Edge))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LinkGraph_UpsertEdges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertEdgesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinkGraphServer).UpsertEdges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LinkGraph/UpsertEdges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinkGraphServer).UpsertEdges(ctx, req.(*UpsertEdgesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LinkGraph_Links_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Range)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LinkGraphServer).Links(m, &linkGraphLinksServer{stream})
+}
+
+type LinkGraph_LinksServer interface {
+	Send(*Link) error
+	grpc.ServerStream
+}
+
+type linkGraphLinksServer struct {
+	grpc.ServerStream
+}
+
+func (x *linkGraphLinksServer) Send(m *Link) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LinkGraph_LinksByHost_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LinksByHostQuery)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LinkGraphServer).LinksByHost(m, &linkGraphLinksByHostServer{stream})
+}
+
+type LinkGraph_LinksByHostServer interface {
+	Send(*Link) error
+	grpc.ServerStream
+}
+
+type linkGraphLinksByHostServer struct {
+	grpc.ServerStream
+}
+
+func (x *linkGraphLinksByHostServer) Send(m *Link) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LinkGraph_Edges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Range)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LinkGraphServer).Edges(m, &linkGraphEdgesServer{stream})
+}
+
+type LinkGraph_EdgesServer interface {
+	Send(*Edge) error
+	grpc.ServerStream
+}
+
+type linkGraphEdgesServer struct {
+	grpc.ServerStream
+}
+
+func (x *linkGraphEdgesServer) Send(m *Edge) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LinkGraph_InboundEdges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InboundEdgesQuery)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LinkGraphServer).InboundEdges(m, &linkGraphInboundEdgesServer{stream})
+}
+
+type LinkGraph_InboundEdgesServer interface {
+	Send(*Edge) error
+	grpc.ServerStream
+}
+
+type linkGraphInboundEdgesServer struct {
+	grpc.ServerStream
+}
+
+func (x *linkGraphInboundEdgesServer) Send(m *Edge) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LinkGraph_RemoveStaleEdges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveStaleEdgesQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LinkGraphServer).RemoveStaleEdges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.LinkGraph/RemoveStaleEdges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LinkGraphServer).RemoveStaleEdges(ctx, req.(*RemoveStaleEdgesQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LinkGraph_ServiceDesc is the grpc.ServiceDesc for LinkGraph service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LinkGraph_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.LinkGraph",
+	HandlerType: (*LinkGraphServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UpsertLink",
+			Handler:    _LinkGraph_UpsertLink_Handler,
+		},
+		{
+			MethodName: "UpsertLinks",
+			Handler:    _LinkGraph_UpsertLinks_Handler,
+		},
+		{
+			MethodName: "FindLink",
+			Handler:    _LinkGraph_FindLink_Handler,
+		},
+		{
+			MethodName: "FindLinkByURL",
+			Handler:    _LinkGraph_FindLinkByURL_Handler,
+		},
+		{
+			MethodName: "RemoveLink",
+			Handler:    _LinkGraph_RemoveLink_Handler,
+		},
+		{
+			MethodName: "UpsertEdge",
+			Handler:    _LinkGraph_UpsertEdge_Handler,
+		},
+		{
+			MethodName: "UpsertEdges",
+			Handler:    _LinkGraph_UpsertEdges_Handler,
+		},
+		{
+			MethodName: "RemoveStaleEdges",
+			Handler:    _LinkGraph_RemoveStaleEdges_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Links",
+			Handler:       _LinkGraph_Links_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "LinksByHost",
+			Handler:       _LinkGraph_LinksByHost_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Edges",
+			Handler:       _LinkGraph_Edges_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "InboundEdges",
+			Handler:       _LinkGraph_InboundEdges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api.proto",
+}
@@ -0,0 +1,455 @@
+package linkgraphapi
+
+import (
+	"context"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/graph"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraph/urlcanon"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/linkgraphapi/proto"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var _ proto.LinkGraphServer = (*LinkGraphServer)(nil)
+
+// LinkGraphServer provides a gRPC layer for accessing a link graph.
+type LinkGraphServer struct {
+	proto.UnimplementedLinkGraphServer
+	g graph.Graph
+}
+
+// NewLinkGraphServer returns a new server instance that uses the provided
+// graph as its backing store.
+func NewLinkGraphServer(g graph.Graph) *LinkGraphServer {
+	return &LinkGraphServer{g: g}
+}
+
+// UpsertLink inserts or updates a link.
+func (s *LinkGraphServer) UpsertLink(ctx context.Context, req *proto.Link) (*proto.Link, error) {
+	link, err := linkFromProto(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.g.UpsertLinkContext(ctx, link); err != nil {
+		return nil, mapError(err)
+	}
+
+	return linkToProto(link), nil
+}
+
+// UpsertLinks inserts or updates a batch of links. Links that cannot be
+// decoded or upserted are reported in the results of the response; the RPC
+// only fails if the batch as a whole could not be upserted.
+func (s *LinkGraphServer) UpsertLinks(ctx context.Context, req *proto.UpsertLinksRequest) (*proto.UpsertLinksResponse, error) {
+	res := &proto.UpsertLinksResponse{Results: make([]*proto.UpsertLinkResult, len(req.Links))}
+
+	// Only the links that could be decoded are passed to the graph; resIdx
+	// maps them back to their position in the request.
+	var (
+		links  []*graph.Link
+		resIdx []int
+	)
+	for i, pl := range req.Links {
+		res.Results[i] = new(proto.UpsertLinkResult)
+		link, err := linkFromProto(pl)
+		if err != nil {
+			res.Results[i].Error = batchItemErrorToProto(err)
+			continue
+		}
+		links = append(links, link)
+		resIdx = append(resIdx, i)
+	}
+
+	batchErrs, err := batchErrors(s.g.UpsertLinksContext(ctx, links))
+	if err != nil {
+		return nil, err
+	}
+	for j, link := range links {
+		if result := res.Results[resIdx[j]]; batchErrs != nil && batchErrs[j] != nil {
+			result.Error = batchItemErrorToProto(mapError(batchErrs[j]))
+		} else {
+			result.Link = linkToProto(link)
+		}
+	}
+
+	return res, nil
+}
+
+// FindLink looks up a link by its ID.
+func (s *LinkGraphServer) FindLink(ctx context.Context, req *proto.FindLinkRequest) (*proto.Link, error) {
+	linkID, err := parseUUID(req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.g.FindLinkContext(ctx, linkID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return linkToProto(link), nil
+}
+
+// FindLinkByURL looks up a link by its URL.
+func (s *LinkGraphServer) FindLinkByURL(ctx context.Context, req *proto.FindLinkByURLRequest) (*proto.Link, error) {
+	link, err := s.g.FindLinkByURLContext(ctx, req.Url)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return linkToProto(link), nil
+}
+
+// RemoveLink deletes a link and all edges that originate from or point to
+// it.
+func (s *LinkGraphServer) RemoveLink(ctx context.Context, req *proto.RemoveLinkRequest) (*emptypb.Empty, error) {
+	linkID, err := parseUUID(req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.g.RemoveLinkContext(ctx, linkID); err != nil {
+		return nil, mapError(err)
+	}
+
+	return new(emptypb.Empty), nil
+}
+
+// UpsertEdge inserts or updates an edge.
+func (s *LinkGraphServer) UpsertEdge(ctx context.Context, req *proto.Edge) (*proto.Edge, error) {
+	edge, err := edgeFromProto(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.g.UpsertEdgeContext(ctx, edge); err != nil {
+		return nil, mapError(err)
+	}
+
+	return edgeToProto(edge), nil
+}
+
+// UpsertEdges inserts or updates a batch of edges. Edges that cannot be
+// decoded or upserted are reported in the results of the response; the RPC
+// only fails if the batch as a whole could not be upserted.
+func (s *LinkGraphServer) UpsertEdges(ctx context.Context, req *proto.UpsertEdgesRequest) (*proto.UpsertEdgesResponse, error) {
+	res := &proto.UpsertEdgesResponse{Results: make([]*proto.UpsertEdgeResult, len(req.Edges))}
+
+	// Only the edges that could be decoded are passed to the graph; resIdx
+	// maps them back to their position in the request.
+	var (
+		edges  []*graph.Edge
+		resIdx []int
+	)
+	for i, pe := range req.Edges {
+		res.Results[i] = new(proto.UpsertEdgeResult)
+		edge, err := edgeFromProto(pe)
+		if err != nil {
+			res.Results[i].Error = batchItemErrorToProto(err)
+			continue
+		}
+		edges = append(edges, edge)
+		resIdx = append(resIdx, i)
+	}
+
+	batchErrs, err := batchErrors(s.g.UpsertEdgesContext(ctx, edges))
+	if err != nil {
+		return nil, err
+	}
+	for j, edge := range edges {
+		if result := res.Results[resIdx[j]]; batchErrs != nil && batchErrs[j] != nil {
+			result.Error = batchItemErrorToProto(mapError(batchErrs[j]))
+		} else {
+			result.Edge = edgeToProto(edge)
+		}
+	}
+
+	return res, nil
+}
+
+// Links streams the set of links in the specified ID range.
+func (s *LinkGraphServer) Links(idRange *proto.Range, w proto.LinkGraph_LinksServer) error {
+	fromID, toID, err := parseRange(idRange)
+	if err != nil {
+		return err
+	}
+
+	filters := make([]graph.LinkFilter, 0, len(idRange.LinkFilters))
+	for _, f := range idRange.LinkFilters {
+		filters = append(filters, linkFilterFromProto(f))
+	}
+
+	it, err := s.g.LinksContext(w.Context(), fromID, toID, idRange.Filter.AsTime(), filters...)
+	if err != nil {
+		return mapError(err)
+	}
+	defer func() { _ = it.Close() }()
+
+	for it.Next() {
+		if err := w.Send(linkToProto(it.Link())); err != nil {
+			return err
+		}
+	}
+
+	if err = it.Error(); err != nil {
+		return mapError(err)
+	}
+	return mapError(it.Close())
+}
+
+// LinksByHost streams the set of links whose URL refers to the specified
+// host.
+func (s *LinkGraphServer) LinksByHost(req *proto.LinksByHostQuery, w proto.LinkGraph_LinksByHostServer) error {
+	it, err := s.g.LinksByHostContext(w.Context(), req.Host)
+	if err != nil {
+		return mapError(err)
+	}
+	defer func() { _ = it.Close() }()
+
+	for it.Next() {
+		if err := w.Send(linkToProto(it.Link())); err != nil {
+			return err
+		}
+	}
+
+	if err = it.Error(); err != nil {
+		return mapError(err)
+	}
+	return mapError(it.Close())
+}
+
+// Edges streams the set of edges in the specified ID range.
+func (s *LinkGraphServer) Edges(idRange *proto.Range, w proto.LinkGraph_EdgesServer) error {
+	fromID, toID, err := parseRange(idRange)
+	if err != nil {
+		return err
+	}
+
+	it, err := s.g.EdgesContext(w.Context(), fromID, toID, idRange.Filter.AsTime())
+	if err != nil {
+		return mapError(err)
+	}
+	defer func() { _ = it.Close() }()
+
+	for it.Next() {
+		if err := w.Send(edgeToProto(it.Edge())); err != nil {
+			return err
+		}
+	}
+
+	if err = it.Error(); err != nil {
+		return mapError(err)
+	}
+	return mapError(it.Close())
+}
+
+// InboundEdges streams the set of edges that point to the specified link.
+func (s *LinkGraphServer) InboundEdges(req *proto.InboundEdgesQuery, w proto.LinkGraph_InboundEdgesServer) error {
+	dstID, err := parseUUID(req.DstUuid)
+	if err != nil {
+		return err
+	}
+
+	it, err := s.g.InboundEdgesContext(w.Context(), dstID, req.UpdatedBefore.AsTime())
+	if err != nil {
+		return mapError(err)
+	}
+	defer func() { _ = it.Close() }()
+
+	for it.Next() {
+		if err := w.Send(edgeToProto(it.Edge())); err != nil {
+			return err
+		}
+	}
+
+	if err = it.Error(); err != nil {
+		return mapError(err)
+	}
+	return mapError(it.Close())
+}
+
+// RemoveStaleEdges removes any edge that originates from the specified
+// link ID and was updated before the specified timestamp.
+func (s *LinkGraphServer) RemoveStaleEdges(ctx context.Context, req *proto.RemoveStaleEdgesQuery) (*emptypb.Empty, error) {
+	fromID, err := parseUUID(req.FromUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.g.RemoveStaleEdgesContext(ctx, fromID, req.UpdatedBefore.AsTime()); err != nil {
+		return nil, mapError(err)
+	}
+
+	return new(emptypb.Empty), nil
+}
+
+// mapError converts the errors returned by graph.Graph implementations into
+// gRPC status errors that can be mapped back by the client.
+func mapError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case xerrors.Is(err, graph.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case xerrors.Is(err, graph.ErrUnknownEdgeLinks):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case xerrors.Is(err, urlcanon.ErrInvalidURL):
+		// The client recognizes invalid URLs by the message of the status
+		// as other InvalidArgument errors share the same code.
+		return status.Error(codes.InvalidArgument, urlcanon.ErrInvalidURL.Error())
+	case xerrors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case xerrors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// batchErrors returns the per-item errors of a *graph.BatchError returned by
+// a batch upsert. Any other error is mapped to a gRPC status error.
+func batchErrors(err error) ([]error, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	var batchErr *graph.BatchError
+	if !xerrors.As(err, &batchErr) {
+		return nil, mapError(err)
+	}
+	return batchErr.Errors, nil
+}
+
+// batchItemErrorToProto encodes a gRPC status error for inclusion in the
+// results of a batch RPC.
+func batchItemErrorToProto(err error) *proto.BatchItemError {
+	st := status.Convert(err)
+	return &proto.BatchItemError{Code: int32(st.Code()), Message: st.Message()}
+}
+
+// parseUUID decodes a UUID sent by the client.
+func parseUUID(b []byte) (uuid.UUID, error) {
+	id, err := uuid.FromBytes(b)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid UUID: %v", err)
+	}
+	return id, nil
+}
+
+func parseRange(idRange *proto.Range) (fromID, toID uuid.UUID, err error) {
+	if fromID, err = parseUUID(idRange.FromUuid); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	if toID, err = parseUUID(idRange.ToUuid); err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return fromID, toID, nil
+}
+
+func linkToProto(link *graph.Link) *proto.Link {
+	return &proto.Link{
+		Uuid:        link.ID[:],
+		Url:         link.URL,
+		RetrievedAt: timestamppb.New(link.RetrievedAt),
+		FirstSeenAt: timestamppb.New(link.FirstSeenAt),
+		StatusCode:  int32(link.StatusCode),
+		ContentType: link.ContentType,
+		ContentHash: link.ContentHash,
+		FetchErrors: int32(link.FetchErrors),
+		LastError:   link.LastError,
+	}
+}
+
+func linkFromProto(req *proto.Link) (*graph.Link, error) {
+	link := &graph.Link{
+		URL:         req.Url,
+		RetrievedAt: req.RetrievedAt.AsTime(),
+		FirstSeenAt: req.FirstSeenAt.AsTime(),
+		StatusCode:  int(req.StatusCode),
+		ContentType: req.ContentType,
+		ContentHash: req.ContentHash,
+		FetchErrors: int(req.FetchErrors),
+		LastError:   req.LastError,
+	}
+
+	// New links are sent without an ID.
+	if len(req.Uuid) != 0 {
+		var err error
+		if link.ID, err = parseUUID(req.Uuid); err != nil {
+			return nil, err
+		}
+	}
+	return link, nil
+}
+
+func linkFilterToProto(f graph.LinkFilter) *proto.LinkFilter {
+	pf := &proto.LinkFilter{
+		MinStatusCode:     int32(f.MinStatusCode),
+		MaxStatusCode:     int32(f.MaxStatusCode),
+		ContentTypePrefix: f.ContentTypePrefix,
+		MinFetchErrors:    int32(f.MinFetchErrors),
+	}
+	// A nil timestamp denotes a zero FirstSeenAfter value as the zero
+	// time.Time cannot be distinguished from the Unix epoch once decoded.
+	if !f.FirstSeenAfter.IsZero() {
+		pf.FirstSeenAfter = timestamppb.New(f.FirstSeenAfter)
+	}
+	return pf
+}
+
+func linkFilterFromProto(pf *proto.LinkFilter) graph.LinkFilter {
+	f := graph.LinkFilter{
+		MinStatusCode:     int(pf.MinStatusCode),
+		MaxStatusCode:     int(pf.MaxStatusCode),
+		ContentTypePrefix: pf.ContentTypePrefix,
+		MinFetchErrors:    int(pf.MinFetchErrors),
+	}
+	if pf.FirstSeenAfter != nil {
+		f.FirstSeenAfter = pf.FirstSeenAfter.AsTime()
+	}
+	return f
+}
+
+func edgeToProto(edge *graph.Edge) *proto.Edge {
+	return &proto.Edge{
+		Uuid:       edge.ID[:],
+		SrcUuid:    edge.Src[:],
+		DstUuid:    edge.Dst[:],
+		UpdatedAt:  timestamppb.New(edge.UpdatedAt),
+		AnchorText: edge.AnchorText,
+		Nofollow:   edge.NoFollow,
+		Sponsored:  edge.Sponsored,
+		Ugc:        edge.UGC,
+		LinkCount:  int32(edge.LinkCount),
+	}
+}
+
+func edgeFromProto(req *proto.Edge) (*graph.Edge, error) {
+	edge := &graph.Edge{
+		UpdatedAt:  req.UpdatedAt.AsTime(),
+		AnchorText: req.AnchorText,
+		NoFollow:   req.Nofollow,
+		Sponsored:  req.Sponsored,
+		UGC:        req.Ugc,
+		LinkCount:  int(req.LinkCount),
+	}
+
+	var err error
+	if len(req.Uuid) != 0 {
+		if edge.ID, err = parseUUID(req.Uuid); err != nil {
+			return nil, err
+		}
+	}
+	if edge.Src, err = parseUUID(req.SrcUuid); err != nil {
+		return nil, err
+	}
+	if edge.Dst, err = parseUUID(req.DstUuid); err != nil {
+		return nil, err
+	}
+	return edge, nil
+}
@@ -0,0 +1,327 @@
+// Package metrics provides counters and histograms that can be exported in
+// the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are the upper bounds (in seconds) of the histogram
+// buckets used for request latencies. They include the response time
+// thresholds of the search SLA.
+var DefaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 1.2, 2, 5, 10}
+
+// collector is implemented by the metric families that can be registered
+// with a Registry.
+type collector interface {
+	name() string
+	write(w io.Writer) error
+}
+
+// Registry is a set of metric families. It implements http.Handler and
+// serves the current values of all registered metrics in the Prometheus
+// text exposition format.
+type Registry struct {
+	mu         sync.Mutex
+	collectors map[string]collector
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// NewCounterVec registers a family of counters that are partitioned by the
+// specified labels. It panics if a metric with the same name has already
+// been registered.
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	v := &CounterVec{family: newFamily(name, help, labelNames)}
+	r.register(v)
+	return v
+}
+
+// NewHistogramVec registers a family of histograms with the specified
+// bucket upper bounds that are partitioned by the specified labels. It
+// panics if a metric with the same name has already been registered.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	v := &HistogramVec{family: newFamily(name, help, labelNames), buckets: buckets}
+	r.register(v)
+	return v
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.collectors[c.name()]; exists {
+		panic(fmt.Sprintf("metrics: duplicate metric %q", c.name()))
+	}
+	r.collectors[c.name()] = c
+}
+
+// WriteTo writes all registered metrics to w in the Prometheus text
+// exposition format. Metric families are written in name order.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	collectors := make([]collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.Unlock()
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+	for _, c := range collectors {
+		if err := c.write(cw); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, bw.Flush()
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = r.WriteTo(w)
+}
+
+// family holds the state shared by all members of a metric family.
+type family struct {
+	metricName string
+	help       string
+	labelNames []string
+
+	// mu guards the members map; members are created on first use.
+	mu      sync.Mutex
+	members map[string]interface{}
+}
+
+func newFamily(name, help string, labelNames []string) family {
+	return family{
+		metricName: name,
+		help:       help,
+		labelNames: labelNames,
+		members:    make(map[string]interface{}),
+	}
+}
+
+func (f *family) name() string { return f.metricName }
+
+// member returns the family member for the specified label values, creating
+// it via newFn if required.
+func (f *family) member(labelValues []string, newFn func() interface{}) interface{} {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %q expects %d label values, got %d", f.metricName, len(f.labelNames), len(labelValues)))
+	}
+
+	key := f.labelString(labelValues)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m, exists := f.members[key]
+	if !exists {
+		m = newFn()
+		f.members[key] = m
+	}
+	return m
+}
+
+// sortedMembers returns the label strings of all members in sorted order.
+func (f *family) sortedMembers() ([]string, map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.members))
+	members := make(map[string]interface{}, len(f.members))
+	for k, m := range f.members {
+		keys = append(keys, k)
+		members[k] = m
+	}
+	sort.Strings(keys)
+	return keys, members
+}
+
+// labelString formats the specified label values as a comma-separated list
+// of label pairs.
+func (f *family) labelString(labelValues []string) string {
+	pairs := make([]string, len(labelValues))
+	for i, v := range labelValues {
+		pairs[i] = f.labelNames[i] + `="` + labelValueEscaper.Replace(v) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f *family) writeHeader(w io.Writer, metricType string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.metricName, helpEscaper.Replace(f.help), f.metricName, metricType)
+	return err
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+// CounterVec is a family of counters partitioned by a set of labels.
+type CounterVec struct {
+	family
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	mu    sync.Mutex
+	value float64
+}
+
+// WithLabelValues returns the counter for the specified label values which
+// must be provided in the order of the label names of the family.
+func (v *CounterVec) WithLabelValues(labelValues ...string) *Counter {
+	return v.member(labelValues, func() interface{} { return new(Counter) }).(*Counter)
+}
+
+// Inc increments the counter by 1.
+func (c *Counter) Inc() { c.Add(1) }
+
+// Add increments the counter by delta which must not be negative.
+func (c *Counter) Add(delta float64) {
+	c.mu.Lock()
+	c.value += delta
+	c.mu.Unlock()
+}
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+func (v *CounterVec) write(w io.Writer) error {
+	if err := v.writeHeader(w, "counter"); err != nil {
+		return err
+	}
+
+	keys, members := v.sortedMembers()
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", v.metricName, braces(k), formatFloat(members[k].(*Counter).Value())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HistogramVec is a family of histograms partitioned by a set of labels.
+type HistogramVec struct {
+	family
+	buckets []float64
+}
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	buckets []float64
+
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// WithLabelValues returns the histogram for the specified label values which
+// must be provided in the order of the label names of the family.
+func (v *HistogramVec) WithLabelValues(labelValues ...string) *Histogram {
+	return v.member(labelValues, func() interface{} {
+		return &Histogram{buckets: v.buckets, counts: make([]uint64, len(v.buckets))}
+	}).(*Histogram)
+}
+
+// Observe records a single observation.
+func (h *Histogram) Observe(value float64) {
+	idx := sort.SearchFloat64s(h.buckets, value)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if idx < len(h.counts) {
+		h.counts[idx]++
+	}
+	h.count++
+	h.sum += value
+}
+
+// Count returns the number of recorded observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (v *HistogramVec) write(w io.Writer) error {
+	if err := v.writeHeader(w, "histogram"); err != nil {
+		return err
+	}
+
+	keys, members := v.sortedMembers()
+	for _, k := range keys {
+		h := members[k].(*Histogram)
+		h.mu.Lock()
+		counts, count, sum := append([]uint64(nil), h.counts...), h.count, h.sum
+		h.mu.Unlock()
+
+		sep := ""
+		if k != "" {
+			sep = ","
+		}
+
+		var cumulative uint64
+		for i, upperBound := range v.buckets {
+			cumulative += counts[i]
+			if _, err := fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", v.metricName, k, sep, formatFloat(upperBound), cumulative); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", v.metricName, k, sep, count); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", v.metricName, braces(k), formatFloat(sum), v.metricName, braces(k), count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// braces wraps a non-empty label string in curly braces.
+func braces(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+// formatFloat formats v as expected by the text exposition format.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countingWriter tracks the number of bytes written to the wrapped writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
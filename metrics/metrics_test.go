@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(MetricsTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+type MetricsTestSuite struct{}
+
+// TestExposition verifies the text exposition format of counters and
+// histograms served by the registry.
+func (s *MetricsTestSuite) TestExposition(c *gc.C) {
+	r := NewRegistry()
+	errs := r.NewCounterVec("test_errors_total", "Errors by method.", "method", "kind")
+	latency := r.NewHistogramVec("test_duration_seconds", "Latency\nby method.", []float64{1, 0.5}, "method")
+
+	errs.WithLabelValues("Find", "not_found").Inc()
+	errs.WithLabelValues("Find", "not_found").Add(2)
+	errs.WithLabelValues(`Up"sert`, "other").Inc()
+	for _, v := range []float64{0.2, 0.5, 0.7, 3} {
+		latency.WithLabelValues("Find").Observe(v)
+	}
+	c.Assert(latency.WithLabelValues("Find").Count(), gc.Equals, uint64(4))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	c.Assert(rec.Header().Get("Content-Type"), gc.Equals, "text/plain; version=0.0.4; charset=utf-8")
+
+	body, err := io.ReadAll(rec.Body)
+	c.Assert(err, gc.IsNil)
+	c.Assert(string(body), gc.Equals, `# HELP test_duration_seconds Latency\nby method.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{method="Find",le="0.5"} 2
+test_duration_seconds_bucket{method="Find",le="1"} 3
+test_duration_seconds_bucket{method="Find",le="+Inf"} 4
+test_duration_seconds_sum{method="Find"} 4.4
+test_duration_seconds_count{method="Find"} 4
+# HELP test_errors_total Errors by method.
+# TYPE test_errors_total counter
+test_errors_total{method="Find",kind="not_found"} 3
+test_errors_total{method="Up\"sert",kind="other"} 1
+`)
+}
+
+// TestDuplicateMetric verifies that metric names must be unique.
+func (s *MetricsTestSuite) TestDuplicateMetric(c *gc.C) {
+	r := NewRegistry()
+	r.NewCounterVec("test_total", "")
+	c.Assert(func() { r.NewHistogramVec("test_total", "", DefaultLatencyBuckets) }, gc.PanicMatches, `metrics: duplicate metric "test_total"`)
+}
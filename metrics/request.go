@@ -0,0 +1,63 @@
+package metrics
+
+import "time"
+
+// Error kinds reported by the errors counter of RequestMetrics.
+const (
+	ErrorKindNotFound = "not_found"
+	ErrorKindOther    = "other"
+)
+
+// RequestMetrics bundles the metrics recorded by the instrumenting
+// decorators for the graph and indexer backends:
+//
+//   - <namespace>_request_duration_seconds: a histogram of call latencies
+//     partitioned by method.
+//   - <namespace>_request_errors_total: the number of failed calls
+//     partitioned by method and error kind (not_found or other).
+//   - <namespace>_iterator_items_total: the number of items returned by
+//     iterators partitioned by the method that created the iterator.
+type RequestMetrics struct {
+	latency    *HistogramVec
+	errors     *CounterVec
+	items      *CounterVec
+	isNotFound func(error) bool
+}
+
+// NewRequestMetrics registers the request metrics for the specified
+// namespace with r. Errors for which isNotFound returns true are reported
+// as not_found errors.
+func NewRequestMetrics(r *Registry, namespace string, isNotFound func(error) bool) *RequestMetrics {
+	return &RequestMetrics{
+		latency:    r.NewHistogramVec(namespace+"_request_duration_seconds", "The latency of "+namespace+" calls in seconds.", DefaultLatencyBuckets, "method"),
+		errors:     r.NewCounterVec(namespace+"_request_errors_total", "The number of failed "+namespace+" calls.", "method", "kind"),
+		items:      r.NewCounterVec(namespace+"_iterator_items_total", "The number of items returned by "+namespace+" iterators.", "method"),
+		isNotFound: isNotFound,
+	}
+}
+
+// Observe records the latency of a call to method that started at start
+// and, if err is not nil, the failure of the call.
+func (m *RequestMetrics) Observe(method string, start time.Time, err error) {
+	m.latency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	m.ObserveError(method, err)
+}
+
+// ObserveError records a failed call to method if err is not nil.
+func (m *RequestMetrics) ObserveError(method string, err error) {
+	if err == nil {
+		return
+	}
+
+	kind := ErrorKindOther
+	if m.isNotFound(err) {
+		kind = ErrorKindNotFound
+	}
+	m.errors.WithLabelValues(method, kind).Inc()
+}
+
+// Items returns the counter for the items returned by iterators created by
+// method.
+func (m *RequestMetrics) Items(method string) *Counter {
+	return m.items.WithLabelValues(method)
+}
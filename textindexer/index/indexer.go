@@ -1,6 +1,7 @@
 package index
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,13 +29,25 @@ type Query struct {
 	Offset     uint64
 }
 
+// Indexer is implemented by objects that can index and search documents.
+//
+// Each method has a Context-suffixed variant that accepts a context.Context
+// which can be used to cancel the operation or bound it with a deadline.
+// Iterators returned by SearchContext stop and report ctx.Err() if the
+// context is cancelled while they are being consumed.
 type Indexer interface {
 	// Inserts document to the index or updates the index entry.
 	Index(doc *Document) error
+	IndexContext(ctx context.Context, doc *Document) error
 
 	FindByID(linkID uuid.UUID) (*Document, error)
+	FindByIDContext(ctx context.Context, linkID uuid.UUID) (*Document, error)
+
 	Search(query Query) (Iterator, error)
+	SearchContext(ctx context.Context, query Query) (Iterator, error)
+
 	UpdateScore(linkID uuid.UUID, score float64) error
+	UpdateScoreContext(ctx context.Context, linkID uuid.UUID, score float64) error
 }
 
 type Iterator interface {
@@ -1,6 +1,7 @@
 package indextest
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -289,6 +290,53 @@ func (s *SuiteBase) TestUpdateScoreForUnknownDocument(c *gc.C) {
 	c.Assert(doc.PageRank, gc.Equals, 0.5)
 }
 
+// TestCancelledContext verifies that the Context variants of the indexer
+// methods refuse to operate on a context that has already been cancelled.
+func (s *SuiteBase) TestCancelledContext(c *gc.C) {
+	linkID := uuid.New()
+	c.Assert(s.idx.Index(&index.Document{LinkID: linkID, Content: "Lorem ipsum"}), gc.IsNil)
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	cancelFn()
+
+	err := s.idx.IndexContext(ctx, &index.Document{LinkID: uuid.New()})
+	c.Assert(xerrors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("index: %v", err))
+
+	_, err = s.idx.FindByIDContext(ctx, linkID)
+	c.Assert(xerrors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("find by ID: %v", err))
+
+	err = s.idx.UpdateScoreContext(ctx, linkID, 0.5)
+	c.Assert(xerrors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("update score: %v", err))
+
+	_, err = s.idx.SearchContext(ctx, index.Query{Type: index.QueryTypeMatch, Expression: "ipsum"})
+	c.Assert(xerrors.Is(err, context.Canceled), gc.Equals, true, gc.Commentf("search: %v", err))
+}
+
+// TestSearchIteratorContextCancellation verifies that search iterators stop
+// and report the context error when their context is cancelled while they
+// are being consumed.
+func (s *SuiteBase) TestSearchIteratorContextCancellation(c *gc.C) {
+	for i := 0; i < 30; i++ {
+		err := s.idx.Index(&index.Document{
+			LinkID:  uuid.New(),
+			Title:   fmt.Sprintf("doc %d", i),
+			Content: "Lorem ipsum dolor",
+		})
+		c.Assert(err, gc.IsNil)
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	it, err := s.idx.SearchContext(ctx, index.Query{Type: index.QueryTypeMatch, Expression: "ipsum"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.Next(), gc.Equals, true)
+
+	cancelFn()
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(xerrors.Is(it.Error(), context.Canceled), gc.Equals, true, gc.Commentf("search iterator: %v", it.Error()))
+	c.Assert(it.Close(), gc.IsNil)
+}
+
 func iterateDocs(c *gc.C, it index.Iterator) []uuid.UUID {
 	var seen []uuid.UUID
 	for it.Next() {
@@ -0,0 +1,115 @@
+// Package indexmetrics provides an index.Indexer decorator that records
+// latency, error and iterator metrics for each indexer method.
+package indexmetrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/metrics"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/index"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// Compile-time check for ensuring InstrumentedIndexer implements
+// index.Indexer.
+var _ index.Indexer = (*InstrumentedIndexer)(nil)
+
+// Namespace is the prefix of the names of the metrics recorded by
+// InstrumentedIndexer.
+const Namespace = "textindexer"
+
+// InstrumentedIndexer is an index.Indexer decorator that records the
+// metrics described by metrics.RequestMetrics for each call to the wrapped
+// indexer. Calls to a method and its Context variant are reported under the
+// name of the method without the Context suffix.
+type InstrumentedIndexer struct {
+	idx index.Indexer
+	m   *metrics.RequestMetrics
+}
+
+// New returns an InstrumentedIndexer that wraps idx and registers its
+// metrics with r.
+func New(idx index.Indexer, r *metrics.Registry) *InstrumentedIndexer {
+	return &InstrumentedIndexer{
+		idx: idx,
+		m:   metrics.NewRequestMetrics(r, Namespace, func(err error) bool { return xerrors.Is(err, index.ErrNotFound) }),
+	}
+}
+
+// Index implements index.Indexer.
+func (ii *InstrumentedIndexer) Index(doc *index.Document) error {
+	return ii.IndexContext(context.Background(), doc)
+}
+
+// IndexContext implements index.Indexer.
+func (ii *InstrumentedIndexer) IndexContext(ctx context.Context, doc *index.Document) error {
+	start := time.Now()
+	err := ii.idx.IndexContext(ctx, doc)
+	ii.m.Observe("Index", start, err)
+	return err
+}
+
+// FindByID implements index.Indexer.
+func (ii *InstrumentedIndexer) FindByID(linkID uuid.UUID) (*index.Document, error) {
+	return ii.FindByIDContext(context.Background(), linkID)
+}
+
+// FindByIDContext implements index.Indexer.
+func (ii *InstrumentedIndexer) FindByIDContext(ctx context.Context, linkID uuid.UUID) (*index.Document, error) {
+	start := time.Now()
+	doc, err := ii.idx.FindByIDContext(ctx, linkID)
+	ii.m.Observe("FindByID", start, err)
+	return doc, err
+}
+
+// Search implements index.Indexer.
+func (ii *InstrumentedIndexer) Search(query index.Query) (index.Iterator, error) {
+	return ii.SearchContext(context.Background(), query)
+}
+
+// SearchContext implements index.Indexer.
+func (ii *InstrumentedIndexer) SearchContext(ctx context.Context, query index.Query) (index.Iterator, error) {
+	start := time.Now()
+	it, err := ii.idx.SearchContext(ctx, query)
+	ii.m.Observe("Search", start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &iterator{Iterator: it, m: ii.m, items: ii.m.Items("Search")}, nil
+}
+
+// UpdateScore implements index.Indexer.
+func (ii *InstrumentedIndexer) UpdateScore(linkID uuid.UUID, score float64) error {
+	return ii.UpdateScoreContext(context.Background(), linkID, score)
+}
+
+// UpdateScoreContext implements index.Indexer.
+func (ii *InstrumentedIndexer) UpdateScoreContext(ctx context.Context, linkID uuid.UUID, score float64) error {
+	start := time.Now()
+	err := ii.idx.UpdateScoreContext(ctx, linkID, score)
+	ii.m.Observe("UpdateScore", start, err)
+	return err
+}
+
+// iterator is an index.Iterator decorator that counts the returned
+// documents and records the error that terminated the iteration.
+type iterator struct {
+	index.Iterator
+	m     *metrics.RequestMetrics
+	items *metrics.Counter
+	done  bool
+}
+
+// Next implements index.Iterator.
+func (it *iterator) Next() bool {
+	hasNext := it.Iterator.Next()
+	if hasNext {
+		it.items.Inc()
+	} else if !it.done {
+		it.done = true
+		it.m.ObserveError("Search", it.Iterator.Error())
+	}
+	return hasNext
+}
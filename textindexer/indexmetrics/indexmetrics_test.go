@@ -0,0 +1,80 @@
+package indexmetrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/metrics"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/index"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/index/indextest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/store/memory"
+	"github.com/google/uuid"
+	gc "gopkg.in/check.v1"
+)
+
+var (
+	_ = gc.Suite(new(InstrumentedIndexerTestSuite))
+	_ = gc.Suite(new(MetricsTestSuite))
+)
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+// InstrumentedIndexerTestSuite runs the indexer conformance tests against an
+// instrumented in-memory indexer.
+type InstrumentedIndexerTestSuite struct {
+	indextest.SuiteBase
+	idx *memory.InMemoryBleveIndexer
+}
+
+func (s *InstrumentedIndexerTestSuite) SetUpTest(c *gc.C) {
+	idx, err := memory.NewInMemoryBleveIndexer()
+	c.Assert(err, gc.IsNil)
+	s.SetIndexer(New(idx, metrics.NewRegistry()))
+	s.idx = idx
+}
+
+func (s *InstrumentedIndexerTestSuite) TearDownTest(c *gc.C) {
+	c.Assert(s.idx.Close(), gc.IsNil)
+}
+
+type MetricsTestSuite struct{}
+
+// TestMetrics verifies that calls, errors and search results are recorded.
+func (s *MetricsTestSuite) TestMetrics(c *gc.C) {
+	idx, err := memory.NewInMemoryBleveIndexer()
+	c.Assert(err, gc.IsNil)
+	defer func() { c.Assert(idx.Close(), gc.IsNil) }()
+
+	r := metrics.NewRegistry()
+	ii := New(idx, r)
+
+	for i := 0; i < 3; i++ {
+		c.Assert(ii.Index(&index.Document{LinkID: uuid.New(), URL: "http://example.com/", Title: "gopher facts", Content: "all about gophers"}), gc.IsNil)
+	}
+	_, err = ii.FindByID(uuid.New())
+	c.Assert(err, gc.NotNil)
+	c.Assert(ii.Index(&index.Document{}), gc.NotNil)
+
+	it, err := ii.Search(index.Query{Type: index.QueryTypeMatch, Expression: "gopher"})
+	c.Assert(err, gc.IsNil)
+	for it.Next() {
+	}
+	c.Assert(it.Next(), gc.Equals, false)
+	c.Assert(it.Close(), gc.IsNil)
+
+	var buf bytes.Buffer
+	_, err = r.WriteTo(&buf)
+	c.Assert(err, gc.IsNil)
+	out := buf.String()
+	for _, exp := range []string{
+		`textindexer_request_duration_seconds_count{method="Index"} 4`,
+		`textindexer_request_duration_seconds_count{method="Search"} 1`,
+		`textindexer_request_errors_total{method="FindByID",kind="not_found"} 1`,
+		`textindexer_request_errors_total{method="Index",kind="other"} 1`,
+		`textindexer_iterator_items_total{method="Search"} 3`,
+	} {
+		c.Assert(strings.Contains(out, exp+"\n"), gc.Equals, true, gc.Commentf("missing %q in:\n%s", exp, out))
+	}
+	c.Assert(strings.Contains(out, `method="Search",kind=`), gc.Equals, false)
+}
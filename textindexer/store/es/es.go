@@ -159,6 +159,15 @@ func makeEsDoc(d *index.Document) esDoc {
 
 
 func (i *ElasticSearchIndexer) Index(doc *index.Document) error {
+	return i.IndexContext(context.Background(), doc)
+}
+
+// IndexContext implements index.Indexer.
+func (i *ElasticSearchIndexer) IndexContext(ctx context.Context, doc *index.Document) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("index: %w", err)
+	}
+
 	if doc.LinkID == uuid.Nil {
 		return xerrors.Errorf("index: %w", index.ErrMissingLinkID)
 	}
@@ -177,7 +186,7 @@ func (i *ElasticSearchIndexer) Index(doc *index.Document) error {
 		return xerrors.Errorf("index: %w", err)
 	}
 
-	res, err := i.es.Update(indexName, esDoc.LinkID, &buf, i.refreshOpt)
+	res, err := i.es.Update(indexName, esDoc.LinkID, &buf, i.refreshOpt, i.es.Update.WithContext(ctx))
 	if err != nil {
 		return xerrors.Errorf("index: %w", err)
 	}
@@ -190,7 +199,7 @@ func (i *ElasticSearchIndexer) Index(doc *index.Document) error {
 	return nil
 }
 
-func runSearch(es *elasticsearch.Client, searchQuery map[string]interface{}) (*esSearchRes, error) {
+func runSearch(ctx context.Context, es *elasticsearch.Client, searchQuery map[string]interface{}) (*esSearchRes, error) {
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(searchQuery); err != nil {
 		return nil, xerrors.Errorf("find by ID: %w", err)
@@ -198,7 +207,7 @@ func runSearch(es *elasticsearch.Client, searchQuery map[string]interface{}) (*e
 
 	// Perform the search request.
 	res, err := es.Search(
-		es.Search.WithContext(context.Background()),
+		es.Search.WithContext(ctx),
 		es.Search.WithIndex(indexName),
 		es.Search.WithBody(&buf),
 	)
@@ -226,6 +235,15 @@ func mapEsDoc(d *esDoc) *index.Document {
 }
 
 func (i *ElasticSearchIndexer) FindByID(linkID uuid.UUID) (*index.Document, error) {
+	return i.FindByIDContext(context.Background(), linkID)
+}
+
+// FindByIDContext implements index.Indexer.
+func (i *ElasticSearchIndexer) FindByIDContext(ctx context.Context, linkID uuid.UUID) (*index.Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("find by ID: %w", err)
+	}
+
 	var buf bytes.Buffer
 	// map[string]interface{} is used to store unknown struct data
 	query := map[string]interface{}{
@@ -241,7 +259,7 @@ func (i *ElasticSearchIndexer) FindByID(linkID uuid.UUID) (*index.Document, erro
 		return nil, xerrors.Errorf("find by ID: %w", err)
 	}
 
-	searchRes, err := runSearch(i.es, query)
+	searchRes, err := runSearch(ctx, i.es, query)
 
 	if err != nil {
 		return nil, xerrors.Errorf("find by ID: %w", err)
@@ -255,6 +273,15 @@ func (i *ElasticSearchIndexer) FindByID(linkID uuid.UUID) (*index.Document, erro
 }
 
 func (i *ElasticSearchIndexer) Search(q index.Query) (index.Iterator, error) {
+	return i.SearchContext(context.Background(), q)
+}
+
+// SearchContext implements index.Indexer.
+func (i *ElasticSearchIndexer) SearchContext(ctx context.Context, q index.Query) (index.Iterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("search: %w", err)
+	}
+
 	var qtype string
 	switch q.Type {
 	case index.QueryTypePhrase:
@@ -284,12 +311,12 @@ func (i *ElasticSearchIndexer) Search(q index.Query) (index.Iterator, error) {
 		"size": batchSize,
 	}
 
-	searchRes, err := runSearch(i.es, query)
+	searchRes, err := runSearch(ctx, i.es, query)
 	if err != nil {
 		return nil, xerrors.Errorf("search: %w", err)
 	}
 
-	return &esIterator{es: i.es, searchReq: query, rs: searchRes, cumIdx: q.Offset}, nil
+	return &esIterator{ctx: ctx, es: i.es, searchReq: query, rs: searchRes, cumIdx: q.Offset}, nil
 }
 
 
@@ -297,6 +324,15 @@ func (i *ElasticSearchIndexer) Search(q index.Query) (index.Iterator, error) {
 // specified link ID. If no such document exists, a placeholder
 // document with the provided score will be created.
 func (i *ElasticSearchIndexer) UpdateScore(linkID uuid.UUID, score float64) error {
+	return i.UpdateScoreContext(context.Background(), linkID, score)
+}
+
+// UpdateScoreContext implements index.Indexer.
+func (i *ElasticSearchIndexer) UpdateScoreContext(ctx context.Context, linkID uuid.UUID, score float64) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("update score: %w", err)
+	}
+
 	var buf bytes.Buffer
 	update := map[string]interface{}{
 		"doc": map[string]interface{}{
@@ -309,7 +345,7 @@ func (i *ElasticSearchIndexer) UpdateScore(linkID uuid.UUID, score float64) erro
 		return xerrors.Errorf("update score: %w", err)
 	}
 
-	res, err := i.es.Update(indexName, linkID.String(), &buf, i.refreshOpt)
+	res, err := i.es.Update(indexName, linkID.String(), &buf, i.refreshOpt, i.es.Update.WithContext(ctx))
 	if err != nil {
 		return xerrors.Errorf("update score: %w", err)
 	}
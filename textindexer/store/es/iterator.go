@@ -1,12 +1,15 @@
 package es
 
 import (
+	"context"
+
 	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/index"
 	"github.com/elastic/go-elasticsearch"
 )
 
 // esIterator implements index.Iterator.
 type esIterator struct {
+	ctx       context.Context
 	es        *elasticsearch.Client
 	searchReq map[string]interface{}
 
@@ -31,12 +34,14 @@ func (it *esIterator) Close() error {
 func (it *esIterator) Next() bool {
 	if it.lastErr != nil || it.rs == nil || it.cumIdx >= it.rs.Hits.Total.Count {
 		return false
+	} else if it.lastErr = it.ctx.Err(); it.lastErr != nil {
+		return false
 	}
 
 	// Do we need to fetch the next batch?
 	if it.rsIdx >= len(it.rs.Hits.HitList) {
 		it.searchReq["from"] = it.searchReq["from"].(uint64) + batchSize
-		if it.rs, it.lastErr = runSearch(it.es, it.searchReq); it.lastErr != nil {
+		if it.rs, it.lastErr = runSearch(it.ctx, it.es, it.searchReq); it.lastErr != nil {
 			return false
 		}
 
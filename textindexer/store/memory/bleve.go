@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -48,6 +49,15 @@ func makeBleveDoc(d *index.Document) bleveDoc {
 }
 
 func (i *InMemoryBleveIndexer) Index(doc *index.Document) error {
+	return i.IndexContext(context.Background(), doc)
+}
+
+// IndexContext implements index.Indexer.
+func (i *InMemoryBleveIndexer) IndexContext(ctx context.Context, doc *index.Document) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("index: %w", err)
+	}
+
 	if doc.LinkID == uuid.Nil {
 		return xerrors.Errorf("index: %w", index.ErrMissingLinkID)
 	}
@@ -68,6 +78,15 @@ func (i *InMemoryBleveIndexer) Index(doc *index.Document) error {
 }
 
 func (i *InMemoryBleveIndexer) FindByID(linkID uuid.UUID) (*index.Document, error) {
+	return i.FindByIDContext(context.Background(), linkID)
+}
+
+// FindByIDContext implements index.Indexer.
+func (i *InMemoryBleveIndexer) FindByIDContext(ctx context.Context, linkID uuid.UUID) (*index.Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("find by ID: %w", err)
+	}
+
 	//Method created for findByID() so that document lookup code can be reused when iterating through search results
 	return i.findByID(linkID.String())
 
@@ -83,6 +102,15 @@ func (i *InMemoryBleveIndexer) findByID(linkID string) (*index.Document, error)
 }
 
 func (i *InMemoryBleveIndexer) UpdateScore(linkedID uuid.UUID, score float64) error {
+	return i.UpdateScoreContext(context.Background(), linkedID, score)
+}
+
+// UpdateScoreContext implements index.Indexer.
+func (i *InMemoryBleveIndexer) UpdateScoreContext(ctx context.Context, linkedID uuid.UUID, score float64) error {
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("update score: %w", err)
+	}
+
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
@@ -102,6 +130,15 @@ func (i *InMemoryBleveIndexer) UpdateScore(linkedID uuid.UUID, score float64) er
 }
 
 func (i *InMemoryBleveIndexer) Search(q index.Query) (index.Iterator, error) {
+	return i.SearchContext(context.Background(), q)
+}
+
+// SearchContext implements index.Indexer.
+func (i *InMemoryBleveIndexer) SearchContext(ctx context.Context, q index.Query) (index.Iterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, xerrors.Errorf("search: %w", err)
+	}
+
 	var bq query.Query
 	switch q.Type {
 	case index.QueryTypePhrase:
@@ -115,11 +152,11 @@ func (i *InMemoryBleveIndexer) Search(q index.Query) (index.Iterator, error) {
 	searchReq.Size = batchSize
 	searchReq.From = int(q.Offset)
 
-	rs, err := i.idx.Search(searchReq)
+	rs, err := i.idx.SearchInContext(ctx, searchReq)
 	if err != nil {
 		return nil, xerrors.Errorf("search : %w", err)
 	}
-	return &bleveIterator{idx: i, searchReq: searchReq, rs: rs, cumIdx: q.Offset}, nil
+	return &bleveIterator{ctx: ctx, idx: i, searchReq: searchReq, rs: rs, cumIdx: q.Offset}, nil
 }
 
 func NewInMemoryBleveIndexer() (*InMemoryBleveIndexer, error) {
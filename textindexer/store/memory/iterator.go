@@ -1,12 +1,15 @@
 package memory
 
 import (
+	"context"
+
 	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/index"
 	"github.com/blevesearch/bleve"
 )
 
 // bleveIterator implements index.Iterator.
 type bleveIterator struct {
+	ctx       context.Context
 	idx       *InMemoryBleveIndexer
 	searchReq *bleve.SearchRequest
 
@@ -30,11 +33,13 @@ func (it *bleveIterator) Close() error {
 func (it *bleveIterator) Next() bool {
 	if it.lastErr != nil || it.rs == nil || it.cumIdx >= it.rs.Total {
 		return false
+	} else if it.lastErr = it.ctx.Err(); it.lastErr != nil {
+		return false
 	}
 
 	if it.rsIdx >= it.rs.Hits.Len() {
 		it.searchReq.From += it.searchReq.Size
-		if it.rs, it.lastErr = it.idx.idx.Search(it.searchReq); it.lastErr != nil {
+		if it.rs, it.lastErr = it.idx.idx.SearchInContext(it.ctx, it.searchReq); it.lastErr != nil {
 			return false
 		}
 
@@ -0,0 +1,219 @@
+package textindexerapi
+
+import (
+	"context"
+	"io"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/index"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexerapi/proto"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Compile-time check to ensure TextIndexerClient implements Indexer.
+var _ index.Indexer = (*TextIndexerClient)(nil)
+
+// TextIndexerClient provides an API compatible with the index.Indexer
+// interface for accessing a text indexer instance exposed by a remote gRPC
+// server.
+type TextIndexerClient struct {
+	ctx context.Context
+	cli proto.TextIndexerClient
+}
+
+// NewTextIndexerClient returns a new client instance that implements the
+// index.Indexer interface by delegating methods to a text indexer instance
+// exposed by a remote gRPC server. RPCs issued by the methods without a
+// Context suffix are bound to ctx.
+func NewTextIndexerClient(ctx context.Context, rpcClient proto.TextIndexerClient) *TextIndexerClient {
+	return &TextIndexerClient{ctx: ctx, cli: rpcClient}
+}
+
+// Index inserts a new document to the index or updates the index entry for
+// and existing document.
+func (c *TextIndexerClient) Index(doc *index.Document) error {
+	return c.IndexContext(c.ctx, doc)
+}
+
+// IndexContext implements index.Indexer.
+func (c *TextIndexerClient) IndexContext(ctx context.Context, doc *index.Document) error {
+	req := docToProto(doc)
+	if doc.LinkID == uuid.Nil {
+		req.LinkId = nil
+	}
+
+	res, err := c.cli.Index(ctx, req)
+	if err != nil {
+		return xerrors.Errorf("index: %w", unmapError(err))
+	}
+
+	doc.IndexedAt = res.IndexedAt.AsTime()
+	return nil
+}
+
+// FindByID looks up a document by its link ID.
+func (c *TextIndexerClient) FindByID(linkID uuid.UUID) (*index.Document, error) {
+	return c.FindByIDContext(c.ctx, linkID)
+}
+
+// FindByIDContext implements index.Indexer.
+func (c *TextIndexerClient) FindByIDContext(ctx context.Context, linkID uuid.UUID) (*index.Document, error) {
+	res, err := c.cli.FindByID(ctx, &proto.FindByIDRequest{LinkId: linkID[:]})
+	if err != nil {
+		return nil, xerrors.Errorf("find by ID: %w", unmapError(err))
+	}
+
+	doc, err := docFromProto(res)
+	if err != nil {
+		return nil, xerrors.Errorf("find by ID: %w", err)
+	}
+	return doc, nil
+}
+
+// Search the index for a particular query and return back a result
+// iterator.
+func (c *TextIndexerClient) Search(query index.Query) (index.Iterator, error) {
+	return c.SearchContext(c.ctx, query)
+}
+
+// SearchContext implements index.Indexer.
+func (c *TextIndexerClient) SearchContext(ctx context.Context, query index.Query) (index.Iterator, error) {
+	req := &proto.Query{
+		Expression: query.Expression,
+		Offset:     query.Offset,
+	}
+	switch query.Type {
+	case index.QueryTypeMatch:
+		req.Type = proto.Query_MATCH
+	case index.QueryTypePhrase:
+		req.Type = proto.Query_PHRASE
+	}
+
+	streamCtx, cancelFn := context.WithCancel(ctx)
+	stream, err := c.cli.Search(streamCtx, req)
+	if err != nil {
+		cancelFn()
+		return nil, xerrors.Errorf("search: %w", unmapError(err))
+	}
+
+	// Read result count
+	res, err := stream.Recv()
+	if err != nil {
+		cancelFn()
+		return nil, xerrors.Errorf("search: %w", unmapError(err))
+	} else if res.GetDoc() != nil {
+		cancelFn()
+		return nil, xerrors.Errorf("search: expected server to report the result count before sending any documents")
+	}
+
+	return &resultIterator{
+		ctx:      ctx,
+		total:    res.GetDocCount(),
+		stream:   stream,
+		cancelFn: cancelFn,
+	}, nil
+}
+
+// UpdateScore updates the PageRank score for a document with the specified
+// link ID.
+func (c *TextIndexerClient) UpdateScore(linkID uuid.UUID, score float64) error {
+	return c.UpdateScoreContext(c.ctx, linkID, score)
+}
+
+// UpdateScoreContext implements index.Indexer.
+func (c *TextIndexerClient) UpdateScoreContext(ctx context.Context, linkID uuid.UUID, score float64) error {
+	req := &proto.UpdateScoreRequest{
+		LinkId:        linkID[:],
+		PageRankScore: score,
+	}
+	if _, err := c.cli.UpdateScore(ctx, req); err != nil {
+		return xerrors.Errorf("update score: %w", unmapError(err))
+	}
+	return nil
+}
+
+// unmapError converts gRPC status errors returned by the server back into
+// the errors defined by the index package.
+func unmapError(err error) error {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.NotFound:
+		return index.ErrNotFound
+	case codes.InvalidArgument:
+		if st.Message() == index.ErrMissingLinkID.Error() {
+			return index.ErrMissingLinkID
+		}
+		return err
+	case codes.Canceled:
+		return context.Canceled
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	default:
+		return err
+	}
+}
+
+// resultIterator is an index.Iterator implementation that consumes the
+// result stream returned by the remote server. Documents are only received
+// when Next is invoked.
+type resultIterator struct {
+	ctx      context.Context
+	total    uint64
+	stream   proto.TextIndexer_SearchClient
+	cancelFn context.CancelFunc
+
+	latchedDoc *index.Document
+	lastErr    error
+}
+
+// Next advances the iterator. If no more items are available or an error
+// occurs, calls to Next() return false.
+func (it *resultIterator) Next() bool {
+	if it.lastErr != nil {
+		return false
+	} else if err := it.ctx.Err(); err != nil {
+		it.lastErr = xerrors.Errorf("search iterator: %w", err)
+		it.cancelFn()
+		return false
+	}
+
+	res, err := it.stream.Recv()
+	if err != nil {
+		if err != io.EOF {
+			it.lastErr = xerrors.Errorf("search iterator: %w", unmapError(err))
+		}
+		it.cancelFn()
+		return false
+	}
+
+	resDoc := res.GetDoc()
+	if resDoc == nil {
+		it.cancelFn()
+		it.lastErr = xerrors.Errorf("search iterator: received nil document")
+		return false
+	}
+
+	if it.latchedDoc, err = docFromProto(resDoc); err != nil {
+		it.cancelFn()
+		it.lastErr = xerrors.Errorf("search iterator: %w", err)
+		return false
+	}
+	return true
+}
+
+// Error returns the last error encountered by the iterator.
+func (it *resultIterator) Error() error { return it.lastErr }
+
+// Document returns the current document from the result set.
+func (it *resultIterator) Document() *index.Document { return it.latchedDoc }
+
+// TotalCount returns the approximate number of search results.
+func (it *resultIterator) TotalCount() uint64 { return it.total }
+
+// Close releases any resources associated with the iterator.
+func (it *resultIterator) Close() error {
+	it.cancelFn()
+	return nil
+}
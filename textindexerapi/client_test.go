@@ -0,0 +1,98 @@
+package textindexerapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/index"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/index/indextest"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/store/memory"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexerapi/proto"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	gc "gopkg.in/check.v1"
+)
+
+var _ = gc.Suite(new(TextIndexerClientTestSuite))
+
+func Test(t *testing.T) { gc.TestingT(t) }
+
+// TextIndexerClientTestSuite runs the indexer conformance tests against a
+// TextIndexerClient that talks to an in-memory bleve indexer over a loopback
+// gRPC connection.
+type TextIndexerClientTestSuite struct {
+	indextest.SuiteBase
+
+	idx  *memory.InMemoryBleveIndexer
+	srv  *grpc.Server
+	conn *grpc.ClientConn
+	cli  *TextIndexerClient
+}
+
+func (s *TextIndexerClientTestSuite) SetUpTest(c *gc.C) {
+	idx, err := memory.NewInMemoryBleveIndexer()
+	c.Assert(err, gc.IsNil)
+	s.idx = idx
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, gc.IsNil)
+
+	s.srv = grpc.NewServer()
+	proto.RegisterTextIndexerServer(s.srv, NewTextIndexerServer(idx))
+	go func() { _ = s.srv.Serve(lis) }()
+
+	s.conn, err = grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	c.Assert(err, gc.IsNil)
+	s.cli = NewTextIndexerClient(context.Background(), proto.NewTextIndexerClient(s.conn))
+	s.SetIndexer(s.cli)
+}
+
+func (s *TextIndexerClientTestSuite) TearDownTest(c *gc.C) {
+	c.Assert(s.conn.Close(), gc.IsNil)
+	s.srv.Stop()
+	c.Assert(s.idx.Close(), gc.IsNil)
+}
+
+// TestSearchTotalCount verifies that the iterator returned by the client
+// reports the total number of matching documents before any of them have
+// been consumed.
+func (s *TextIndexerClientTestSuite) TestSearchTotalCount(c *gc.C) {
+	numDocs := 25
+	for i := 0; i < numDocs; i++ {
+		err := s.cli.Index(&index.Document{
+			LinkID:  uuid.New(),
+			Title:   fmt.Sprintf("doc %d", i),
+			Content: "Lorem ipsum dolor",
+		})
+		c.Assert(err, gc.IsNil)
+	}
+
+	it, err := s.cli.Search(index.Query{Type: index.QueryTypeMatch, Expression: "ipsum"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(it.TotalCount(), gc.Equals, uint64(numDocs))
+
+	// Closing the iterator early must not leak the stream.
+	c.Assert(it.Next(), gc.Equals, true)
+	c.Assert(it.Close(), gc.IsNil)
+}
+
+// TestInvalidLinkID verifies that only missing link IDs are reported to
+// clients as index.ErrMissingLinkID while malformed ones are passed through
+// as InvalidArgument errors.
+func (s *TextIndexerClientTestSuite) TestInvalidLinkID(c *gc.C) {
+	err := s.cli.Index(&index.Document{Title: "no ID"})
+	c.Assert(xerrors.Is(err, index.ErrMissingLinkID), gc.Equals, true, gc.Commentf("%v", err))
+
+	_, err = proto.NewTextIndexerClient(s.conn).UpdateScore(context.Background(), &proto.UpdateScoreRequest{LinkId: []byte{1, 2, 3}})
+	c.Assert(status.Code(err), gc.Equals, codes.InvalidArgument)
+
+	err = unmapError(err)
+	c.Assert(xerrors.Is(err, index.ErrMissingLinkID), gc.Equals, false)
+	c.Assert(status.Code(err), gc.Equals, codes.InvalidArgument)
+}
@@ -0,0 +1,181 @@
+package textindexerapi
+
+import (
+	"context"
+
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexer/index"
+	"github.com/Waqas-Shah-42/Links-R-Us-2/textindexerapi/proto"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var _ proto.TextIndexerServer = (*TextIndexerServer)(nil)
+
+// TextIndexerServer provides a gRPC layer for indexing and querying documents.
+type TextIndexerServer struct {
+	proto.UnimplementedTextIndexerServer
+	i index.Indexer
+}
+
+// NewTextIndexerServer creates a new server instance that uses the provided
+// indexer as its backing store.
+func NewTextIndexerServer(i index.Indexer) *TextIndexerServer {
+	return &TextIndexerServer{i: i}
+}
+
+// Index inserts a new document to the index or updates the index entry for
+// an existing document.
+func (s *TextIndexerServer) Index(ctx context.Context, req *proto.Document) (*proto.Document, error) {
+	doc, err := docFromProto(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.i.IndexContext(ctx, doc); err != nil {
+		return nil, mapError(err)
+	}
+
+	return docToProto(doc), nil
+}
+
+// FindByID looks up a document by its link ID.
+func (s *TextIndexerServer) FindByID(ctx context.Context, req *proto.FindByIDRequest) (*proto.Document, error) {
+	linkID, err := parseLinkID(req.LinkId)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.i.FindByIDContext(ctx, linkID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return docToProto(doc), nil
+}
+
+// Search the index for a particular query and stream the results back to
+// the client. The first response will include the total result count while
+// all subsequent responses will include documents from the resultset.
+func (s *TextIndexerServer) Search(req *proto.Query, w proto.TextIndexer_SearchServer) error {
+	query := index.Query{
+		Expression: req.Expression,
+		Offset:     req.Offset,
+	}
+	switch req.Type {
+	case proto.Query_MATCH:
+		query.Type = index.QueryTypeMatch
+	case proto.Query_PHRASE:
+		query.Type = index.QueryTypePhrase
+	}
+
+	it, err := s.i.SearchContext(w.Context(), query)
+	if err != nil {
+		return mapError(err)
+	}
+	defer func() { _ = it.Close() }()
+
+	// Send back the total document count
+	countRes := &proto.QueryResult{
+		Result: &proto.QueryResult_DocCount{DocCount: it.TotalCount()},
+	}
+	if err = w.Send(countRes); err != nil {
+		return err
+	}
+
+	// Start streaming. The iterator fetches results from the index in
+	// batches so documents are only loaded as the client consumes them.
+	for it.Next() {
+		doc := it.Document()
+		res := &proto.QueryResult{
+			Result: &proto.QueryResult_Doc{Doc: docToProto(doc)},
+		}
+		if err = w.Send(res); err != nil {
+			return err
+		}
+	}
+
+	if err = it.Error(); err != nil {
+		return mapError(err)
+	}
+	return mapError(it.Close())
+}
+
+// UpdateScore updates the PageRank score for a document with the specified
+// link ID.
+func (s *TextIndexerServer) UpdateScore(ctx context.Context, req *proto.UpdateScoreRequest) (*emptypb.Empty, error) {
+	linkID, err := parseLinkID(req.LinkId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.i.UpdateScoreContext(ctx, linkID, req.PageRankScore); err != nil {
+		return nil, mapError(err)
+	}
+	return new(emptypb.Empty), nil
+}
+
+// mapError converts the errors returned by index.Indexer implementations
+// into gRPC status errors that can be mapped back by the client.
+func mapError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case xerrors.Is(err, index.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case xerrors.Is(err, index.ErrMissingLinkID):
+		// The client recognizes missing link IDs by the message of the
+		// status as other InvalidArgument errors share the same code.
+		return status.Error(codes.InvalidArgument, index.ErrMissingLinkID.Error())
+	case xerrors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case xerrors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// parseLinkID decodes a link ID. An empty value is mapped to uuid.Nil so
+// that the indexer gets a chance to reject it with index.ErrMissingLinkID.
+func parseLinkID(b []byte) (uuid.UUID, error) {
+	if len(b) == 0 {
+		return uuid.Nil, nil
+	}
+
+	linkID, err := uuid.FromBytes(b)
+	if err != nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid link ID: %v", err)
+	}
+	return linkID, nil
+}
+
+func docToProto(doc *index.Document) *proto.Document {
+	return &proto.Document{
+		LinkId:    doc.LinkID[:],
+		Url:       doc.URL,
+		Title:     doc.Title,
+		Content:   doc.Content,
+		IndexedAt: timestamppb.New(doc.IndexedAt),
+		PageRank:  doc.PageRank,
+	}
+}
+
+func docFromProto(req *proto.Document) (*index.Document, error) {
+	linkID, err := parseLinkID(req.LinkId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &index.Document{
+		LinkID:    linkID,
+		URL:       req.Url,
+		Title:     req.Title,
+		Content:   req.Content,
+		IndexedAt: req.IndexedAt.AsTime(),
+		PageRank:  req.PageRank,
+	}, nil
+}